@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"slices"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"sr-api/internal/core/ports/telemetry"
+	"strconv"
+	"time"
+)
+
+const defaultAuditQueryLimit = 1000
+
+// auditOutcome maps an HTTP status code to the outcome recorded in the audit log.
+func auditOutcome(code int) string {
+	if code < http.StatusInternalServerError {
+		return domain.AuditOutcomeRejected
+	}
+	return domain.AuditOutcomeFailed
+}
+
+// recordAudit appends the record to the configured sink. Failures are logged but never
+// affect the response, the caller has already been served at this point.
+func (dep *UploadHandlerDependencies) recordAudit(ctx context.Context, record domain.AuditRecord) {
+	if dep.AuditSink == nil {
+		return
+	}
+	if err := dep.AuditSink.Append(context.WithoutCancel(ctx), record); err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("audit.id", record.ID).Msg("Failed to write audit record")
+	}
+}
+
+// AuditHandler returns the audit records of the caller filtered by the optional from/to
+// (RFC 3339) and limit query parameters. Audit admins read the records of every caller
+// of their tenant and may filter them with the caller query parameter. Anonymous callers
+// share one identity and are refused.
+func (dep *UploadHandlerDependencies) AuditHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "AuditHandler")
+	defer span.End()
+
+	caller := domain.CallerFromContext(ctx)
+	if caller.ID == domain.AnonymousCaller {
+		ports.RespondWithClientError(c, span, errors.New("anonymous audit query"), http.StatusForbidden, "An API key is required to read the audit log")
+		return
+	}
+	query := domain.AuditQuery{
+		Caller: c.Query("caller"),
+		Tenant: domain.TenantFromContext(ctx).ID,
+		Limit:  defaultAuditQueryLimit,
+	}
+	if !caller.Known || !slices.Contains(dep.AuditAdmins, caller.ID) {
+		if query.Caller != "" && query.Caller != caller.ID {
+			ports.RespondWithClientError(c, span, fmt.Errorf("caller %s queried the audit records of %s", caller.ID, query.Caller), http.StatusForbidden, "Only audit admins may read the records of other callers")
+			return
+		}
+		query.Caller = caller.ID
+	}
+	var err error
+	if from := c.Query("from"); from != "" {
+		if query.From, err = time.Parse(time.RFC3339, from); err != nil {
+			ports.RespondWithClientError(c, span, err, http.StatusBadRequest, "Invalid 'from' timestamp, expected RFC 3339")
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if query.To, err = time.Parse(time.RFC3339, to); err != nil {
+			ports.RespondWithClientError(c, span, err, http.StatusBadRequest, "Invalid 'to' timestamp, expected RFC 3339")
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 {
+			ports.RespondWithClientError(c, span, fmt.Errorf("invalid limit: %s", limit), http.StatusBadRequest, "Invalid 'limit', expected a positive integer")
+			return
+		}
+	}
+
+	records, err := dep.AuditSink.Query(ctx, query)
+	if err != nil {
+		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to query audit log")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"records": records})
+	span.SetStatus(codes.Ok, "Audit log queried")
+}
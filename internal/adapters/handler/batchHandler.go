@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"mime/multipart"
+	"net/http"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"sr-api/internal/core/ports/telemetry"
+	"sync"
+	"time"
+)
+
+// batchItem is one file of a batch upload, either uploaded directly or extracted from an
+// uploaded ZIP archive when opened.
+type batchItem struct {
+	filename string
+	archive  string
+	open     func() (multipart.File, int64, error)
+	// failure is set when the file was rejected before processing, e.g. a broken archive.
+	failure *uploadError
+}
+
+// BatchUploadHandler stores and transcribes every file of the multipart "files" (or
+// "file") fields, extracting ZIP archives, with bounded parallelism. Each file gets its
+// own result and audit record, so a bad file does not fail the whole batch.
+func (dep *UploadHandlerDependencies) BatchUploadHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "BatchUploadHandler")
+	defer span.End()
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		ports.RespondWithClientError(c, span, err, http.StatusBadRequest, "Failed to read multipart form")
+		return
+	}
+	options, err := parseTranscribeOptions(c.PostForm)
+	if err != nil {
+		ports.RespondWithClientError(c, span, err, http.StatusBadRequest, err.Error())
+		return
+	}
+	headers := append(form.File["files"], form.File["file"]...)
+	if len(headers) == 0 {
+		ports.RespondWithClientError(c, span, fmt.Errorf("no files in batch"), http.StatusBadRequest, "No files uploaded")
+		return
+	}
+
+	var items []batchItem
+	for _, header := range headers {
+		opened, err := header.Open()
+		if err != nil {
+			items = append(items, batchItem{filename: header.Filename, failure: &uploadError{err, http.StatusBadRequest, "Failed to open uploaded file"}})
+			continue
+		}
+		defer opened.Close()
+		items = append(items, dep.batchItems(header, opened)...)
+	}
+	if len(items) > dep.BatchMaxFiles {
+		err := fmt.Errorf("batch holds %d files", len(items))
+		ports.RespondWithClientError(c, span, err, http.StatusBadRequest, fmt.Sprintf("Too many files, at most %d per batch", dep.BatchMaxFiles))
+		return
+	}
+	span.SetAttributes(attribute.Int("batch.files", len(items)))
+
+	response := handlerStructure.BatchResponse{Results: make([]handlerStructure.BatchItemResult, len(items))}
+	slots := make(chan struct{}, max(dep.BatchConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		go func(i int, item batchItem) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			response.Results[i] = dep.processBatchItem(ctx, item, options)
+		}(i, item)
+	}
+	wg.Wait()
+
+	for _, result := range response.Results {
+		if result.Error == "" {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	span.SetAttributes(attribute.Int("batch.succeeded", response.Succeeded), attribute.Int("batch.failed", response.Failed))
+	c.JSON(http.StatusOK, response)
+	span.SetStatus(codes.Ok, "Batch processed")
+}
+
+// batchItems returns the item for an uploaded file, or one item per file when the upload
+// is a ZIP archive.
+func (dep *UploadHandlerDependencies) batchItems(header *multipart.FileHeader, opened multipart.File) []batchItem {
+	if !domain.IsZipArchive(opened) {
+		return []batchItem{{
+			filename: header.Filename,
+			open: func() (multipart.File, int64, error) {
+				return nopCloser{opened}, header.Size, nil
+			},
+		}}
+	}
+
+	entries, err := domain.ZipEntries(opened, header.Size, dep.BatchMaxFiles, dep.BatchMaxArchiveBytes)
+	if err != nil {
+		return []batchItem{{filename: header.Filename, failure: &uploadError{err, http.StatusBadRequest, "Invalid archive: " + err.Error()}}}
+	}
+	items := make([]batchItem, 0, len(entries))
+	for _, entry := range entries {
+		entry := entry
+		items = append(items, batchItem{
+			filename: entry.Name,
+			archive:  header.Filename,
+			open: func() (multipart.File, int64, error) {
+				file, err := domain.ExtractZipEntry(entry)
+				if err != nil {
+					return nil, 0, err
+				}
+				return file, file.Size, nil
+			},
+		})
+	}
+	return items
+}
+
+// processBatchItem runs one file of a batch through the upload pipeline in its own span
+// and audit record.
+func (dep *UploadHandlerDependencies) processBatchItem(ctx context.Context, item batchItem, options transcribeOptions) handlerStructure.BatchItemResult {
+	ctx, span := telemetry.StartSpan(ctx, "ProcessBatchFile", attribute.String("file.name", item.filename))
+	defer span.End()
+
+	started := time.Now()
+	audit := domain.NewAuditRecord(ctx, domain.AuditActionUpload)
+	audit.OriginalFilename = item.filename
+	audit.Source = item.archive
+	defer func() {
+		audit.TimingsMs["total"] = time.Since(started).Milliseconds()
+		dep.recordAudit(ctx, audit)
+	}()
+
+	result := handlerStructure.BatchItemResult{Filename: item.filename, Archive: item.archive}
+	fail := func(failure *uploadError) handlerStructure.BatchItemResult {
+		audit.Outcome = auditOutcome(failure.code)
+		audit.Error = failure.message
+		span.RecordError(failure.err)
+		span.SetStatus(codes.Error, failure.message)
+		telemetry.LoggerFromContext(ctx).Warn().Err(failure.err).Str("file_name", item.filename).Int("http.status_code", failure.code).Msg(failure.message)
+		result.Status = failure.code
+		result.Error = failure.message
+		return result
+	}
+	if item.failure != nil {
+		return fail(item.failure)
+	}
+
+	file, size, err := item.open()
+	if err != nil {
+		return fail(&uploadError{err, http.StatusBadRequest, "Failed to extract file from archive"})
+	}
+	defer file.Close()
+	audit.Size = size
+
+	recognitionResult, cacheHit, failure := dep.processFile(ctx, &audit, file, item.filename, size, nil, options)
+	if failure != nil {
+		return fail(failure)
+	}
+	result.Status = http.StatusOK
+	result.CacheHit = cacheHit
+	result.Result = &recognitionResult
+	span.SetStatus(codes.Ok, "File transcribed successfully")
+	return result
+}
+
+// nopCloser keeps the uploaded file open for the handler, which closes it once every
+// item of the batch has been processed.
+type nopCloser struct {
+	multipart.File
+}
+
+func (nopCloser) Close() error { return nil }
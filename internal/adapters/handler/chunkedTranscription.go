@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"path"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// recognize transcribes the object at objectKey. When audio holds its content and the
+// recording is longer than the configured chunk duration, it is transcribed in chunks.
+func (dep *UploadHandlerDependencies) recognize(ctx context.Context, objectKey string, audio io.ReadSeeker, options domain.TranscriptionOptions) (domain.RecognitionSuccess, error) {
+	if audio != nil && dep.ChunkDuration > 0 {
+		seconds, ok := domain.AudioDurationSeconds(audio)
+		if ok && seconds > (dep.ChunkDuration+dep.ChunkOverlap).Seconds() {
+			return dep.transcribeChunked(ctx, objectKey, audio, options)
+		}
+	}
+	return dep.WhisperRepo.SendToWhisper(ctx, objectKey, options)
+}
+
+// transcribeChunked splits a long WAV recording into overlapping chunks cut at silence,
+// transcribes them in parallel as temporary objects next to objectKey, named after a
+// run ID of their own so that concurrent transcriptions of one object never share a
+// chunk, and stitches the results. Progress is reported as span events and to the progress of the request, see
+// ProgressHandler. The first failing chunk cancels the others and fails the transcription.
+func (dep *UploadHandlerDependencies) transcribeChunked(ctx context.Context, objectKey string, audio io.ReadSeeker, options domain.TranscriptionOptions) (domain.RecognitionSuccess, error) {
+	ctx, span := telemetry.StartSpan(ctx, "TranscribeChunks", attribute.String("filename", objectKey))
+	defer span.End()
+	logger := telemetry.LoggerFromContext(ctx)
+
+	chunks, err := domain.PlanAudioChunks(audio, dep.ChunkDuration.Seconds(), dep.ChunkOverlap.Seconds())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to split audio into chunks")
+		return domain.RecognitionSuccess{}, err
+	}
+	runID, err := domain.GenerateUIDWithContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to generate chunk run ID")
+		return domain.RecognitionSuccess{}, err
+	}
+	span.SetAttributes(attribute.Int("chunks.total", len(chunks)))
+	logger.Info().Str("file_name", objectKey).Int("chunks.total", len(chunks)).Msg("Transcribing audio in chunks")
+	dep.reportProgress(ctx, objectKey, handlerStructure.ProgressRunning, 0, len(chunks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var firstErr error
+	var failOnce sync.Once
+	fail := func(err error) {
+		failOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	results := make([]domain.RecognitionSuccess, len(chunks))
+	base := strings.TrimSuffix(objectKey, path.Ext(objectKey)) + "." + runID
+	var completed atomic.Int32
+	slots := make(chan struct{}, max(dep.ChunkConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		// Chunks are read one at a time once a slot is free, so at most one chunk per
+		// slot is held in memory.
+		slots <- struct{}{}
+		if ctx.Err() != nil {
+			<-slots
+			break
+		}
+		data, err := domain.ExtractWAVChunk(audio, chunk)
+		if err != nil {
+			<-slots
+			fail(err)
+			break
+		}
+		wg.Add(1)
+		go func(i int, chunk domain.AudioChunk, data []byte) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			chunkKey := fmt.Sprintf("%s.chunk-%03d.wav", base, i)
+			result, err := dep.transcribeChunk(ctx, chunkKey, data, chunk, options)
+			if err != nil {
+				fail(fmt.Errorf("chunk %d: %w", i, err))
+				return
+			}
+			results[i] = result
+			done := int(completed.Add(1))
+			span.AddEvent("Chunk transcribed", trace.WithAttributes(
+				attribute.Int("chunk.index", i),
+				attribute.Int("chunks.completed", done),
+				attribute.Int("chunks.total", len(chunks)),
+			))
+			logger.Info().Str("file_name", objectKey).Int("chunks.completed", done).Int("chunks.total", len(chunks)).Msg("Chunk transcribed")
+			dep.reportProgress(ctx, objectKey, handlerStructure.ProgressRunning, done, len(chunks))
+		}(i, chunk, data)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		dep.reportProgress(ctx, objectKey, handlerStructure.ProgressFailed, int(completed.Load()), len(chunks))
+		span.RecordError(firstErr)
+		span.SetStatus(codes.Error, "Failed to transcribe audio chunk")
+		return domain.RecognitionSuccess{}, firstErr
+	}
+	dep.reportProgress(ctx, objectKey, handlerStructure.ProgressCompleted, len(chunks), len(chunks))
+	span.SetStatus(codes.Ok, "Audio chunks transcribed")
+	return domain.StitchTranscripts(results, chunks), nil
+}
+
+// transcribeChunk stores one chunk as a temporary object, transcribes it and removes it.
+func (dep *UploadHandlerDependencies) transcribeChunk(ctx context.Context, chunkKey string, data []byte, chunk domain.AudioChunk, options domain.TranscriptionOptions) (domain.RecognitionSuccess, error) {
+	metadata := domain.ObjectMetadata{
+		ContentType:     "audio/wav",
+		DurationSeconds: chunk.End - chunk.Start,
+	}
+	if err := dep.MinioRepo.UploadToMinioWithContext(ctx, chunkKey, domain.NewMemoryFile(data), int64(len(data)), metadata); err != nil {
+		return domain.RecognitionSuccess{}, err
+	}
+	defer func() {
+		// Leftover chunks are audio objects, the retention policy removes them eventually.
+		if err := dep.MinioRepo.RemoveObjectWithContext(context.WithoutCancel(ctx), chunkKey); err != nil {
+			telemetry.LoggerFromContext(ctx).Warn().Err(err).Str("file_name", chunkKey).Msg("Failed to remove audio chunk")
+		}
+	}()
+	return dep.WhisperRepo.SendToWhisper(ctx, chunkKey, options)
+}
@@ -0,0 +1,21 @@
+package handlerStructure
+
+// ChatCompletionData asks an OpenAI-compatible chat completion service for a reply.
+type ChatCompletionData struct {
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+// ChatMessage is a message of a chat completion request or reply.
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatCompletionResult is the response of the chat completion service.
+type ChatCompletionResult struct {
+	Choices []struct {
+		Message ChatMessage `json:"message"`
+	} `json:"choices"`
+}
@@ -0,0 +1,21 @@
+package handlerStructure
+
+import "sr-api/internal/core/domain"
+
+// BatchItemResult is the outcome of one file of a batch upload.
+type BatchItemResult struct {
+	Filename string `json:"filename"`
+	// Archive is the name of the uploaded ZIP archive the file was extracted from.
+	Archive  string                     `json:"archive,omitempty"`
+	Status   int                        `json:"status"`
+	CacheHit bool                       `json:"cache_hit,omitempty"`
+	Result   *domain.RecognitionSuccess `json:"result,omitempty"`
+	Error    string                     `json:"error,omitempty"`
+}
+
+// BatchResponse lists the per-file results of a batch upload in upload order.
+type BatchResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
@@ -0,0 +1,27 @@
+package handlerStructure
+
+import "time"
+
+// PresignRequest asks for a URL to upload a file directly to the bucket.
+type PresignRequest struct {
+	Filename string `json:"filename"`
+}
+
+// PresignResponse carries the presigned URL the client uploads the file to with PUT and
+// the headers it must send, and the upload ID to complete once the upload has finished.
+type PresignResponse struct {
+	ID        string            `json:"id"`
+	ObjectKey string            `json:"object_key"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// PendingUpload is a presigned upload waiting to be completed.
+type PendingUpload struct {
+	ObjectKey        string
+	OriginalFilename string
+	Caller           string
+	Tenant           string
+}
@@ -0,0 +1,26 @@
+package handlerStructure
+
+import "time"
+
+// States of a chunked transcription.
+const (
+	ProgressRunning   = "running"
+	ProgressCompleted = "completed"
+	ProgressFailed    = "failed"
+)
+
+// ChunkProgress is the progress of the chunked transcription of one file.
+type ChunkProgress struct {
+	ObjectKey       string    `json:"object_key"`
+	State           string    `json:"state"`
+	ChunksCompleted int       `json:"chunks_completed"`
+	ChunksTotal     int       `json:"chunks_total"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// RequestProgress lists the chunked transcriptions of a request in the order they
+// started.
+type RequestProgress struct {
+	RequestID string          `json:"request_id"`
+	Files     []ChunkProgress `json:"files"`
+}
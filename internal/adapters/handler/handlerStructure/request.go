@@ -1,6 +1,9 @@
 package handlerStructure
 
+import "sr-api/internal/core/domain"
+
 type SendData struct {
 	BucketName string `json:"bucket"`
 	FileName   string `json:"file_name"`
+	domain.TranscriptionOptions
 }
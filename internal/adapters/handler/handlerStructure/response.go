@@ -12,8 +12,3 @@ type UploadSuccess struct {
 type StatusSuccess struct {
 	Status string `json:"status"`
 }
-
-type RecognitionSuccess struct {
-	DetectedLang   string `json:"detected_language"`
-	RecognizedText string `json:"recognized_text"`
-}
@@ -0,0 +1,16 @@
+package handlerStructure
+
+import "sr-api/internal/core/domain"
+
+// DiarizeData asks the diarization service for the speaker turns of a stored object.
+type DiarizeData struct {
+	BucketName string `json:"bucket"`
+	FileName   string `json:"file_name"`
+	// NumSpeakers is the expected number of speakers, zero lets the service decide.
+	NumSpeakers int `json:"num_speakers,omitempty"`
+}
+
+// DiarizeResult is the response of the diarization service.
+type DiarizeResult struct {
+	Segments []domain.SpeakerTurn `json:"segments"`
+}
@@ -0,0 +1,29 @@
+package handlerStructure
+
+import "sr-api/internal/core/domain"
+
+// TranscribeRequest names audio to transcribe without uploading it: either a URL or an
+// object in an existing bucket.
+type TranscribeRequest struct {
+	URL         string `json:"url"`
+	Bucket      string `json:"bucket"`
+	Object      string `json:"object"`
+	NoCache     bool   `json:"no_cache"`
+	DeleteAudio bool   `json:"delete_audio"`
+	// Normalize overrides the configured audio normalization when set.
+	Normalize *bool `json:"normalize,omitempty"`
+	// Diarize overrides the configured speaker diarization when set.
+	Diarize     *bool `json:"diarize,omitempty"`
+	NumSpeakers int   `json:"num_speakers,omitempty"`
+	// Redact overrides the configured redaction of personal data when set.
+	Redact *bool `json:"redact,omitempty"`
+	// PostProcess selects the post-processing steps instead of the configured ones,
+	// ["none"] disables post-processing.
+	PostProcess []string `json:"postprocess,omitempty"`
+	// TargetLanguage asks for a translation of the transcript into the language.
+	TargetLanguage string `json:"target_language,omitempty"`
+	// Analyze overrides the configured summary and keyword extraction when set.
+	Analyze *bool `json:"analyze,omitempty"`
+	domain.TranscriptionOptions
+	domain.ConfidenceFilter
+}
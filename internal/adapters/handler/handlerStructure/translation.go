@@ -0,0 +1,16 @@
+package handlerStructure
+
+// TranslateData asks a LibreTranslate-compatible service to translate a batch of texts.
+type TranslateData struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+// TranslateResult is the response of the translation service, one text per input text.
+type TranslateResult struct {
+	TranslatedText []string `json:"translatedText"`
+	Error          string   `json:"error,omitempty"`
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"time"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	APIKeyHeader    = "X-API-Key"
+)
+
+// RequestLogger builds a per-request logger carrying the request ID, client IP and
+// API key identity, and stores it together with the caller in the request context.
+// It must be registered after the OpenTelemetry middleware so that the server span
+// is already present in the context.
+func RequestLogger(cfg *config.AppConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		caller := domain.ResolveCaller(c.GetHeader(APIKeyHeader), cfg.APIKeys)
+		logger := log.Logger.With().
+			Str("request_id", requestID).
+			Str("client_ip", c.ClientIP()).
+			Str("api_key_id", caller.ID).
+			Logger()
+
+		ctx := domain.ContextWithCaller(c.Request.Context(), caller)
+		ctx = domain.ContextWithRequestID(ctx, requestID)
+		ctx = telemetry.ContextWithLogger(ctx, logger)
+		c.Request = c.Request.WithContext(ctx)
+
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.String("request.id", requestID),
+			attribute.String("caller.id", caller.ID),
+		)
+
+		c.Next()
+
+		// Later middleware may have annotated the logger, e.g. with the tenant.
+		telemetry.LoggerFromContext(c.Request.Context()).Info().
+			Str("method", c.Request.Method).
+			Str("path", c.FullPath()).
+			Int("status", c.Writer.Status()).
+			Dur("latency", time.Since(start)).
+			Msg("Request completed")
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+)
+
+// TenantResolver stores the tenant of the caller in the request context, which scopes
+// storage and results to the tenant and attributes the spans, metrics and log lines of
+// the request to it. It must be registered after RequestLogger, which identifies the
+// caller.
+func TenantResolver(tenants *domain.TenantRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := tenants.Resolve(domain.CallerFromContext(c.Request.Context()))
+		ctx := domain.ContextWithTenant(c.Request.Context(), tenant)
+		c.Request = c.Request.WithContext(ctx)
+
+		if tenant.ID != "" {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String(telemetry.TenantAttribute, tenant.ID))
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"path/filepath"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"sr-api/internal/core/ports/telemetry"
+	"time"
+)
+
+// PresignUploadHandler reserves an object key and returns a presigned URL the client
+// uploads the file to directly, bypassing this service.
+func (dep *UploadHandlerDependencies) PresignUploadHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "PresignUploadHandler")
+	defer span.End()
+
+	var request handlerStructure.PresignRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			ports.RespondWithClientError(c, span, err, http.StatusBadRequest, "Invalid presign request")
+			return
+		}
+	}
+
+	uploadID, err := domain.GenerateUIDWithContext(ctx)
+	if err != nil {
+		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to generate UUID for file")
+		return
+	}
+	caller := domain.CallerFromContext(ctx).ID
+	keyPrefix := dep.objectKeyPrefix(ctx, caller)
+	objectKey := fmt.Sprintf("%s%s%s", keyPrefix, uploadID, filepath.Ext(request.Filename))
+
+	expiresAt := time.Now().Add(dep.PresignExpiry).UTC()
+	uploadURL, headers, err := dep.MinioRepo.PresignPutObject(ctx, objectKey, dep.PresignExpiry)
+	if err != nil {
+		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to presign upload")
+		return
+	}
+	dep.PendingUploads.Set(uploadID, handlerStructure.PendingUpload{
+		ObjectKey:        objectKey,
+		OriginalFilename: request.Filename,
+		Caller:           caller,
+		Tenant:           domain.TenantFromContext(ctx).ID,
+	})
+
+	span.SetAttributes(attribute.String("upload.id", uploadID), attribute.String("file.name", objectKey))
+	response := handlerStructure.PresignResponse{
+		ID:        uploadID,
+		ObjectKey: objectKey,
+		UploadURL: uploadURL.String(),
+		Method:    http.MethodPut,
+		Headers:   make(map[string]string, len(headers)),
+		ExpiresAt: expiresAt,
+	}
+	for name := range headers {
+		response.Headers[name] = headers.Get(name)
+	}
+	c.JSON(http.StatusOK, response)
+	span.SetStatus(codes.Ok, "Upload presigned")
+}
+
+// CompleteUploadHandler verifies a presigned upload landed in the bucket and processes
+// it like a regular upload: the object is hashed, deduplicated, scanned and stored under
+// a new key, so that a later PUT to the presigned URL can never replace the content that
+// was checked. The presigned object is then removed.
+func (dep *UploadHandlerDependencies) CompleteUploadHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "CompleteUploadHandler")
+	defer span.End()
+
+	logger := telemetry.LoggerFromContext(ctx)
+	started := time.Now()
+	uploadID := c.Param("id")
+	span.SetAttributes(attribute.String("upload.id", uploadID))
+
+	audit := domain.NewAuditRecord(ctx, domain.AuditActionUpload)
+	defer func() {
+		audit.TimingsMs["total"] = time.Since(started).Milliseconds()
+		dep.recordAudit(ctx, audit)
+	}()
+	fail := func(err error, code int, message string) {
+		audit.Outcome = auditOutcome(code)
+		audit.Error = message
+		if code < http.StatusInternalServerError {
+			ports.RespondWithClientError(c, span, err, code, message)
+			return
+		}
+		ports.RespondWithError(c, span, err, code, message)
+	}
+
+	options, err := parseTranscribeOptions(c.Query)
+	if err != nil {
+		fail(err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Uploads presigned for another caller or tenant are reported as unknown.
+	pending, ok := dep.PendingUploads.Get(uploadID)
+	if !ok || pending.Caller != audit.Caller || pending.Tenant != audit.Tenant {
+		fail(fmt.Errorf("unknown upload: %s", uploadID), http.StatusNotFound, "Upload not found or expired")
+		return
+	}
+	audit.ObjectKey = pending.ObjectKey
+	audit.OriginalFilename = pending.OriginalFilename
+
+	if _, err := dep.MinioRepo.StatObjectMetadata(ctx, pending.ObjectKey); errors.Is(err, repository.ErrObjectNotFound) {
+		fail(err, http.StatusConflict, "File has not been uploaded yet")
+		return
+	} else if err != nil {
+		fail(err, http.StatusInternalServerError, "Failed to check uploaded file")
+		return
+	}
+
+	bucket := dep.MinioRepo.Bucket(ctx)
+	object, err := dep.MinioRepo.OpenObject(ctx, bucket, pending.ObjectKey)
+	if err != nil {
+		fail(err, http.StatusInternalServerError, "Failed to read uploaded file")
+		return
+	}
+	defer object.Close()
+	// The version read here is the one processed, see objectSource.
+	info, err := object.Stat()
+	if err != nil {
+		fail(err, http.StatusInternalServerError, "Failed to read uploaded file")
+		return
+	}
+	audit.Size = info.Size
+
+	source := &objectSource{Bucket: bucket, Object: pending.ObjectKey, ETag: info.ETag, Owned: true}
+	result, cacheHit, failure := dep.processFile(ctx, &audit, object, pending.OriginalFilename, info.Size, source, options)
+	// After a server-side failure, such as an unavailable scanner, the upload stays
+	// pending so that its completion can be retried.
+	if failure == nil || failure.code < http.StatusInternalServerError {
+		dep.PendingUploads.Delete(uploadID)
+		if err := dep.MinioRepo.RemoveObjectWithContext(ctx, pending.ObjectKey); err != nil {
+			logger.Error().Err(err).Str("file_name", pending.ObjectKey).Msg("Failed to remove presigned upload")
+		}
+	}
+	if failure != nil {
+		fail(failure.err, failure.code, failure.message)
+		return
+	}
+	respondWithTranscription(c, span, result, cacheHit)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"slices"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"sr-api/internal/core/ports/telemetry"
+	"time"
+)
+
+// ProgressHandler returns the progress of the chunked transcriptions of a request, by
+// the ID the client sent in X-Request-ID, while the request runs and for a while after.
+// Progress is kept per caller and tenant, so requests of others reusing the ID are
+// neither merged nor shown. Requests that transcribed nothing in chunks are reported as
+// not found.
+func (dep *UploadHandlerDependencies) ProgressHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "ProgressHandler")
+	defer span.End()
+
+	requestID := c.Param("id")
+	span.SetAttributes(attribute.String("progress.request_id", requestID))
+	dep.progressMu.Lock()
+	progress, ok := dep.Progress.Get(progressKey(ctx, requestID))
+	dep.progressMu.Unlock()
+	if !ok {
+		ports.RespondWithClientError(c, span, fmt.Errorf("unknown request: %s", requestID), http.StatusNotFound, "No chunked transcription for this request")
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+	span.SetStatus(codes.Ok, "Progress returned")
+}
+
+// reportProgress records the progress of the chunked transcription of objectKey under
+// the request ID in ctx. The files of a request are replaced rather than updated in
+// place, so that a returned RequestProgress is never modified.
+func (dep *UploadHandlerDependencies) reportProgress(ctx context.Context, objectKey string, state string, completed int, total int) {
+	requestID := domain.RequestIDFromContext(ctx)
+	if requestID == "" {
+		return
+	}
+	dep.progressMu.Lock()
+	defer dep.progressMu.Unlock()
+
+	key := progressKey(ctx, requestID)
+	progress, ok := dep.Progress.Get(key)
+	if !ok {
+		progress = handlerStructure.RequestProgress{RequestID: requestID}
+	}
+	file := handlerStructure.ChunkProgress{
+		ObjectKey:       objectKey,
+		State:           state,
+		ChunksCompleted: completed,
+		ChunksTotal:     total,
+		UpdatedAt:       time.Now().UTC(),
+	}
+	index := slices.IndexFunc(progress.Files, func(existing handlerStructure.ChunkProgress) bool {
+		return existing.ObjectKey == objectKey
+	})
+	progress.Files = slices.Clone(progress.Files)
+	if index < 0 {
+		progress.Files = append(progress.Files, file)
+	} else {
+		progress.Files[index] = file
+	}
+	dep.Progress.Set(key, progress)
+}
+
+// progressKey scopes a client-chosen request ID to the tenant and caller in ctx.
+func progressKey(ctx context.Context, requestID string) string {
+	return domain.TenantFromContext(ctx).ID + "\x00" + domain.CallerFromContext(ctx).ID + "\x00" + requestID
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"time"
+)
+
+// Content scan verdicts reported to the metrics pipeline.
+const (
+	scanVerdictClean    = "clean"
+	scanVerdictInfected = "infected"
+	scanVerdictError    = "error"
+)
+
+// scanContent runs the configured scanner over content and rewinds it. Infected content
+// is returned as an uploadError with the first return value set, so that the caller can
+// quarantine it. Scanner failures reject the file unless the scanner fails open.
+func (dep *UploadHandlerDependencies) scanContent(ctx context.Context, audit *domain.AuditRecord, content io.ReadSeeker) (bool, *uploadError) {
+	if dep.Scanner == nil {
+		return false, nil
+	}
+	logger := telemetry.LoggerFromContext(ctx)
+	metrics := telemetry.MetricsFromContext(ctx)
+
+	stepStarted := time.Now()
+	result, err := dep.Scanner.Scan(ctx, content)
+	audit.TimingsMs["scan"] = time.Since(stepStarted).Milliseconds()
+	if _, seekErr := content.Seek(0, io.SeekStart); seekErr != nil && err == nil {
+		return false, &uploadError{seekErr, http.StatusInternalServerError, "Failed to rewind scanned file"}
+	}
+	if err != nil {
+		metrics.RecordScan(ctx, scanVerdictError)
+		if dep.ScanFailOpen {
+			logger.Warn().Err(err).Msg("Content scan failed, accepting file unscanned")
+			return false, nil
+		}
+		return false, &uploadError{err, http.StatusServiceUnavailable, "Content scan unavailable, try again later"}
+	}
+	if result.Infected {
+		metrics.RecordScan(ctx, scanVerdictInfected)
+		audit.Reason = "Malware detected: " + result.Signature
+		logger.Warn().Str("scan.signature", result.Signature).Msg("Content scan flagged file")
+		return true, &uploadError{fmt.Errorf("malware detected: %s", result.Signature), http.StatusUnprocessableEntity, "File rejected by content scan"}
+	}
+	metrics.RecordScan(ctx, scanVerdictClean)
+	return false, nil
+}
+
+// quarantineUpload stores a flagged upload under the quarantine prefix for review, or
+// moves it there when it is an owned object of the bucket. An object of another bucket is
+// left in place. A failure is only logged, the file is rejected either way.
+func (dep *UploadHandlerDependencies) quarantineUpload(ctx context.Context, audit *domain.AuditRecord, file multipart.File, size int64, source *objectSource, objectKey string, metadata domain.ObjectMetadata) {
+	quarantineKey := dep.QuarantinePrefix + objectKey
+	var err error
+	switch {
+	case source == nil:
+		err = dep.MinioRepo.QuarantineUpload(ctx, quarantineKey, file, size, metadata)
+	case source.Owned:
+		err = dep.MinioRepo.QuarantineObject(ctx, source.Object, quarantineKey, metadata)
+	default:
+		return
+	}
+	if err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("file_name", quarantineKey).Msg("Failed to quarantine flagged file")
+		return
+	}
+	audit.ObjectKey = quarantineKey
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"sr-api/internal/core/ports/telemetry"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 100
+
+	// reindexRetryInterval is how long StartSearchReindex waits after a failed rebuild.
+	reindexRetryInterval = time.Minute
+)
+
+// SearchHandler runs a full-text search over the stored transcripts of the caller's
+// tenant. The q parameter is required; lang, offset and limit are optional.
+func (dep *UploadHandlerDependencies) SearchHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "SearchHandler")
+	defer span.End()
+
+	query := domain.SearchQuery{
+		Text:     c.Query("q"),
+		Language: c.Query("lang"),
+		Tenant:   domain.TenantFromContext(ctx).ID,
+		Limit:    defaultSearchPageSize,
+	}
+	if query.Text == "" {
+		ports.RespondWithClientError(c, span, fmt.Errorf("empty search query"), http.StatusBadRequest, "Missing 'q' search query")
+		return
+	}
+	var err error
+	if offset := c.Query("offset"); offset != "" {
+		if query.Offset, err = strconv.Atoi(offset); err != nil || query.Offset < 0 {
+			ports.RespondWithClientError(c, span, fmt.Errorf("invalid offset: %s", offset), http.StatusBadRequest, "Invalid 'offset', expected a non-negative integer")
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 || query.Limit > maxSearchPageSize {
+			ports.RespondWithClientError(c, span, fmt.Errorf("invalid limit: %s", limit), http.StatusBadRequest, fmt.Sprintf("Invalid 'limit', expected an integer between 1 and %d", maxSearchPageSize))
+			return
+		}
+	}
+
+	result, err := dep.SearchIndex.Search(ctx, query)
+	if err != nil {
+		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to search transcripts")
+		return
+	}
+
+	span.SetAttributes(attribute.Int("search.total", result.Total))
+	c.JSON(http.StatusOK, result)
+	span.SetStatus(codes.Ok, "Search completed")
+}
+
+// ReindexSearch rebuilds the search index from every stored transcript of every tenant.
+func (dep *UploadHandlerDependencies) ReindexSearch(ctx context.Context) error {
+	if dep.SearchIndex == nil || dep.TranscriptStore == nil {
+		return fmt.Errorf("search index or transcript store is not configured")
+	}
+	ctx, span := telemetry.StartSpan(ctx, "ReindexSearch")
+	defer span.End()
+
+	var transcripts []domain.Transcript
+	err := dep.SearchIndex.Rebuild(ctx, func(ctx context.Context) ([]domain.Transcript, error) {
+		for _, tenant := range dep.Tenants.All() {
+			page, err := dep.TranscriptStore.List(domain.ContextWithTenant(ctx, tenant), domain.TranscriptQuery{})
+			if err != nil {
+				return nil, err
+			}
+			transcripts = append(transcripts, page.Items...)
+		}
+		return transcripts, nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to rebuild search index")
+		return err
+	}
+	telemetry.LoggerFromContext(ctx).Info().Int("search.documents", len(transcripts)).Msg("Search index rebuilt")
+	span.SetStatus(codes.Ok, "Search index rebuilt")
+	return nil
+}
+
+// StartSearchReindex rebuilds an index that is not persisted from the stored transcripts
+// in the background, so that the service starts while MinIO is unreachable. Requests
+// are served meanwhile, searching only the transcripts indexed so far; a failed rebuild
+// is logged and retried every reindexRetryInterval until ctx is done.
+func (dep *UploadHandlerDependencies) StartSearchReindex(ctx context.Context, cfg *config.AppConfig) {
+	if dep.SearchIndex == nil || dep.TranscriptStore == nil || cfg.SearchIndexPath != "" {
+		return
+	}
+	go func() {
+		for {
+			err := dep.ReindexSearch(ctx)
+			if err == nil {
+				return
+			}
+			telemetry.LoggerFromContext(ctx).Warn().Err(err).Dur("retry_in", reindexRetryInterval).Msg("Failed to rebuild search index")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reindexRetryInterval):
+			}
+		}
+	}()
+}
+
+// indexTranscript adds a completed transcription to the search index. A failure is logged
+// but does not fail the request.
+func (dep *UploadHandlerDependencies) indexTranscript(ctx context.Context, transcript domain.Transcript) {
+	if dep.SearchIndex == nil {
+		return
+	}
+	if err := dep.SearchIndex.Index(ctx, transcript); err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("transcript.id", transcript.ID).Msg("Failed to index transcript")
+	}
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"path"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"sr-api/internal/core/ports/telemetry"
+	"time"
+)
+
+// TranscribeHandler transcribes audio that is not uploaded with the request, fetched
+// either from an allowlisted URL or from an object in an allowlisted bucket.
+func (dep *UploadHandlerDependencies) TranscribeHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "TranscribeHandler")
+	defer span.End()
+
+	started := time.Now()
+	audit := domain.NewAuditRecord(ctx, domain.AuditActionUpload)
+	defer func() {
+		audit.TimingsMs["total"] = time.Since(started).Milliseconds()
+		dep.recordAudit(ctx, audit)
+	}()
+	fail := func(err error, code int, message string) {
+		audit.Outcome = auditOutcome(code)
+		audit.Error = message
+		if code < http.StatusInternalServerError {
+			ports.RespondWithClientError(c, span, err, code, message)
+			return
+		}
+		ports.RespondWithError(c, span, err, code, message)
+	}
+
+	var request handlerStructure.TranscribeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		fail(err, http.StatusBadRequest, "Invalid transcribe request")
+		return
+	}
+	options := transcribeOptions{
+		NoCache:     request.NoCache,
+		DeleteAudio: request.DeleteAudio,
+		Normalize:   request.Normalize,
+		Diarize:     request.Diarize,
+		NumSpeakers: request.NumSpeakers,
+		Redact:      request.Redact,
+		Analyze:     request.Analyze,
+	}
+	if err := validateNumSpeakers(options.NumSpeakers); err != nil {
+		fail(err, http.StatusBadRequest, err.Error())
+		return
+	}
+	var err error
+	if request.PostProcess != nil {
+		if options.PostProcess, err = domain.ParsePostProcessSteps(request.PostProcess); err != nil {
+			fail(err, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if options.TargetLanguage, err = domain.NormalizeTargetLanguage(request.TargetLanguage); err != nil {
+		fail(err, http.StatusBadRequest, err.Error())
+		return
+	}
+	if options.Transcription, err = domain.NormalizeTranscriptionOptions(request.TranscriptionOptions); err != nil {
+		fail(err, http.StatusBadRequest, err.Error())
+		return
+	}
+	if options.Confidence, err = domain.NormalizeConfidenceFilter(request.ConfidenceFilter); err != nil {
+		fail(err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch {
+	case request.URL != "" && request.Bucket == "" && request.Object == "":
+		audit.Source = request.URL
+		dep.transcribeURL(ctx, c, span, &audit, fail, request.URL, options)
+	case request.URL == "" && request.Bucket != "" && request.Object != "":
+		audit.Source = request.Bucket + "/" + request.Object
+		dep.transcribeBucketObject(ctx, c, span, &audit, fail, request.Bucket, request.Object, options)
+	default:
+		fail(fmt.Errorf("ambiguous transcribe request"), http.StatusBadRequest, "Expected either 'url' or 'bucket' and 'object'")
+	}
+}
+
+// transcribeURL downloads the file and handles it like a multipart upload.
+func (dep *UploadHandlerDependencies) transcribeURL(ctx context.Context, c *gin.Context, span trace.Span, audit *domain.AuditRecord, fail func(err error, code int, message string), rawURL string, options transcribeOptions) {
+	if dep.RemoteFetcher == nil {
+		fail(fmt.Errorf("URL sources are disabled"), http.StatusForbidden, "Transcribing from URLs is disabled")
+		return
+	}
+
+	stepStarted := time.Now()
+	file, err := dep.RemoteFetcher.Fetch(ctx, rawURL)
+	audit.TimingsMs["fetch"] = time.Since(stepStarted).Milliseconds()
+	switch {
+	case errors.Is(err, repository.ErrSourceNotAllowed):
+		fail(err, http.StatusForbidden, "URL is not allowed")
+		return
+	case errors.Is(err, repository.ErrSourceTooLarge):
+		fail(err, http.StatusRequestEntityTooLarge, "Remote file is too large")
+		return
+	case err != nil:
+		fail(err, http.StatusBadGateway, "Failed to fetch remote file")
+		return
+	}
+	defer file.Close()
+	audit.OriginalFilename = file.Filename
+	audit.Size = file.Size
+
+	result, cacheHit, failure := dep.processFile(ctx, audit, file, file.Filename, file.Size, nil, options)
+	if failure != nil {
+		fail(failure.err, failure.code, failure.message)
+		return
+	}
+	respondWithTranscription(c, span, result, cacheHit)
+}
+
+// transcribeBucketObject processes an existing object like an upload. The object is
+// copied into the upload bucket with a server-side copy, or stored as its audio track for
+// a video, so that retention and deletion never touch the source object. Objects stored
+// by another tenant are refused like objects of a bucket that is not allowed.
+func (dep *UploadHandlerDependencies) transcribeBucketObject(ctx context.Context, c *gin.Context, span trace.Span, audit *domain.AuditRecord, fail func(err error, code int, message string), bucket string, object string, options transcribeOptions) {
+	if !dep.Tenants.MayTranscribeFrom(domain.TenantFromContext(ctx).ID, bucket, object) {
+		fail(fmt.Errorf("bucket not allowed: %s", bucket), http.StatusForbidden, "Bucket is not allowed")
+		return
+	}
+
+	stored, err := dep.MinioRepo.StatObjectMetadataFrom(ctx, bucket, object)
+	if errors.Is(err, repository.ErrObjectNotFound) {
+		fail(err, http.StatusNotFound, "Object not found")
+		return
+	}
+	if err != nil {
+		fail(err, http.StatusInternalServerError, "Failed to read object")
+		return
+	}
+	originalFilename := stored.OriginalFilename
+	if originalFilename == "" {
+		originalFilename = path.Base(object)
+	}
+	audit.OriginalFilename = originalFilename
+
+	sourceObject, err := dep.MinioRepo.OpenObject(ctx, bucket, object)
+	if err != nil {
+		fail(err, http.StatusInternalServerError, "Failed to read object")
+		return
+	}
+	defer sourceObject.Close()
+	// The version read here is the one processed, see objectSource.
+	info, err := sourceObject.Stat()
+	if err != nil {
+		fail(err, http.StatusInternalServerError, "Failed to read object")
+		return
+	}
+	audit.Size = info.Size
+
+	source := &objectSource{Bucket: bucket, Object: object, ETag: info.ETag}
+	result, cacheHit, failure := dep.processFile(ctx, audit, sourceObject, originalFilename, info.Size, source, options)
+	if failure != nil {
+		fail(failure.err, failure.code, failure.message)
+		return
+	}
+	respondWithTranscription(c, span, result, cacheHit)
+}
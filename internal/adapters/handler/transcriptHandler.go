@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"sr-api/internal/core/ports/telemetry"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTranscriptPageSize = 50
+	maxTranscriptPageSize     = 500
+)
+
+// GetTranscriptHandler returns the stored transcript with the given ID, together with
+// the metadata of its audio object when the audio has not been purged. With format=srt
+// or format=vtt its segments are rendered as subtitles instead, those of its translation
+// with translated=true.
+func (dep *UploadHandlerDependencies) GetTranscriptHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "GetTranscriptHandler")
+	defer span.End()
+
+	id := c.Param("id")
+	format := c.DefaultQuery("format", "json")
+	span.SetAttributes(attribute.String("transcript.id", id), attribute.String("transcript.format", format))
+	if format != "json" && format != domain.SubtitleFormatSRT && format != domain.SubtitleFormatVTT {
+		ports.RespondWithClientError(c, span, fmt.Errorf("unknown format: %s", format), http.StatusBadRequest, "Expected format json, srt or vtt")
+		return
+	}
+	transcript, err := dep.TranscriptStore.Get(ctx, id)
+	if errors.Is(err, ports.ErrTranscriptNotFound) {
+		ports.RespondWithClientError(c, span, err, http.StatusNotFound, "Transcript not found")
+		return
+	}
+	if err != nil {
+		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to read transcript")
+		return
+	}
+	if format != "json" {
+		segments := transcript.Segments
+		if parseBoolForm(c.Query("translated")) {
+			if transcript.Translation == nil {
+				ports.RespondWithClientError(c, span, fmt.Errorf("transcript %s has no translation", id), http.StatusNotFound, "Transcript has no translation")
+				return
+			}
+			segments = domain.TranslatedSegments(*transcript.Translation)
+		}
+		respondWithSubtitles(c, span, id, segments, format)
+		return
+	}
+	metadata, err := dep.MinioRepo.StatObjectMetadata(ctx, transcript.ObjectKey)
+	if err == nil {
+		transcript.Audio = &metadata
+	} else if !errors.Is(err, repository.ErrObjectNotFound) {
+		telemetry.LoggerFromContext(ctx).Warn().Err(err).Str("file_name", transcript.ObjectKey).Msg("Failed to read audio object metadata")
+	}
+
+	c.JSON(http.StatusOK, transcript)
+	span.SetStatus(codes.Ok, "Transcript returned")
+}
+
+// respondWithSubtitles writes the segments of a transcript in a subtitle format.
+func respondWithSubtitles(c *gin.Context, span trace.Span, id string, segments []domain.Segment, format string) {
+	if len(segments) == 0 {
+		ports.RespondWithClientError(c, span, fmt.Errorf("transcript %s has no segments", id), http.StatusUnprocessableEntity, "Transcript has no segment timings")
+		return
+	}
+	if format == domain.SubtitleFormatSRT {
+		c.Data(http.StatusOK, "application/x-subrip; charset=utf-8", []byte(domain.RenderSRT(segments)))
+	} else {
+		c.Data(http.StatusOK, "text/vtt; charset=utf-8", []byte(domain.RenderVTT(segments)))
+	}
+	span.SetStatus(codes.Ok, "Transcript rendered")
+}
+
+// ListTranscriptsHandler returns a page of stored transcripts filtered by the optional
+// lang, from and to (RFC 3339) query parameters and paginated with offset and limit.
+func (dep *UploadHandlerDependencies) ListTranscriptsHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "ListTranscriptsHandler")
+	defer span.End()
+
+	query, err := parseTranscriptQuery(c)
+	if err != nil {
+		ports.RespondWithClientError(c, span, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := dep.TranscriptStore.List(ctx, query)
+	if err != nil {
+		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to list transcripts")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+	span.SetStatus(codes.Ok, "Transcripts listed")
+}
+
+// DeleteTranscriptHandler removes the transcript, and its audio object once no other
+// transcript of the same content references it.
+func (dep *UploadHandlerDependencies) DeleteTranscriptHandler(c *gin.Context) {
+	ctx, span := telemetry.StartSpanFromGinContext(c, "DeleteTranscriptHandler")
+	defer span.End()
+
+	id := c.Param("id")
+	span.SetAttributes(attribute.String("transcript.id", id))
+	audit := domain.NewAuditRecord(ctx, domain.AuditActionDelete)
+	defer func() { dep.recordAudit(ctx, audit) }()
+
+	transcript, audioRemoved, err := dep.TranscriptStore.Delete(ctx, id)
+	audit.ObjectKey = transcript.ObjectKey
+	audit.SHA256 = transcript.SHA256
+	if errors.Is(err, ports.ErrTranscriptNotFound) {
+		audit.Outcome = domain.AuditOutcomeRejected
+		audit.Error = "Transcript not found"
+		ports.RespondWithClientError(c, span, err, http.StatusNotFound, "Transcript not found")
+		return
+	}
+	if err != nil {
+		audit.Outcome = domain.AuditOutcomeFailed
+		audit.Error = "Failed to delete transcript"
+		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to delete transcript")
+		return
+	}
+	audit.Outcome = domain.AuditOutcomeSuccess
+	dep.forgetContent(dep.contentKey(ctx, transcript.SHA256), audioRemoved)
+	if dep.SearchIndex != nil {
+		if err := dep.SearchIndex.Remove(ctx, id); err != nil {
+			telemetry.LoggerFromContext(ctx).Error().Err(err).Str("transcript.id", id).Msg("Failed to remove transcript from search index")
+		}
+	}
+
+	c.Status(http.StatusNoContent)
+	span.SetStatus(codes.Ok, "Transcript deleted")
+}
+
+// forgetContent drops the cached results of content whose transcript was deleted, since
+// they carry its ID, identified by its tenant-scoped content key. The object index entry
+// is dropped too once the audio object is removed.
+func (dep *UploadHandlerDependencies) forgetContent(contentKey string, objectRemoved bool) {
+	if contentKey == "" {
+		return
+	}
+	if objectRemoved {
+		dep.ObjectIndex.Delete(contentKey)
+	}
+	dep.ResultCache.DeleteMatching(func(key string) bool {
+		return strings.HasPrefix(key, contentKey+":")
+	})
+}
+
+func parseTranscriptQuery(c *gin.Context) (domain.TranscriptQuery, error) {
+	query := domain.TranscriptQuery{
+		Language: c.Query("lang"),
+		Limit:    defaultTranscriptPageSize,
+	}
+	var err error
+	if from := c.Query("from"); from != "" {
+		if query.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return query, fmt.Errorf("invalid 'from' timestamp, expected RFC 3339")
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if query.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return query, fmt.Errorf("invalid 'to' timestamp, expected RFC 3339")
+		}
+	}
+	if offset := c.Query("offset"); offset != "" {
+		if query.Offset, err = strconv.Atoi(offset); err != nil || query.Offset < 0 {
+			return query, fmt.Errorf("invalid 'offset', expected a non-negative integer")
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 || query.Limit > maxTranscriptPageSize {
+			return query, fmt.Errorf("invalid 'limit', expected an integer between 1 and %d", maxTranscriptPageSize)
+		}
+	}
+	return query, nil
+}
@@ -1,24 +1,112 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
+	"sr-api/internal/adapters/handler/handlerStructure"
 	"sr-api/internal/adapters/repository"
 	"sr-api/internal/config"
 	"sr-api/internal/core/domain"
 	"sr-api/internal/core/ports"
 	"sr-api/internal/core/ports/telemetry"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// CacheHeader reports whether the transcription was served from the result cache.
+const CacheHeader = "X-Cache"
+
+// maxPendingUploads bounds the presigned uploads awaiting completion.
+const maxPendingUploads = 10000
+
+// maxTrackedRequests bounds the requests whose chunked transcription progress is kept,
+// each for progressRetention after its last update.
+const (
+	maxTrackedRequests = 10000
+	progressRetention  = time.Hour
+)
+
+// maxNumSpeakers bounds the number of speakers a request may announce.
+const maxNumSpeakers = 20
+
 type UploadHandlerDependencies struct {
 	WhisperRepo *repository.WhisperRepository
 	MinioRepo   *repository.MinioRepository
+	// AuditSink is nil when auditing is disabled. AuditAdmins are the API key names
+	// allowed to read the audit records of other callers.
+	AuditSink   ports.AuditSink
+	AuditAdmins []string
+	// ResultCache maps a content hash and transcription options to a previous result.
+	ResultCache *repository.MemoryCache[domain.RecognitionSuccess]
+	// ObjectIndex maps a content hash to the object already holding that content.
+	ObjectIndex *repository.MemoryCache[string]
+	// TranscriptStore is nil when transcripts are not persisted.
+	TranscriptStore ports.TranscriptStore
+	// SearchIndex is nil when search is disabled or transcripts are not persisted.
+	SearchIndex ports.SearchIndex
+	// ObjectKeyPrefix is the key prefix template for uploaded objects.
+	ObjectKeyPrefix string
+	// DeleteAudioAfterTranscription purges the uploaded audio once it has been transcribed.
+	DeleteAudioAfterTranscription bool
+	// MediaPolicy is applied to every file after the signature check.
+	MediaPolicy domain.MediaPolicy
+	// Scanner is nil when content scanning is disabled.
+	Scanner          ports.Scanner
+	ScanFailOpen     bool
+	QuarantinePrefix string
+	// AudioNormalization transcribes a normalized derivative of WAV uploads by default.
+	AudioNormalization bool
+	// Diarizer is nil when speaker diarization is disabled, Diarization enables it by default.
+	Diarizer    ports.Diarizer
+	Diarization bool
+	// Redactor removes personal data from transcripts when Redaction is enabled by
+	// default or a request asks for it.
+	Redactor  *domain.Redactor
+	Redaction bool
+	// PostProcessSteps run after Whisper unless a request selects others.
+	PostProcessSteps  []string
+	PostProcessConfig domain.PostProcessConfig
+	// Translator is nil when translation is disabled.
+	Translator ports.Translator
+	// Analysis adds a summary and keywords to transcripts by default. Summarizer is nil
+	// when summaries are extractive.
+	Analysis       bool
+	AnalysisConfig domain.AnalysisConfig
+	Summarizer     ports.Summarizer
+	// ChunkDuration, ChunkOverlap and ChunkConcurrency control the chunked transcription
+	// of long recordings, a zero ChunkDuration disables it.
+	ChunkDuration    time.Duration
+	ChunkOverlap     time.Duration
+	ChunkConcurrency int
+	// Progress maps request IDs to the progress of their chunked transcriptions, updated
+	// under progressMu.
+	Progress   *repository.MemoryCache[handlerStructure.RequestProgress]
+	progressMu sync.Mutex
+	// BatchMaxFiles, BatchConcurrency and BatchMaxArchiveBytes limit batch uploads.
+	BatchMaxFiles        int
+	BatchConcurrency     int
+	BatchMaxArchiveBytes int64
+	// PendingUploads maps presigned upload IDs to the uploads awaiting completion.
+	PendingUploads *repository.MemoryCache[handlerStructure.PendingUpload]
+	PresignExpiry  time.Duration
+	// RemoteFetcher is nil when transcription from URLs is disabled.
+	RemoteFetcher *repository.RemoteFetcher
+	// Tenants maps callers to the tenant isolating their storage and results, Quotas
+	// enforces the daily quotas of the tenants.
+	Tenants *domain.TenantRegistry
+	Quotas  *repository.QuotaTracker
 }
 
 func NewUploadHandlerDependencies(cfg *config.AppConfig) (*UploadHandlerDependencies, error) {
@@ -32,62 +120,815 @@ func NewUploadHandlerDependencies(cfg *config.AppConfig) (*UploadHandlerDependen
 		return nil, fmt.Errorf("failed to create Minio repository: %w", err)
 	}
 
-	return &UploadHandlerDependencies{
-		WhisperRepo: whisperRepo,
-		MinioRepo:   minioRepo,
-	}, nil
+	auditSink, err := repository.NewAuditSink(cfg, minioRepo.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit sink: %w", err)
+	}
+
+	transcriptStore, err := repository.NewTranscriptStore(cfg, minioRepo.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcript store: %w", err)
+	}
+
+	scanner, err := repository.NewScanner(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create content scanner: %w", err)
+	}
+
+	diarizer, err := repository.NewDiarizer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create diarizer: %w", err)
+	}
+
+	translator, err := repository.NewTranslator(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create translator: %w", err)
+	}
+
+	summarizer, err := repository.NewSummarizer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create summarizer: %w", err)
+	}
+
+	var redactionRules map[string]string
+	if cfg.RedactionRules != "" {
+		if err := json.Unmarshal([]byte(cfg.RedactionRules), &redactionRules); err != nil {
+			return nil, fmt.Errorf("failed to parse redaction rules: %w", err)
+		}
+	}
+	redactor, err := domain.NewRedactor(redactionRules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create redactor: %w", err)
+	}
+
+	var tenants map[string]domain.Tenant
+	if cfg.Tenants != "" {
+		if err := json.Unmarshal([]byte(cfg.Tenants), &tenants); err != nil {
+			return nil, fmt.Errorf("failed to parse tenants: %w", err)
+		}
+	}
+	var apiKeyCallers []string
+	if cfg.TenantFromAPIKey {
+		for _, name := range cfg.APIKeys {
+			apiKeyCallers = append(apiKeyCallers, name)
+		}
+	}
+	defaultTenant := domain.Tenant{Bucket: cfg.MinioBucket, SourceBuckets: cfg.TranscribeSourceBuckets}
+	tenantRegistry, err := domain.NewTenantRegistry(defaultTenant, tenants, apiKeyCallers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure tenants: %w", err)
+	}
+
+	postProcessSteps, err := domain.ParsePostProcessSteps(cfg.PostProcessSteps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse post-processing steps: %w", err)
+	}
+	postProcessConfig := domain.PostProcessConfig{ProfanityWords: cfg.PostProcessProfanityWords}
+	if cfg.PostProcessVocabulary != "" {
+		if err := json.Unmarshal([]byte(cfg.PostProcessVocabulary), &postProcessConfig.Vocabulary); err != nil {
+			return nil, fmt.Errorf("failed to parse post-processing vocabulary: %w", err)
+		}
+	}
+
+	dep := &UploadHandlerDependencies{
+		WhisperRepo:     whisperRepo,
+		MinioRepo:       minioRepo,
+		AuditSink:       auditSink,
+		AuditAdmins:     cfg.AuditAdmins,
+		ResultCache:     repository.NewMemoryCache[domain.RecognitionSuccess](cfg.ResultCacheSize, cfg.ResultCacheTTL),
+		ObjectIndex:     repository.NewMemoryCache[string](cfg.ResultCacheSize, cfg.ResultCacheTTL),
+		TranscriptStore: transcriptStore,
+
+		ObjectKeyPrefix:               cfg.ObjectKeyPrefix,
+		DeleteAudioAfterTranscription: cfg.DeleteAudioAfterTranscription,
+		MediaPolicy: domain.MediaPolicy{
+			AllowedTypes:   cfg.MediaAllowedTypes,
+			CheckExtension: cfg.MediaCheckExtension,
+			CheckStructure: cfg.MediaCheckStructure,
+		},
+		AnalysisConfig: domain.AnalysisConfig{
+			SummarySentences: cfg.AnalysisSummarySentences,
+			Keywords:         cfg.AnalysisKeywords,
+		},
+		Scanner:              scanner,
+		ScanFailOpen:         cfg.ScanFailOpen,
+		QuarantinePrefix:     cfg.QuarantinePrefix,
+		AudioNormalization:   cfg.AudioNormalization,
+		Diarizer:             diarizer,
+		Diarization:          cfg.Diarization,
+		Redactor:             redactor,
+		Redaction:            cfg.Redaction,
+		PostProcessSteps:     postProcessSteps,
+		PostProcessConfig:    postProcessConfig,
+		Translator:           translator,
+		Analysis:             cfg.Analysis,
+		Summarizer:           summarizer,
+		ChunkDuration:        cfg.TranscribeChunkDuration,
+		ChunkOverlap:         cfg.TranscribeChunkOverlap,
+		ChunkConcurrency:     cfg.TranscribeChunkConcurrency,
+		Progress:             repository.NewMemoryCache[handlerStructure.RequestProgress](maxTrackedRequests, progressRetention),
+		BatchMaxFiles:        cfg.BatchMaxFiles,
+		BatchConcurrency:     cfg.BatchConcurrency,
+		BatchMaxArchiveBytes: cfg.BatchMaxArchiveBytes,
+		PendingUploads:       repository.NewMemoryCache[handlerStructure.PendingUpload](maxPendingUploads, cfg.PresignExpiry),
+		PresignExpiry:        cfg.PresignExpiry,
+		Tenants:              tenantRegistry,
+		Quotas:               repository.NewQuotaTracker(),
+	}
+
+	if len(cfg.TranscribeURLAllowlist) > 0 {
+		dep.RemoteFetcher, err = repository.NewRemoteFetcher(cfg.TranscribeURLAllowlist, cfg.TranscribeURLMaxBytes, cfg.TranscribeURLTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create remote fetcher: %w", err)
+		}
+	}
+
+	if cfg.SearchIndexEnabled && transcriptStore != nil {
+		searchIndex, err := repository.NewMemorySearchIndex(cfg.SearchIndexPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load search index: %w", err)
+		}
+		dep.SearchIndex = searchIndex
+	}
+
+	return dep, nil
+}
+
+// StartRetention applies the configured audio retention policy, either by installing a
+// bucket lifecycle rule or by starting the background sweeper. It does nothing when no
+// retention period is configured.
+func (dep *UploadHandlerDependencies) StartRetention(ctx context.Context, cfg *config.AppConfig) error {
+	if cfg.RetentionPeriod <= 0 {
+		return nil
+	}
+	switch cfg.RetentionMode {
+	case "lifecycle":
+		return repository.ApplyLifecycleRetention(ctx, dep.MinioRepo, dep.Tenants.Buckets(), cfg.RetentionPeriod)
+	case "sweeper":
+		sweeper, err := repository.NewRetentionSweeper(dep.MinioRepo, dep.Tenants, dep.AuditSink, cfg.RetentionPeriod, cfg.RetentionSweepInterval)
+		if err != nil {
+			return err
+		}
+		go sweeper.Run(ctx)
+		return nil
+	default:
+		return fmt.Errorf("unknown retention mode: %s", cfg.RetentionMode)
+	}
 }
 
 func (dep *UploadHandlerDependencies) UploadHandler(c *gin.Context) {
-	_, span := telemetry.StartSpanFromGinContext(c, "UploadHandler")
+	ctx, span := telemetry.StartSpanFromGinContext(c, "UploadHandler")
 	defer span.End()
 
-	spanID := telemetry.GetSpanId(span)
+	logger := telemetry.LoggerFromContext(ctx)
+	started := time.Now()
+
+	audit := domain.NewAuditRecord(ctx, domain.AuditActionUpload)
+	defer func() {
+		audit.TimingsMs["total"] = time.Since(started).Milliseconds()
+		dep.recordAudit(ctx, audit)
+	}()
+	fail := func(err error, code int, message string) {
+		audit.Outcome = auditOutcome(code)
+		audit.Error = message
+		if code < http.StatusInternalServerError {
+			ports.RespondWithClientError(c, span, err, code, message)
+			return
+		}
+		ports.RespondWithError(c, span, err, code, message)
+	}
 
-	log.Debug().Str("span_id", spanID).Msg("Starting UploadHandler")
+	logger.Debug().Msg("Starting UploadHandler")
 	// Extract the file from the request
 	file, err := c.FormFile("file")
 	if err != nil {
-		ports.RespondWithError(c, span, err, http.StatusBadRequest, "Failed to get uploaded file")
+		fail(err, http.StatusBadRequest, "Failed to get uploaded file")
 		return
 	}
-	log.Debug().Str("span_id", spanID).Str("file_name", file.Filename).Msg("File extracted from the request")
+	logger.Debug().Str("file_name", file.Filename).Msg("File extracted from the request")
+	audit.OriginalFilename = file.Filename
+	audit.Size = file.Size
 
 	openedFile, err := file.Open()
 	if err != nil {
-		ports.RespondWithError(c, span, err, http.StatusBadRequest, "Failed to open uploaded file")
+		fail(err, http.StatusBadRequest, "Failed to open uploaded file")
 		return
 	}
 	defer openedFile.Close()
-	log.Debug().Str("span_id", spanID).Msg("Opened file successfully")
+	logger.Debug().Msg("Opened file successfully")
 
-	if err := domain.CheckFileSignatureWithGinContext(c, openedFile); err != nil {
-		ports.RespondWithError(c, span, err, http.StatusBadRequest, "Invalid file signature")
+	options, err := parseTranscribeOptions(c.PostForm)
+	if err != nil {
+		fail(err, http.StatusBadRequest, err.Error())
+		return
+	}
+	result, cacheHit, failure := dep.processFile(ctx, &audit, openedFile, file.Filename, file.Size, nil, options)
+	if failure != nil {
+		fail(failure.err, failure.code, failure.message)
 		return
 	}
-	log.Info().Str("span_id", spanID).Msg("File signature verified")
+	respondWithTranscription(c, span, result, cacheHit)
+}
+
+// transcribeOptions are the per-request switches shared by the transcription entry points.
+type transcribeOptions struct {
+	// NoCache skips the result cache lookup and forces a fresh transcription.
+	NoCache bool
+	// DeleteAudio purges the stored audio once it has been transcribed.
+	DeleteAudio bool
+	// Normalize overrides the configured audio normalization when set.
+	Normalize *bool
+	// Diarize overrides the configured speaker diarization when set, NumSpeakers is the
+	// expected number of speakers or zero.
+	Diarize     *bool
+	NumSpeakers int
+	// Redact overrides the configured redaction of personal data when set.
+	Redact *bool
+	// PostProcess overrides the configured post-processing steps when not nil.
+	PostProcess []string
+	// TargetLanguage asks for a translation of the transcript when not empty.
+	TargetLanguage string
+	// Analyze overrides the configured summary and keyword extraction when set.
+	Analyze *bool
+	// Transcription is forwarded to Whisper and part of the result cache key.
+	Transcription domain.TranscriptionOptions
+	// Confidence is applied to the response, never to cached or stored results.
+	Confidence domain.ConfidenceFilter
+}
+
+// objectSource is an object already stored in a bucket that processFile reads. The
+// object is copied server-side instead of being uploaded again, and only if it still has
+// the ETag it was read with. An owned object, a presigned upload, is moved to quarantine
+// when flagged; other objects are not the service's to move.
+type objectSource struct {
+	Bucket string
+	Object string
+	ETag   string
+	Owned  bool
+}
+
+// processFile verifies the signature of an opened file, returns a cached result when
+// the content is known, otherwise stores the file unless an identical object exists and
+// transcribes it. source is the object the file was opened from, nil for uploaded
+// content. The second return value reports a cache hit.
+func (dep *UploadHandlerDependencies) processFile(ctx context.Context, audit *domain.AuditRecord, openedFile multipart.File, originalFilename string, size int64, source *objectSource, options transcribeOptions) (domain.RecognitionSuccess, bool, *uploadError) {
+	logger := telemetry.LoggerFromContext(ctx)
+	span := trace.SpanFromContext(ctx)
 
-	fileExt := filepath.Ext(file.Filename)
-	fileUUID, err := domain.GenerateUIDWithContext(c)
+	stepStarted := time.Now()
+	mimeType, err := domain.ValidateMediaWithContext(ctx, openedFile, originalFilename, dep.MediaPolicy)
+	audit.TimingsMs["signature"] = time.Since(stepStarted).Milliseconds()
 	if err != nil {
-		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to generate UUID for file")
-		return
+		return domain.RecognitionSuccess{}, false, &uploadError{err, http.StatusBadRequest, mediaRejectionMessage(err)}
+	}
+	logger.Info().Msg("File signature verified")
+	audit.MIMEType = mimeType
+
+	digest, err := domain.FileSHA256WithContext(ctx, openedFile)
+	if err != nil {
+		return domain.RecognitionSuccess{}, false, &uploadError{err, http.StatusInternalServerError, "Failed to hash uploaded file"}
+	}
+	audit.SHA256 = digest
+
+	metrics := telemetry.MetricsFromContext(ctx)
+	// Only RIFF/WAVE files have a duration and can be normalized.
+	audioSeconds, hasDuration := domain.AudioDurationSeconds(openedFile)
+	normalize := hasDuration && dep.normalizeEnabled(options)
+	optionsKey := domain.TranscriptionOptionsKey(options.Transcription)
+	if normalize {
+		optionsKey += ";normalized"
+	}
+	if dep.diarizeEnabled(options) {
+		optionsKey += ";speakers=" + strconv.Itoa(options.NumSpeakers)
+	}
+	if steps := dep.postProcessSteps(options); len(steps) > 0 {
+		optionsKey += ";postprocess=" + strings.Join(steps, ",")
+	}
+	if dep.translateEnabled(options) {
+		optionsKey += ";target=" + options.TargetLanguage
+	}
+	if dep.analyzeEnabled(options) {
+		optionsKey += ";analysis"
+	}
+	contentKey := dep.contentKey(ctx, digest)
+	cacheKey := domain.ResultCacheKey(contentKey, optionsKey)
+	if !options.NoCache && dep.ResultCache != nil {
+		cached, hit := dep.ResultCache.Get(cacheKey)
+		metrics.RecordCacheLookup(ctx, hit)
+		if hit {
+			logger.Info().Str("file.sha256", digest).Msg("Serving transcription from result cache")
+			audit.ObjectKey, _ = dep.ObjectIndex.Get(contentKey)
+			audit.DetectedLanguage = cached.DetectedLang
+			audit.CacheHit = true
+			audit.Outcome = domain.AuditOutcomeSuccess
+			span.SetAttributes(attribute.Bool("cache.hit", true))
+			return dep.responseResult(ctx, cached, options), true, nil
+		}
 	}
-	fileName := fmt.Sprintf("%s%s", fileUUID, fileExt)
-	if err := dep.MinioRepo.UploadToMinioWithContext(c, fileName, openedFile, file.Size); err != nil {
-		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to upload file")
+
+	if failure := dep.reserveQuota(ctx, size); failure != nil {
+		return domain.RecognitionSuccess{}, false, failure
+	}
+
+	// Videos are stored as their audio track, the track chosen is kept with the transcript.
+	var audioTrack *domain.AudioTrack
+	fileName, reused := dep.findStoredObject(ctx, contentKey)
+	if !reused {
+		fileUUID, err := domain.GenerateUIDWithContext(ctx)
+		if err != nil {
+			return domain.RecognitionSuccess{}, false, &uploadError{err, http.StatusInternalServerError, "Failed to generate UUID for file"}
+		}
+		keyPrefix := dep.objectKeyPrefix(ctx, audit.Caller)
+		fileName = fmt.Sprintf("%s%s%s", keyPrefix, fileUUID, filepath.Ext(originalFilename))
+		metadata := domain.ObjectMetadata{
+			OriginalFilename: originalFilename,
+			Uploader:         audit.Caller,
+			SHA256:           digest,
+			ContentType:      mimeType,
+			DurationSeconds:  audioSeconds,
+		}
+		if infected, failure := dep.scanContent(ctx, audit, openedFile); failure != nil {
+			if infected {
+				dep.quarantineUpload(ctx, audit, openedFile, size, source, fileName, metadata)
+			}
+			return domain.RecognitionSuccess{}, false, failure
+		}
+
+		storedSize := size
+		copySource := source
+		if domain.HasExtractableAudio(mimeType) {
+			extracted, failure := extractAudio(ctx, audit, openedFile, mimeType)
+			if failure != nil {
+				return domain.RecognitionSuccess{}, false, failure
+			}
+			defer extracted.Close()
+			openedFile, storedSize = extracted, extracted.Size
+			fileName = fmt.Sprintf("%s%s%s", keyPrefix, fileUUID, extracted.Extension)
+			metadata.ContentType = extracted.MIMEType
+			audioTrack = &extracted.Track
+			// The extracted track is uploaded, the video is never copied.
+			copySource = nil
+		}
+
+		stepStarted = time.Now()
+		failureMessage := "Failed to upload file"
+		if copySource != nil {
+			err = dep.MinioRepo.CopyObjectFrom(ctx, copySource.Bucket, copySource.Object, copySource.ETag, fileName, metadata)
+			failureMessage = "Failed to copy object"
+		} else {
+			err = dep.MinioRepo.UploadToMinioWithContext(ctx, fileName, openedFile, storedSize, metadata)
+		}
+		audit.TimingsMs["storage"] = time.Since(stepStarted).Milliseconds()
+		if errors.Is(err, repository.ErrObjectChanged) {
+			return domain.RecognitionSuccess{}, false, &uploadError{err, http.StatusConflict, "File changed while it was processed"}
+		}
+		if err != nil {
+			return domain.RecognitionSuccess{}, false, &uploadError{err, http.StatusInternalServerError, failureMessage}
+		}
+		dep.ObjectIndex.Set(contentKey, fileName)
+
+		logger.Info().Str("file_name", fileName).Msg("File uploaded successfully")
+		metrics.RecordUpload(ctx, metadata.ContentType, storedSize)
+		span.AddEvent("File uploaded successfully", trace.WithAttributes(attribute.String("filename", fileName)))
+	}
+	audit.ObjectKey = fileName
+
+	transcript := domain.Transcript{
+		ObjectKey:        fileName,
+		OriginalFilename: originalFilename,
+		Caller:           audit.Caller,
+		SHA256:           digest,
+		MIMEType:         mimeType,
+		Size:             size,
+	}
+	transcript.AudioTrack = audioTrack
+	// The audio Whisper transcribes, read locally to split long recordings into chunks.
+	var audio io.ReadSeeker = openedFile
+	if normalize {
+		var normalized []byte
+		transcript.NormalizedObjectKey, normalized = dep.storeNormalized(ctx, audit, openedFile, fileName, digest)
+		if normalized != nil {
+			audio = domain.NewMemoryFile(normalized)
+		}
+	}
+
+	recognitionResult, err := dep.transcribe(ctx, audit, transcript, audio, options)
+	if err != nil {
+		return domain.RecognitionSuccess{}, false, &uploadError{err, http.StatusInternalServerError, "Failed to process file"}
+	}
+	dep.ResultCache.Set(cacheKey, recognitionResult)
+
+	if hasDuration {
+		metrics.RecordAudioSeconds(ctx, audioSeconds)
+	}
+	return dep.responseResult(ctx, recognitionResult, options), false, nil
+}
+
+// extractAudio copies the audio track of a video into a standalone file, see
+// domain.ExtractAudioTrack, and records the track chosen.
+func extractAudio(ctx context.Context, audit *domain.AuditRecord, video multipart.File, mimeType string) (*domain.ExtractedAudio, *uploadError) {
+	stepStarted := time.Now()
+	extracted, err := domain.ExtractAudioTrack(video, mimeType)
+	audit.TimingsMs["extraction"] = time.Since(stepStarted).Milliseconds()
+	switch {
+	case errors.Is(err, domain.ErrNoAudioTrack):
+		return nil, &uploadError{err, http.StatusUnprocessableEntity, "Video has no audio track"}
+	case errors.Is(err, domain.ErrUnsupportedVideo):
+		return nil, &uploadError{err, http.StatusBadRequest, "Unsupported video: " + strings.TrimPrefix(err.Error(), domain.ErrUnsupportedVideo.Error()+": ")}
+	case err != nil:
+		return nil, &uploadError{err, http.StatusInternalServerError, "Failed to extract audio track"}
+	}
+
+	track := extracted.Track
+	telemetry.LoggerFromContext(ctx).Info().
+		Int("track.id", track.ID).
+		Str("track.codec", track.Codec).
+		Int("track.audio_tracks", track.AudioTracks).
+		Msg("Audio track extracted from video")
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Int("track.id", track.ID),
+		attribute.String("track.codec", track.Codec),
+		attribute.Int("track.audio_tracks", track.AudioTracks),
+	)
+	return extracted, nil
+}
+
+// mediaRejectionMessage returns the client message for a file failing validation.
+func mediaRejectionMessage(err error) string {
+	var rejection *domain.MediaRejection
+	if errors.As(err, &rejection) {
+		return rejection.Message
+	}
+	return "Invalid file signature"
+}
+
+// uploadError is a failed processing step with the status code and message for the client.
+type uploadError struct {
+	err     error
+	code    int
+	message string
+}
+
+// respondWithTranscription writes a transcription, flagging whether it came from the cache.
+func respondWithTranscription(c *gin.Context, span trace.Span, result domain.RecognitionSuccess, cacheHit bool) {
+	if cacheHit {
+		c.Header(CacheHeader, "HIT")
+		c.JSON(http.StatusOK, result)
+		span.SetStatus(codes.Ok, "Transcription served from cache")
 		return
 	}
+	c.Header(CacheHeader, "MISS")
+	c.JSON(http.StatusOK, result)
+	span.SetStatus(codes.Ok, "File transcribed successfully")
+}
+
+// transcribe sends a stored audio object to Whisper, then persists and indexes the
+// transcript described by the provenance fields of transcript. When the content of the
+// object is available locally as audio, long recordings are transcribed in chunks; audio
+// may be nil. When options.DeleteAudio is set, or deletion is configured globally, the
+// audio object is purged once transcribed.
+func (dep *UploadHandlerDependencies) transcribe(ctx context.Context, audit *domain.AuditRecord, transcript domain.Transcript, audio io.ReadSeeker, options transcribeOptions) (domain.RecognitionSuccess, error) {
+	whisperKey := transcript.ObjectKey
+	if transcript.NormalizedObjectKey != "" {
+		whisperKey = transcript.NormalizedObjectKey
+	}
+	stepStarted := time.Now()
+	recognitionResult, err := dep.recognize(ctx, whisperKey, audio, options.Transcription)
+	audit.TimingsMs["transcription"] = time.Since(stepStarted).Milliseconds()
+	if err != nil {
+		return recognitionResult, err
+	}
+	if steps := dep.postProcessSteps(options); len(steps) > 0 {
+		stepStarted = time.Now()
+		recognitionResult = domain.PostProcess(recognitionResult, steps, textLanguage(recognitionResult, options), dep.PostProcessConfig)
+		audit.TimingsMs["post_processing"] = time.Since(stepStarted).Milliseconds()
+	}
+	if dep.diarizeEnabled(options) {
+		recognitionResult = dep.diarize(ctx, audit, whisperKey, recognitionResult, options.NumSpeakers)
+	}
+	if dep.translateEnabled(options) {
+		recognitionResult = dep.translate(ctx, audit, recognitionResult, options)
+	}
+	if dep.analyzeEnabled(options) {
+		recognitionResult = dep.analyze(ctx, audit, recognitionResult, options)
+	}
+	// Every transcription is stored under an ID of its own: deduplicated uploads share
+	// their audio object but never overwrite each other's transcript.
+	if recognitionResult.TranscriptID, err = domain.GenerateUIDWithContext(ctx); err != nil {
+		return recognitionResult, err
+	}
+	audit.DetectedLanguage = recognitionResult.DetectedLang
+	audit.Outcome = domain.AuditOutcomeSuccess
+
+	transcript.ID = recognitionResult.TranscriptID
+	transcript.Tenant = domain.TenantFromContext(ctx).ID
+	transcript.CreatedAt = time.Now().UTC()
+	recognitionResult.AudioTrack = transcript.AudioTrack
+	transcript.RecognitionSuccess = recognitionResult
+	// Personal data a request asked to redact is never persisted or indexed.
+	if dep.redactEnabled(options) {
+		transcript.RecognitionSuccess = dep.Redactor.RedactResult(recognitionResult)
+	}
+	dep.saveTranscript(ctx, transcript)
+	dep.indexTranscript(ctx, transcript)
+
+	if dep.DeleteAudioAfterTranscription || options.DeleteAudio {
+		// The transcription is already complete, a failed purge is only logged.
+		if err := repository.PurgeAudio(ctx, dep.MinioRepo, dep.AuditSink, transcript.ObjectKey, repository.PurgeReasonAfterTranscription); err != nil {
+			telemetry.LoggerFromContext(ctx).Error().Err(err).Str("file_name", transcript.ObjectKey).Msg("Failed to delete audio after transcription")
+		} else if transcript.SHA256 != "" {
+			dep.ObjectIndex.Delete(dep.contentKey(ctx, transcript.SHA256))
+		}
+		if transcript.NormalizedObjectKey != "" {
+			if err := repository.PurgeAudio(ctx, dep.MinioRepo, dep.AuditSink, transcript.NormalizedObjectKey, repository.PurgeReasonAfterTranscription); err != nil {
+				telemetry.LoggerFromContext(ctx).Error().Err(err).Str("file_name", transcript.NormalizedObjectKey).Msg("Failed to delete normalized audio after transcription")
+			}
+		}
+	}
+	return recognitionResult, nil
+}
+
+// parseTranscribeOptions reads the per-request switches and options through get, such
+// as a form or query lookup.
+func parseTranscribeOptions(get func(key string) string) (transcribeOptions, error) {
+	options := transcribeOptions{
+		NoCache:     parseBoolForm(get("no_cache")),
+		DeleteAudio: parseBoolForm(get("delete_audio")),
+	}
+	if value := get("normalize"); value != "" {
+		normalize := parseBoolForm(value)
+		options.Normalize = &normalize
+	}
+	if value := get("diarize"); value != "" {
+		diarize := parseBoolForm(value)
+		options.Diarize = &diarize
+	}
+	if value := get("redact"); value != "" {
+		redact := parseBoolForm(value)
+		options.Redact = &redact
+	}
+	if value := get("analyze"); value != "" {
+		analyze := parseBoolForm(value)
+		options.Analyze = &analyze
+	}
+	if value := get("postprocess"); value != "" {
+		steps, err := domain.ParsePostProcessSteps(strings.Split(value, ","))
+		if err != nil {
+			return options, err
+		}
+		options.PostProcess = steps
+	}
+	var err error
+	if options.TargetLanguage, err = domain.NormalizeTargetLanguage(get("target_language")); err != nil {
+		return options, err
+	}
+	if value := get("num_speakers"); value != "" {
+		numSpeakers, err := strconv.Atoi(value)
+		if err != nil {
+			return options, fmt.Errorf("num_speakers must be an integer")
+		}
+		options.NumSpeakers = numSpeakers
+	}
+	if err := validateNumSpeakers(options.NumSpeakers); err != nil {
+		return options, err
+	}
+	if options.Transcription, err = domain.ParseTranscriptionOptions(get); err != nil {
+		return options, err
+	}
+	options.Confidence, err = domain.ParseConfidenceFilter(get)
+	return options, err
+}
+
+// normalizeEnabled reports whether audio should be normalized, honouring a per-request
+// override of the configured default.
+func (dep *UploadHandlerDependencies) normalizeEnabled(options transcribeOptions) bool {
+	if options.Normalize != nil {
+		return *options.Normalize
+	}
+	return dep.AudioNormalization
+}
 
-	log.Info().Str("span_id", spanID).Str("file_name", fileName).Msg("File uploaded successfully")
-	span.AddEvent("File uploaded successfully", trace.WithAttributes(attribute.String("filename", fileName)))
+// redactEnabled reports whether personal data should be redacted, honouring a
+// per-request override of the configured default.
+func (dep *UploadHandlerDependencies) redactEnabled(options transcribeOptions) bool {
+	if options.Redact != nil {
+		return *options.Redact
+	}
+	return dep.Redaction
+}
+
+// responseResult applies the per-request post-processing to a transcription before it
+// is returned: the confidence filter, then the redaction of personal data. Cached
+// results are kept unprocessed.
+func (dep *UploadHandlerDependencies) responseResult(ctx context.Context, result domain.RecognitionSuccess, options transcribeOptions) domain.RecognitionSuccess {
+	result = domain.ApplyConfidenceFilter(result, options.Confidence)
+	if dep.redactEnabled(options) {
+		result = dep.Redactor.RedactResult(result)
+		telemetry.MetricsFromContext(ctx).RecordRedactions(ctx, result.Redaction.Categories)
+	}
+	return result
+}
+
+// postProcessSteps returns the post-processing steps of a request, or the configured
+// ones when it does not select any.
+func (dep *UploadHandlerDependencies) postProcessSteps(options transcribeOptions) []string {
+	if options.PostProcess != nil {
+		return options.PostProcess
+	}
+	return dep.PostProcessSteps
+}
+
+// textLanguage is the language of the transcribed text: English for Whisper
+// translations, otherwise the requested or detected language.
+func textLanguage(result domain.RecognitionSuccess, options transcribeOptions) string {
+	if options.Transcription.Task == domain.TaskTranslate {
+		return "en"
+	}
+	if options.Transcription.Language != "" {
+		return options.Transcription.Language
+	}
+	return result.DetectedLang
+}
 
-	recognitionResult, err := dep.WhisperRepo.SendToWhisper(c, fileName)
+// diarizeEnabled reports whether transcripts should be labelled with speakers, honouring
+// a per-request override of the configured default.
+func (dep *UploadHandlerDependencies) diarizeEnabled(options transcribeOptions) bool {
+	if dep.Diarizer == nil {
+		return false
+	}
+	if options.Diarize != nil {
+		return *options.Diarize
+	}
+	return dep.Diarization
+}
+
+// translateEnabled reports whether a request asks for a translation the configured
+// translator can provide.
+func (dep *UploadHandlerDependencies) translateEnabled(options transcribeOptions) bool {
+	return dep.Translator != nil && options.TargetLanguage != ""
+}
+
+// translate attaches the translation into the target language of a request to a
+// transcription, segment by segment. Text already in the target language is not sent
+// to the translator. Translation is best effort: on failure the transcription is
+// returned untranslated.
+func (dep *UploadHandlerDependencies) translate(ctx context.Context, audit *domain.AuditRecord, result domain.RecognitionSuccess, options transcribeOptions) domain.RecognitionSuccess {
+	source := textLanguage(result, options)
+	texts := domain.TranslationTexts(result)
+	if source != options.TargetLanguage {
+		if source == "" {
+			source = "auto"
+		}
+		stepStarted := time.Now()
+		translated, err := dep.Translator.Translate(ctx, texts, source, options.TargetLanguage)
+		audit.TimingsMs["translation"] = time.Since(stepStarted).Milliseconds()
+		if err != nil {
+			telemetry.LoggerFromContext(ctx).Warn().Err(err).Str("translation.target", options.TargetLanguage).Msg("Failed to translate transcript, returning it untranslated")
+			return result
+		}
+		texts = translated
+	}
+	return domain.ApplyTranslation(result, options.TargetLanguage, texts)
+}
+
+// analyzeEnabled reports whether transcripts should be summarized, honouring a
+// per-request override of the configured default.
+func (dep *UploadHandlerDependencies) analyzeEnabled(options transcribeOptions) bool {
+	if options.Analyze != nil {
+		return *options.Analyze
+	}
+	return dep.Analysis
+}
+
+// analyze attaches the summary, keywords and keyphrases of a transcription. The
+// summary is extractive unless a summarizer is configured; when the summarizer fails
+// the extractive summary is kept.
+func (dep *UploadHandlerDependencies) analyze(ctx context.Context, audit *domain.AuditRecord, result domain.RecognitionSuccess, options transcribeOptions) domain.RecognitionSuccess {
+	stepStarted := time.Now()
+	language := textLanguage(result, options)
+	analysis := domain.Analyze(result, language, dep.AnalysisConfig)
+	if dep.Summarizer != nil && analysis.Summary != "" {
+		summary, err := dep.Summarizer.Summarize(ctx, result.RecognizedText, language, dep.AnalysisConfig.SummarySentences)
+		if err != nil {
+			telemetry.LoggerFromContext(ctx).Warn().Err(err).Msg("Failed to summarize transcript, returning the extractive summary")
+		} else {
+			analysis.Summary, analysis.SummaryMethod = summary, domain.SummaryMethodLLM
+		}
+	}
+	audit.TimingsMs["analysis"] = time.Since(stepStarted).Milliseconds()
+	result.Analysis = &analysis
+	return result
+}
+
+// validateNumSpeakers checks the expected number of speakers, zero when unknown.
+func validateNumSpeakers(numSpeakers int) error {
+	if numSpeakers < 0 || numSpeakers > maxNumSpeakers {
+		return fmt.Errorf("num_speakers must be between 0 (detect automatically) and %d", maxNumSpeakers)
+	}
+	return nil
+}
+
+// diarize labels the segments of a transcription with the speakers of the object.
+// Diarization is best effort: on failure the transcription is returned unlabelled.
+func (dep *UploadHandlerDependencies) diarize(ctx context.Context, audit *domain.AuditRecord, objectKey string, result domain.RecognitionSuccess, numSpeakers int) domain.RecognitionSuccess {
+	stepStarted := time.Now()
+	turns, err := dep.Diarizer.Diarize(ctx, objectKey, numSpeakers)
+	audit.TimingsMs["diarization"] = time.Since(stepStarted).Milliseconds()
 	if err != nil {
-		ports.RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to process file")
+		telemetry.LoggerFromContext(ctx).Warn().Err(err).Str("file_name", objectKey).Msg("Failed to diarize audio, returning the transcript without speakers")
+		return result
+	}
+	return domain.AssignSpeakers(result, turns)
+}
+
+// storeNormalized stores a normalized derivative of a WAV file next to the original
+// object and returns its key and content. Normalization is best effort: on failure the
+// original is transcribed and an empty key is returned.
+func (dep *UploadHandlerDependencies) storeNormalized(ctx context.Context, audit *domain.AuditRecord, openedFile multipart.File, objectKey string, digest string) (string, []byte) {
+	logger := telemetry.LoggerFromContext(ctx)
+	ctx, span := telemetry.StartSpan(ctx, "NormalizeAudio", attribute.String("filename", objectKey))
+	defer span.End()
+
+	stepStarted := time.Now()
+	normalized, err := domain.NormalizeWAV(openedFile)
+	audit.TimingsMs["normalization"] = time.Since(stepStarted).Milliseconds()
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("file_name", objectKey).Msg("Skipping audio normalization")
+		return "", nil
+	}
+
+	// A reused object may already have its derivative stored.
+	normalizedKey := domain.NormalizedObjectKey(objectKey)
+	if exists, err := dep.MinioRepo.ObjectExists(ctx, normalizedKey); err == nil && exists {
+		return normalizedKey, normalized
+	}
+	normalizedSeconds, _ := domain.AudioDurationSeconds(domain.NewMemoryFile(normalized))
+	metadata := domain.ObjectMetadata{
+		OriginalFilename: audit.OriginalFilename,
+		Uploader:         audit.Caller,
+		SHA256:           digest,
+		ContentType:      "audio/wav",
+		DurationSeconds:  normalizedSeconds,
+	}
+	if err := dep.MinioRepo.UploadToMinioWithContext(ctx, normalizedKey, domain.NewMemoryFile(normalized), int64(len(normalized)), metadata); err != nil {
+		span.RecordError(err)
+		logger.Warn().Err(err).Str("file_name", normalizedKey).Msg("Failed to store normalized audio, transcribing the original")
+		return "", nil
+	}
+	logger.Info().Str("file_name", normalizedKey).Float64("audio.duration_seconds", normalizedSeconds).Msg("Normalized audio stored")
+	return normalizedKey, normalized
+}
+
+// parseBoolForm reads an optional boolean switch, treating malformed values as false.
+func parseBoolForm(value string) bool {
+	enabled, _ := strconv.ParseBool(value)
+	return enabled
+}
+
+// saveTranscript persists the transcript when a store is configured. A failure is logged
+// but does not fail the request, the caller still receives the transcription.
+func (dep *UploadHandlerDependencies) saveTranscript(ctx context.Context, transcript domain.Transcript) {
+	if dep.TranscriptStore == nil {
 		return
 	}
+	if err := dep.TranscriptStore.Save(ctx, transcript); err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("transcript.id", transcript.ID).Msg("Failed to persist transcript")
+	}
+}
 
-	c.JSON(http.StatusOK, recognitionResult)
-	span.SetStatus(codes.Ok, "File transcribed successfully")
+// findStoredObject returns the key of an object previously stored by the tenant with the
+// same content, if the object index knows one and it is still present in the bucket.
+func (dep *UploadHandlerDependencies) findStoredObject(ctx context.Context, contentKey string) (string, bool) {
+	objectName, ok := dep.ObjectIndex.Get(contentKey)
+	if !ok {
+		return "", false
+	}
+	exists, err := dep.MinioRepo.ObjectExists(ctx, objectName)
+	if err != nil || !exists {
+		dep.ObjectIndex.Delete(contentKey)
+		return "", false
+	}
+	telemetry.LoggerFromContext(ctx).Info().Str("file_name", objectName).Msg("Reusing stored object with identical content")
+	return objectName, true
+}
+
+// contentKey scopes a content hash to the tenant in ctx for the object index and the
+// result cache, so that tenants never reuse each other's objects or results.
+func (dep *UploadHandlerDependencies) contentKey(ctx context.Context, digest string) string {
+	return domain.TenantContentKey(domain.TenantFromContext(ctx).ID, digest)
+}
+
+// objectKeyPrefix returns the key prefix of a new object of the caller: the prefix of the
+// tenant in ctx followed by the configured template.
+func (dep *UploadHandlerDependencies) objectKeyPrefix(ctx context.Context, callerID string) string {
+	return domain.TenantFromContext(ctx).Prefix + domain.ObjectKeyPrefix(dep.ObjectKeyPrefix, callerID, time.Now())
+}
+
+// reserveQuota counts an upload of size bytes against the daily quota of the tenant in ctx.
+func (dep *UploadHandlerDependencies) reserveQuota(ctx context.Context, size int64) *uploadError {
+	if err := dep.Quotas.Reserve(ctx, domain.TenantFromContext(ctx), size); err != nil {
+		return &uploadError{err, http.StatusTooManyRequests, "Tenant quota exceeded"}
+	}
+	return nil
 }
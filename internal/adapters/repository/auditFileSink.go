@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"sync"
+)
+
+// AuditFileSink appends audit records as JSON lines to a local file.
+type AuditFileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewAuditFileSink(path string) (*AuditFileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("audit file path is empty")
+	}
+	// Create the file up front so that permission problems surface at startup.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return &AuditFileSink{path: path}, nil
+}
+
+func (sink *AuditFileSink) Append(ctx context.Context, record domain.AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	file, err := os.OpenFile(sink.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("audit.path", sink.path).Msg("Failed to open audit log")
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("audit.path", sink.path).Msg("Failed to append audit record")
+		return err
+	}
+	return file.Sync()
+}
+
+func (sink *AuditFileSink) Query(ctx context.Context, query domain.AuditQuery) ([]domain.AuditRecord, error) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	file, err := os.Open(sink.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := make([]domain.AuditRecord, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record domain.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			telemetry.LoggerFromContext(ctx).Warn().Err(err).Msg("Skipping malformed audit line")
+			continue
+		}
+		if query.Matches(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return limitAuditRecords(records, query.Limit), nil
+}
+
+// limitAuditRecords orders records newest first and keeps the first limit of them.
+func limitAuditRecords(records []domain.AuditRecord, limit int) []domain.AuditRecord {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.After(records[j].Timestamp)
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records
+}
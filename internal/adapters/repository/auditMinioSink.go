@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"path"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxAuditQueryRange bounds the time range a query of the MinIO audit sink scans, since
+// every scanned record is read with its own request.
+const MaxAuditQueryRange = 31 * 24 * time.Hour
+
+// AuditMinioSink stores each audit record as its own object under a date-partitioned
+// prefix, so records are never rewritten once stored.
+type AuditMinioSink struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewAuditMinioSink(client *minio.Client, bucket string, prefix string) (*AuditMinioSink, error) {
+	if client == nil {
+		return nil, fmt.Errorf("minio client is nil")
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("audit bucket name is empty")
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &AuditMinioSink{client: client, bucket: bucket, prefix: prefix}, nil
+}
+
+func (sink *AuditMinioSink) Append(ctx context.Context, record domain.AuditRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	ts := record.Timestamp.UTC()
+	key := fmt.Sprintf("%s%s/%020d-%s.json", sink.prefix, ts.Format("2006/01/02"), ts.UnixNano(), record.ID)
+
+	_, err = sink.client.PutObject(ctx, sink.bucket, key, bytes.NewReader(body), int64(len(body)), minio.PutObjectOptions{
+		ContentType: "application/json",
+	})
+	if err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("audit.key", key).Msg("Failed to store audit record")
+	}
+	return err
+}
+
+// Query scans the days of the query newest first, reading only the records whose key
+// timestamp is in range, and stops once the limit is reached. A query without a lower
+// bound, or with a longer range, covers MaxAuditQueryRange up to its upper bound or now.
+func (sink *AuditMinioSink) Query(ctx context.Context, query domain.AuditQuery) ([]domain.AuditRecord, error) {
+	to := query.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := to.Add(-MaxAuditQueryRange)
+	if query.From.After(from) {
+		from = query.From
+	}
+
+	records := make([]domain.AuditRecord, 0)
+	for _, prefix := range sink.dayPrefixes(from, to) {
+		var keys []string
+		for object := range sink.client.ListObjects(ctx, sink.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+			if object.Err != nil {
+				return nil, object.Err
+			}
+			keys = append(keys, object.Key)
+		}
+		// Keys start with the zero-padded timestamp of their record, so they list oldest first.
+		for i := len(keys) - 1; i >= 0; i-- {
+			if ts, ok := auditKeyTime(keys[i]); ok && (ts.Before(from) || ts.After(to)) {
+				continue
+			}
+			record, err := sink.readRecord(ctx, keys[i])
+			if err != nil {
+				telemetry.LoggerFromContext(ctx).Warn().Err(err).Str("audit.key", keys[i]).Msg("Skipping unreadable audit record")
+				continue
+			}
+			if query.Matches(record) && !record.Timestamp.Before(from) {
+				records = append(records, record)
+				if query.Limit > 0 && len(records) == query.Limit {
+					return limitAuditRecords(records, query.Limit), nil
+				}
+			}
+		}
+	}
+	return limitAuditRecords(records, query.Limit), nil
+}
+
+// dayPrefixes returns the prefixes of the days from from to to, newest first.
+func (sink *AuditMinioSink) dayPrefixes(from time.Time, to time.Time) []string {
+	var prefixes []string
+	first := from.UTC().Truncate(24 * time.Hour)
+	for day := to.UTC().Truncate(24 * time.Hour); !day.Before(first); day = day.Add(-24 * time.Hour) {
+		prefixes = append(prefixes, sink.prefix+day.Format("2006/01/02")+"/")
+	}
+	return prefixes
+}
+
+// auditKeyTime returns the timestamp encoded in the key of an audit record.
+func auditKeyTime(key string) (time.Time, bool) {
+	name, _, ok := strings.Cut(path.Base(key), "-")
+	if !ok {
+		return time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(name, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos), true
+}
+
+func (sink *AuditMinioSink) readRecord(ctx context.Context, key string) (domain.AuditRecord, error) {
+	var record domain.AuditRecord
+	object, err := sink.client.GetObject(ctx, sink.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return record, err
+	}
+	defer object.Close()
+	err = json.NewDecoder(object).Decode(&record)
+	return record, err
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"sr-api/internal/config"
+	"sr-api/internal/core/ports"
+)
+
+// NewAuditSink creates the audit sink selected in the configuration.
+// It returns nil when auditing is disabled.
+func NewAuditSink(cfg *config.AppConfig, client *minio.Client) (ports.AuditSink, error) {
+	switch cfg.AuditSink {
+	case "":
+		return nil, nil
+	case "file":
+		return NewAuditFileSink(cfg.AuditFilePath)
+	case "minio":
+		return NewAuditMinioSink(client, cfg.AuditBucket, cfg.AuditPrefix)
+	default:
+		return nil, fmt.Errorf("unknown audit sink: %s", cfg.AuditSink)
+	}
+}
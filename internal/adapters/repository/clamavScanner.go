@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"io"
+	"net"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+	"time"
+)
+
+// clamAVChunkSize is the size of the chunks streamed to clamd, well below its default
+// StreamMaxLength.
+const clamAVChunkSize = 64 << 10
+
+// ClamAVScanner scans content with a clamd daemon using the INSTREAM command.
+type ClamAVScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner returns a scanner for the clamd daemon at address, either host:port
+// or unix: followed by a socket path. timeout bounds a whole scan.
+func NewClamAVScanner(address string, timeout time.Duration) *ClamAVScanner {
+	if socket, ok := strings.CutPrefix(address, "unix:"); ok {
+		return &ClamAVScanner{network: "unix", address: socket, timeout: timeout}
+	}
+	return &ClamAVScanner{network: "tcp", address: address, timeout: timeout}
+}
+
+func (scanner *ClamAVScanner) Scan(ctx context.Context, content io.Reader) (domain.ScanResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ClamAVScan", attribute.String("clamav.address", scanner.address))
+	defer span.End()
+
+	if scanner.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, scanner.timeout)
+		defer cancel()
+	}
+	result, err := scanner.scan(ctx, content)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "ClamAV scan failed")
+		return result, err
+	}
+	span.SetAttributes(attribute.Bool("scan.infected", result.Infected))
+	span.SetStatus(codes.Ok, "Content scanned")
+	return result, nil
+}
+
+func (scanner *ClamAVScanner) scan(ctx context.Context, content io.Reader) (domain.ScanResult, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, scanner.network, scanner.address)
+	if err != nil {
+		return domain.ScanResult{}, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	// clamd closes the connection early when the stream exceeds its size limit, its
+	// reply then explains why the write failed.
+	writeErr := streamToClamd(conn, content)
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		if ctx.Err() != nil {
+			return domain.ScanResult{}, ctx.Err()
+		}
+		if writeErr != nil {
+			return domain.ScanResult{}, fmt.Errorf("failed to stream to clamd: %w", writeErr)
+		}
+		return domain.ScanResult{}, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamdReply(reply)
+}
+
+func streamToClamd(conn net.Conn, content io.Reader) error {
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+	chunk := make([]byte, 4+clamAVChunkSize)
+	for {
+		n, err := content.Read(chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk[:4], uint32(n))
+			if _, writeErr := conn.Write(chunk[:4+n]); writeErr != nil {
+				return writeErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := conn.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// parseClamdReply interprets "stream: OK", "stream: <signature> FOUND" and
+// "<message> ERROR" replies.
+func parseClamdReply(reply string) (domain.ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	status := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case status == "OK":
+		return domain.ScanResult{}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return domain.ScanResult{Infected: true, Signature: strings.TrimSuffix(status, " FOUND")}, nil
+	default:
+		return domain.ScanResult{}, fmt.Errorf("clamd: %s", reply)
+	}
+}
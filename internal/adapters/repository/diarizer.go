@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"fmt"
+	"sr-api/internal/config"
+	"sr-api/internal/core/ports"
+)
+
+// NewDiarizer returns the configured speaker diarizer, or nil when diarization is disabled.
+func NewDiarizer(cfg *config.AppConfig) (ports.Diarizer, error) {
+	switch cfg.Diarizer {
+	case "":
+		return nil, nil
+	case "http":
+		return NewHTTPDiarizer(cfg.DiarizationEndpoint, cfg.MinioBucket, cfg.DiarizationTimeout)
+	default:
+		return nil, fmt.Errorf("unknown diarizer: %s", cfg.Diarizer)
+	}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"net/url"
+	"sort"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"time"
+)
+
+// HTTPDiarizer asks a pyannote-style diarization service for the speaker turns of an
+// object, which the service reads from the bucket itself like Whisper does: the bucket
+// of the tenant in ctx, else the configured one.
+type HTTPDiarizer struct {
+	endpoint string
+	bucket   string
+	client   *http.Client
+}
+
+// NewHTTPDiarizer returns a diarizer posting to endpoint. timeout bounds a whole request.
+func NewHTTPDiarizer(endpoint string, bucket string, timeout time.Duration) (*HTTPDiarizer, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid diarization endpoint: %w", err)
+	}
+	return &HTTPDiarizer{
+		endpoint: endpoint,
+		bucket:   bucket,
+		client: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+			Timeout:   timeout,
+		},
+	}, nil
+}
+
+func (diarizer *HTTPDiarizer) Diarize(ctx context.Context, objectKey string, numSpeakers int) ([]domain.SpeakerTurn, error) {
+	ctx, span := telemetry.StartSpan(ctx, "Diarize", attribute.String("file.name", objectKey))
+	defer span.End()
+
+	turns, err := diarizer.diarize(ctx, objectKey, numSpeakers)
+	if err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("file_name", objectKey).Msg("Diarization failed")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Diarization failed")
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("diarization.turns", len(turns)))
+	span.SetStatus(codes.Ok, "Audio diarized")
+	return turns, nil
+}
+
+func (diarizer *HTTPDiarizer) diarize(ctx context.Context, objectKey string, numSpeakers int) ([]domain.SpeakerTurn, error) {
+	bucket := diarizer.bucket
+	if tenant := domain.TenantFromContext(ctx); tenant.Bucket != "" {
+		bucket = tenant.Bucket
+	}
+	body, err := json.Marshal(handlerStructure.DiarizeData{BucketName: bucket, FileName: objectKey, NumSpeakers: numSpeakers})
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, diarizer.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := diarizer.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("diarization service error: %d", response.StatusCode)
+	}
+
+	var result handlerStructure.DiarizeResult
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode diarization response: %w", err)
+	}
+	turns := result.Segments[:0]
+	for _, turn := range result.Segments {
+		if turn.End > turn.Start && turn.Speaker != "" {
+			turns = append(turns, turn)
+		}
+	}
+	sort.SliceStable(turns, func(i, j int) bool { return turns[i].Start < turns[j].Start })
+	return turns, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"net/url"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/core/ports/telemetry"
+	"time"
+)
+
+// maxTranslateBatch bounds the texts sent in one request, services limit the request size.
+const maxTranslateBatch = 100
+
+// LibreTranslator translates texts with a LibreTranslate-compatible service.
+type LibreTranslator struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewLibreTranslator returns a translator posting to the /translate endpoint of the
+// service. apiKey may be empty, timeout bounds a whole request.
+func NewLibreTranslator(endpoint string, apiKey string, timeout time.Duration) (*LibreTranslator, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid translation endpoint: %w", err)
+	}
+	return &LibreTranslator{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		client: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+			Timeout:   timeout,
+		},
+	}, nil
+}
+
+func (translator *LibreTranslator) Translate(ctx context.Context, texts []string, source string, target string) ([]string, error) {
+	ctx, span := telemetry.StartSpan(ctx, "Translate",
+		attribute.String("translation.source", source),
+		attribute.String("translation.target", target),
+		attribute.Int("translation.texts", len(texts)),
+	)
+	defer span.End()
+
+	translated := make([]string, 0, len(texts))
+	for start := 0; start < len(texts); start += maxTranslateBatch {
+		batch, err := translator.translate(ctx, texts[start:min(start+maxTranslateBatch, len(texts))], source, target)
+		if err != nil {
+			telemetry.LoggerFromContext(ctx).Error().Err(err).Str("translation.target", target).Msg("Translation failed")
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Translation failed")
+			return nil, err
+		}
+		translated = append(translated, batch...)
+	}
+	span.SetStatus(codes.Ok, "Texts translated")
+	return translated, nil
+}
+
+func (translator *LibreTranslator) translate(ctx context.Context, texts []string, source string, target string) ([]string, error) {
+	body, err := json.Marshal(handlerStructure.TranslateData{Q: texts, Source: source, Target: target, Format: "text", APIKey: translator.apiKey})
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, translator.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := translator.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var result handlerStructure.TranslateResult
+	decodeErr := json.NewDecoder(response.Body).Decode(&result)
+	if response.StatusCode != http.StatusOK {
+		if result.Error != "" {
+			return nil, fmt.Errorf("translation service error: %d: %s", response.StatusCode, result.Error)
+		}
+		return nil, fmt.Errorf("translation service error: %d", response.StatusCode)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode translation response: %w", decodeErr)
+	}
+	if len(result.TranslatedText) != len(texts) {
+		return nil, fmt.Errorf("translation service returned %d texts for %d", len(result.TranslatedText), len(texts))
+	}
+	return result.TranslatedText, nil
+}
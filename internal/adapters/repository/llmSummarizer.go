@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"net/url"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+	"time"
+)
+
+// summaryPrompt instructs the model, it is filled with the maximum number of sentences
+// and the language of the transcript.
+const summaryPrompt = "You summarize meeting and call transcripts. Write a summary of the transcript sent by the user " +
+	"in at most %d sentences, in the language of the transcript%s. Reply with the summary only."
+
+// LLMSummarizer summarizes transcripts with an OpenAI-compatible chat completion service.
+type LLMSummarizer struct {
+	endpoint string
+	apiKey   string
+	model    string
+	client   *http.Client
+}
+
+// NewLLMSummarizer returns a summarizer posting to the chat completion endpoint of the
+// service. apiKey may be empty, timeout bounds a whole request.
+func NewLLMSummarizer(endpoint string, apiKey string, model string, timeout time.Duration) (*LLMSummarizer, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid summarizer endpoint: %w", err)
+	}
+	if model == "" {
+		return nil, fmt.Errorf("summarizer model is not set")
+	}
+	return &LLMSummarizer{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		model:    model,
+		client: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+			Timeout:   timeout,
+		},
+	}, nil
+}
+
+func (summarizer *LLMSummarizer) Summarize(ctx context.Context, text string, language string, maxSentences int) (string, error) {
+	ctx, span := telemetry.StartSpan(ctx, "Summarize", attribute.String("summarizer.model", summarizer.model))
+	defer span.End()
+
+	summary, err := summarizer.summarize(ctx, text, language, maxSentences)
+	if err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("summarizer.model", summarizer.model).Msg("Summarization failed")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Summarization failed")
+		return "", err
+	}
+	span.SetStatus(codes.Ok, "Transcript summarized")
+	return summary, nil
+}
+
+func (summarizer *LLMSummarizer) summarize(ctx context.Context, text string, language string, maxSentences int) (string, error) {
+	languageHint := ""
+	if language != "" {
+		languageHint = " (" + language + ")"
+	}
+	body, err := json.Marshal(handlerStructure.ChatCompletionData{
+		Model: summarizer.model,
+		Messages: []handlerStructure.ChatMessage{
+			{Role: "system", Content: fmt.Sprintf(summaryPrompt, maxSentences, languageHint)},
+			{Role: "user", Content: text},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, summarizer.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if summarizer.apiKey != "" {
+		request.Header.Set("Authorization", "Bearer "+summarizer.apiKey)
+	}
+
+	response, err := summarizer.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("summarizer service error: %d", response.StatusCode)
+	}
+
+	var result handlerStructure.ChatCompletionResult
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode summarizer response: %w", err)
+	}
+	if len(result.Choices) == 0 || strings.TrimSpace(result.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("summarizer returned no summary")
+	}
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
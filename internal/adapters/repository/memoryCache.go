@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-process LRU cache whose entries also expire after a fixed TTL.
+// A nil *MemoryCache is a valid, always-empty cache.
+type MemoryCache[V any] struct {
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a cache holding at most maxEntries values for ttl each.
+// It returns nil when maxEntries is not positive, which disables caching.
+func NewMemoryCache[V any](maxEntries int, ttl time.Duration) *MemoryCache[V] {
+	if maxEntries <= 0 {
+		return nil
+	}
+	return &MemoryCache[V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		now:        time.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored under key if it is present and has not expired.
+func (cache *MemoryCache[V]) Get(key string) (V, bool) {
+	var zero V
+	if cache == nil {
+		return zero, false
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := element.Value.(*memoryCacheEntry[V])
+	if cache.ttl > 0 && cache.now().After(entry.expiresAt) {
+		cache.removeElement(element)
+		return zero, false
+	}
+	cache.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores value under key, evicting the least recently used entry when full.
+func (cache *MemoryCache[V]) Set(key string, value V) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	expiresAt := cache.now().Add(cache.ttl)
+	if element, ok := cache.entries[key]; ok {
+		entry := element.Value.(*memoryCacheEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	cache.entries[key] = cache.order.PushFront(&memoryCacheEntry[V]{key: key, value: value, expiresAt: expiresAt})
+	for cache.order.Len() > cache.maxEntries {
+		cache.removeElement(cache.order.Back())
+	}
+}
+
+// Delete removes key from the cache.
+func (cache *MemoryCache[V]) Delete(key string) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if element, ok := cache.entries[key]; ok {
+		cache.removeElement(element)
+	}
+}
+
+// DeleteMatching removes every entry whose key satisfies match.
+func (cache *MemoryCache[V]) DeleteMatching(match func(key string) bool) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for key, element := range cache.entries {
+		if match(key) {
+			cache.removeElement(element)
+		}
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (cache *MemoryCache[V]) Len() int {
+	if cache == nil {
+		return 0
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.order.Len()
+}
+
+func (cache *MemoryCache[V]) removeElement(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.entries, element.Value.(*memoryCacheEntry[V]).key)
+}
@@ -1,19 +1,37 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"github.com/gin-gonic/gin"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
-	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
 	"sr-api/internal/core/ports/telemetry"
+	"time"
 )
 
+// Uploaded audio is tagged so that bucket lifecycle rules can target it without
+// touching transcripts or audit records stored in the same bucket. Quarantined files
+// carry their own kind so that retention never removes them.
+const (
+	ObjectKindTag        = "sr-api-kind"
+	ObjectKindAudio      = "audio"
+	ObjectKindQuarantine = "quarantine"
+)
+
+// ErrObjectNotFound is returned when the requested object is not in the bucket.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ErrObjectChanged is returned when an object was overwritten since it was read.
+var ErrObjectChanged = errors.New("object changed")
+
 type MinioRepository struct {
 	Client *minio.Client
 	config *config.AppConfig
@@ -37,8 +55,27 @@ func NewMinioRepository(cfg *config.AppConfig) (*MinioRepository, error) {
 	}, nil
 }
 
-// UploadToMinioWithContext uploads a file to MinIO storage
-func (repo *MinioRepository) UploadToMinioWithContext(c *gin.Context, filename string, file multipart.File, size int64) error {
+// Bucket returns the name of the bucket uploads of the tenant in ctx are stored in.
+func (repo *MinioRepository) Bucket(ctx context.Context) string {
+	if tenant := domain.TenantFromContext(ctx); tenant.Bucket != "" {
+		return tenant.Bucket
+	}
+	return repo.config.MinioBucket
+}
+
+// UploadToMinioWithContext uploads a file to MinIO storage with the content type and
+// user metadata describing the original upload.
+func (repo *MinioRepository) UploadToMinioWithContext(ctx context.Context, filename string, file multipart.File, size int64, metadata domain.ObjectMetadata) error {
+	return repo.putObject(ctx, filename, file, size, metadata, ObjectKindAudio)
+}
+
+// QuarantineUpload stores a file flagged by the content scanner, tagged so that it is
+// kept for review instead of being transcribed or expired.
+func (repo *MinioRepository) QuarantineUpload(ctx context.Context, filename string, file multipart.File, size int64, metadata domain.ObjectMetadata) error {
+	return repo.putObject(ctx, filename, file, size, metadata, ObjectKindQuarantine)
+}
+
+func (repo *MinioRepository) putObject(ctx context.Context, filename string, file multipart.File, size int64, metadata domain.ObjectMetadata, kind string) error {
 	if repo.config == nil {
 		return fmt.Errorf("repository configuration is nil")
 	}
@@ -51,19 +88,28 @@ func (repo *MinioRepository) UploadToMinioWithContext(c *gin.Context, filename s
 		return fmt.Errorf("file is nil")
 	}
 
-	ctx, span := telemetry.StartSpanFromGinContext(c, "UploadToMinio")
-	bucketName := repo.config.MinioBucket
+	ctx, span := telemetry.StartSpan(ctx, "UploadToMinio")
+	defer span.End()
+	bucketName := repo.Bucket(ctx)
 
 	if bucketName == "" {
-		return fmt.Errorf("bucket name is empty")
+		err := fmt.Errorf("bucket name is empty")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Bucket name is empty")
+		return err
 	}
 
-	defer span.End()
-	spanID := telemetry.GetSpanId(span)
+	logger := telemetry.LoggerFromContext(ctx)
 
-	info, err := repo.Client.PutObject(ctx, bucketName, filename, file, size, minio.PutObjectOptions{})
+	start := time.Now()
+	info, err := repo.Client.PutObject(ctx, bucketName, filename, file, size, minio.PutObjectOptions{
+		ContentType:  metadata.ContentType,
+		UserMetadata: domain.ObjectUserMetadata(metadata),
+		UserTags:     map[string]string{ObjectKindTag: kind},
+	})
+	telemetry.MetricsFromContext(ctx).RecordMinioPut(ctx, time.Since(start), err == nil)
 	if err != nil {
-		log.Error().Str("span_id", spanID).Err(err).Str("minio.bucket", bucketName).Msg("Failed to upload file")
+		logger.Error().Err(err).Str("minio.bucket", bucketName).Msg("Failed to upload file")
 		span.RecordError(err)
 		return err
 	}
@@ -74,8 +120,7 @@ func (repo *MinioRepository) UploadToMinioWithContext(c *gin.Context, filename s
 		attribute.Int64("minio.file.size", info.Size),
 	)
 	span.SetStatus(codes.Ok, "File uploaded successfully")
-	log.Info().
-		Str("span_id", spanID).
+	logger.Info().
 		Str("file.name", filename).
 		Int64("minio.file.size", info.Size).
 		Str("minio.bucket", bucketName).
@@ -83,3 +128,149 @@ func (repo *MinioRepository) UploadToMinioWithContext(c *gin.Context, filename s
 
 	return nil
 }
+
+// ObjectExists reports whether the object is present in the bucket of the tenant in ctx.
+func (repo *MinioRepository) ObjectExists(ctx context.Context, objectName string) (bool, error) {
+	ctx, span := telemetry.StartSpan(ctx, "StatMinioObject", attribute.String("file.name", objectName))
+	defer span.End()
+
+	_, err := repo.Client.StatObject(ctx, repo.Bucket(ctx), objectName, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to stat object")
+		return false, err
+	}
+	return true, nil
+}
+
+// StatObjectMetadata returns the metadata stored with the object, or ErrObjectNotFound.
+func (repo *MinioRepository) StatObjectMetadata(ctx context.Context, objectName string) (domain.ObjectMetadata, error) {
+	return repo.StatObjectMetadataFrom(ctx, repo.Bucket(ctx), objectName)
+}
+
+// StatObjectMetadataFrom returns the metadata of an object in any bucket the client can
+// read, or ErrObjectNotFound.
+func (repo *MinioRepository) StatObjectMetadataFrom(ctx context.Context, bucket string, objectName string) (domain.ObjectMetadata, error) {
+	ctx, span := telemetry.StartSpan(ctx, "StatMinioObject", attribute.String("minio.bucket", bucket), attribute.String("file.name", objectName))
+	defer span.End()
+
+	info, err := repo.Client.StatObject(ctx, bucket, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		if code := minio.ToErrorResponse(err).Code; code == "NoSuchKey" || code == "NoSuchBucket" {
+			return domain.ObjectMetadata{}, ErrObjectNotFound
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to stat object")
+		return domain.ObjectMetadata{}, err
+	}
+	return domain.ObjectMetadataFromUser(info.ContentType, info.Size, info.UserMetadata), nil
+}
+
+// PresignPutObject returns a URL the client can PUT the object to until it expires, and
+// the headers the request must carry. They are signed, so the object is always tagged as
+// audio and purged by the retention policy even if the upload is never completed.
+func (repo *MinioRepository) PresignPutObject(ctx context.Context, objectName string, expiry time.Duration) (*url.URL, http.Header, error) {
+	ctx, span := telemetry.StartSpan(ctx, "PresignMinioPut", attribute.String("file.name", objectName))
+	defer span.End()
+
+	headers := http.Header{}
+	headers.Set("X-Amz-Tagging", url.Values{ObjectKindTag: {ObjectKindAudio}}.Encode())
+	presignedURL, err := repo.Client.PresignHeader(ctx, http.MethodPut, repo.Bucket(ctx), objectName, expiry, nil, headers)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to presign upload")
+		return nil, nil, err
+	}
+	span.SetStatus(codes.Ok, "Upload presigned")
+	return presignedURL, headers, nil
+}
+
+// OpenObject opens an object in the given bucket for reading. The returned object is
+// read lazily with ranged requests, so seeking to and reading its headers does not
+// download the whole content. Every request reads the version first read, or fails
+// once the object is overwritten.
+func (repo *MinioRepository) OpenObject(ctx context.Context, bucket string, objectName string) (*minio.Object, error) {
+	ctx, span := telemetry.StartSpan(ctx, "OpenMinioObject", attribute.String("minio.bucket", bucket), attribute.String("file.name", objectName))
+	defer span.End()
+
+	object, err := repo.Client.GetObject(ctx, bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to open object")
+		return nil, err
+	}
+	return object, nil
+}
+
+// CopyObjectFrom copies an object from the source bucket into the tenant's bucket with
+// a server-side copy, storing it as tagged audio with the given content type and metadata.
+// When sourceETag is not empty, ErrObjectChanged is returned if the source no longer
+// has that ETag.
+func (repo *MinioRepository) CopyObjectFrom(ctx context.Context, sourceBucket string, sourceName string, sourceETag string, objectName string, metadata domain.ObjectMetadata) error {
+	return repo.copyObject(ctx, sourceBucket, sourceName, sourceETag, objectName, metadata, ObjectKindAudio)
+}
+
+// QuarantineObject moves an object of the tenant's bucket flagged by the content
+// scanner to quarantineName, tagged so that it is kept for review.
+func (repo *MinioRepository) QuarantineObject(ctx context.Context, objectName string, quarantineName string, metadata domain.ObjectMetadata) error {
+	if err := repo.copyObject(ctx, repo.Bucket(ctx), objectName, "", quarantineName, metadata, ObjectKindQuarantine); err != nil {
+		return err
+	}
+	return repo.RemoveObjectWithContext(ctx, objectName)
+}
+
+func (repo *MinioRepository) copyObject(ctx context.Context, sourceBucket string, sourceName string, sourceETag string, objectName string, metadata domain.ObjectMetadata, kind string) error {
+	ctx, span := telemetry.StartSpan(ctx, "CopyMinioObject",
+		attribute.String("minio.source_bucket", sourceBucket),
+		attribute.String("minio.source_object", sourceName),
+		attribute.String("file.name", objectName),
+	)
+	defer span.End()
+
+	userMetadata := domain.ObjectUserMetadata(metadata)
+	if metadata.ContentType != "" {
+		userMetadata["Content-Type"] = metadata.ContentType
+	}
+	_, err := repo.Client.CopyObject(ctx, minio.CopyDestOptions{
+		Bucket:          repo.Bucket(ctx),
+		Object:          objectName,
+		UserMetadata:    userMetadata,
+		ReplaceMetadata: true,
+		UserTags:        map[string]string{ObjectKindTag: kind},
+		ReplaceTags:     true,
+	}, minio.CopySrcOptions{
+		Bucket:    sourceBucket,
+		Object:    sourceName,
+		MatchETag: sourceETag,
+	})
+	if minio.ToErrorResponse(err).Code == "PreconditionFailed" {
+		span.SetStatus(codes.Error, "Source object changed")
+		return ErrObjectChanged
+	}
+	if err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("file.name", objectName).Msg("Failed to copy object")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to copy object")
+		return err
+	}
+	span.SetStatus(codes.Ok, "Object copied")
+	return nil
+}
+
+// RemoveObjectWithContext deletes the object from the bucket of the tenant in ctx.
+func (repo *MinioRepository) RemoveObjectWithContext(ctx context.Context, objectName string) error {
+	ctx, span := telemetry.StartSpan(ctx, "RemoveMinioObject", attribute.String("file.name", objectName))
+	defer span.End()
+
+	if err := repo.Client.RemoveObject(ctx, repo.Bucket(ctx), objectName, minio.RemoveObjectOptions{}); err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("file.name", objectName).Msg("Failed to remove object")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to remove object")
+		return err
+	}
+	span.SetStatus(codes.Ok, "Object removed")
+	return nil
+}
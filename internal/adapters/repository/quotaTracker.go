@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when an upload would exceed the daily quota of its tenant.
+var ErrQuotaExceeded = errors.New("tenant quota exceeded")
+
+// QuotaTracker counts the uploads and bytes each tenant stores per UTC day against the
+// tenant's quota. Usage is kept in memory, so every instance of the service enforces
+// the quota on its own. A nil *QuotaTracker enforces no quota.
+type QuotaTracker struct {
+	now func() time.Time
+
+	mu    sync.Mutex
+	day   string
+	usage map[string]quotaUsage
+}
+
+type quotaUsage struct {
+	uploads int
+	bytes   int64
+}
+
+func NewQuotaTracker() *QuotaTracker {
+	return &QuotaTracker{now: time.Now, usage: make(map[string]quotaUsage)}
+}
+
+// Reserve counts an upload of size bytes for the tenant. When the upload would exceed
+// the tenant's quota for the day, usage is left unchanged and an error wrapping
+// ErrQuotaExceeded is returned.
+func (tracker *QuotaTracker) Reserve(ctx context.Context, tenant domain.Tenant, size int64) error {
+	if tracker == nil {
+		return nil
+	}
+	quota := tenant.Quota
+	if quota.UploadsPerDay <= 0 && quota.BytesPerDay <= 0 {
+		return nil
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if day := tracker.now().UTC().Format(time.DateOnly); day != tracker.day {
+		tracker.day = day
+		clear(tracker.usage)
+	}
+	usage := tracker.usage[tenant.ID]
+	limit := ""
+	switch {
+	case quota.UploadsPerDay > 0 && usage.uploads+1 > quota.UploadsPerDay:
+		limit = "uploads"
+	case quota.BytesPerDay > 0 && usage.bytes+size > quota.BytesPerDay:
+		limit = "bytes"
+	}
+	if limit != "" {
+		telemetry.MetricsFromContext(ctx).RecordQuotaRejection(ctx, limit)
+		return fmt.Errorf("%w: %s per day of tenant %s", ErrQuotaExceeded, limit, tenant.Name())
+	}
+	usage.uploads++
+	usage.bytes += size
+	tracker.usage[tenant.ID] = usage
+	return nil
+}
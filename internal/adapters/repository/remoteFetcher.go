@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"net/url"
+	"path"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"time"
+)
+
+const maxRemoteRedirects = 5
+
+var (
+	// ErrSourceNotAllowed is returned for URLs outside the allowlist.
+	ErrSourceNotAllowed = errors.New("source is not allowed")
+	// ErrSourceTooLarge is returned when the remote file exceeds the size limit.
+	ErrSourceTooLarge = errors.New("source exceeds the size limit")
+)
+
+// RemoteFile is a downloaded file buffered on disk. Closing it removes the file.
+type RemoteFile struct {
+	*domain.TempFile
+	// Filename is the last path segment of the URL.
+	Filename string
+}
+
+// RemoteFetcher downloads audio over HTTP(S) from allowlisted hosts only, following
+// redirects only to allowlisted hosts.
+type RemoteFetcher struct {
+	client    *http.Client
+	allowlist []string
+	maxBytes  int64
+}
+
+func NewRemoteFetcher(allowlist []string, maxBytes int64, timeout time.Duration) (*RemoteFetcher, error) {
+	if len(allowlist) == 0 {
+		return nil, fmt.Errorf("URL allowlist is empty")
+	}
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("maximum download size must be positive")
+	}
+	fetcher := &RemoteFetcher{allowlist: allowlist, maxBytes: maxBytes}
+	fetcher.client = &http.Client{
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+		Timeout:   timeout,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= maxRemoteRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRemoteRedirects)
+			}
+			if !fetcher.Allowed(request.URL) {
+				return ErrSourceNotAllowed
+			}
+			return nil
+		},
+	}
+	return fetcher, nil
+}
+
+// Allowed reports whether the URL uses HTTP(S) and its host is allowlisted.
+func (fetcher *RemoteFetcher) Allowed(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	return domain.HostAllowed(u.Hostname(), fetcher.allowlist)
+}
+
+// Fetch downloads the URL into a temporary file. The caller must close the returned file.
+func (fetcher *RemoteFetcher) Fetch(ctx context.Context, rawURL string) (*RemoteFile, error) {
+	ctx, span := telemetry.StartSpan(ctx, "FetchRemoteFile")
+	defer span.End()
+
+	u, err := url.Parse(rawURL)
+	if err != nil || !fetcher.Allowed(u) {
+		span.SetStatus(codes.Error, "URL not allowed")
+		return nil, ErrSourceNotAllowed
+	}
+	span.SetAttributes(attribute.String("http.host", u.Host))
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := fetcher.client.Do(request)
+	if err != nil {
+		if errors.Is(err, ErrSourceNotAllowed) {
+			return nil, ErrSourceNotAllowed
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to fetch remote file")
+		return nil, err
+	}
+	defer response.Body.Close()
+	span.SetAttributes(attribute.Int("http.status_code", response.StatusCode))
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("remote server returned %d", response.StatusCode)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Remote server returned an error")
+		return nil, err
+	}
+	if response.ContentLength > fetcher.maxBytes {
+		return nil, ErrSourceTooLarge
+	}
+
+	buffered, err := domain.BufferToTempFile(response.Body, fetcher.maxBytes)
+	if errors.Is(err, domain.ErrFileTooLarge) {
+		return nil, ErrSourceTooLarge
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to download remote file")
+		return nil, err
+	}
+	file := &RemoteFile{TempFile: buffered}
+	if name := path.Base(response.Request.URL.Path); name != "/" && name != "." {
+		file.Filename = name
+	}
+	span.SetAttributes(attribute.Int64("file.size", file.Size))
+	span.SetStatus(codes.Ok, "Remote file downloaded")
+	return file, nil
+}
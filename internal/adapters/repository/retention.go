@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"math"
+	"slices"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+	"time"
+)
+
+// Purge reasons reported to metrics and the audit log.
+const (
+	PurgeReasonExpired             = "expired"
+	PurgeReasonAfterTranscription  = "after_transcription"
+	retentionLifecycleRuleID       = "sr-api-audio-retention"
+	minimumLifecycleExpirationDays = 1
+)
+
+// ApplyLifecycleRetention installs a lifecycle rule expiring tagged audio objects after
+// the retention period, rounded up to whole days, on each bucket. The rule is added to
+// the lifecycle configuration already set on a bucket, replacing only a previous version
+// of itself. Objects expired by the bucket are not counted in metrics nor recorded in
+// the audit log.
+func ApplyLifecycleRetention(ctx context.Context, repo *MinioRepository, buckets []string, period time.Duration) error {
+	ctx, span := telemetry.StartSpan(ctx, "ApplyLifecycleRetention")
+	defer span.End()
+
+	days := int(math.Ceil(period.Hours() / 24))
+	if days < minimumLifecycleExpirationDays {
+		days = minimumLifecycleExpirationDays
+	}
+
+	rule := lifecycle.Rule{
+		ID:     retentionLifecycleRuleID,
+		Status: "Enabled",
+		RuleFilter: lifecycle.Filter{
+			Tag: lifecycle.Tag{Key: ObjectKindTag, Value: ObjectKindAudio},
+		},
+		Expiration: lifecycle.Expiration{Days: lifecycle.ExpirationDays(days)},
+	}
+
+	for _, bucket := range buckets {
+		config, err := repo.Client.GetBucketLifecycle(ctx, bucket)
+		if minio.ToErrorResponse(err).Code == "NoSuchLifecycleConfiguration" {
+			config, err = lifecycle.NewConfiguration(), nil
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to get bucket lifecycle")
+			return err
+		}
+		config.Rules = slices.DeleteFunc(config.Rules, func(existing lifecycle.Rule) bool {
+			return existing.ID == retentionLifecycleRuleID
+		})
+		config.Rules = append(config.Rules, rule)
+		if err := repo.Client.SetBucketLifecycle(ctx, bucket, config); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to set bucket lifecycle")
+			return err
+		}
+		telemetry.LoggerFromContext(ctx).Info().Int("retention.days", days).Str("minio.bucket", bucket).Msg("Applied audio retention lifecycle rule")
+	}
+	span.SetStatus(codes.Ok, "Bucket lifecycle set")
+	return nil
+}
+
+// RetentionSweeper periodically deletes uploaded audio older than the retention period.
+type RetentionSweeper struct {
+	repo     *MinioRepository
+	tenants  *domain.TenantRegistry
+	audit    ports.AuditSink
+	period   time.Duration
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewRetentionSweeper creates a sweeper for the buckets of the tenants, or for the
+// configured bucket when tenants is nil. Only objects tagged as audio are purged, never
+// the transcripts, audit records or quarantined files stored alongside. audit may be nil.
+func NewRetentionSweeper(repo *MinioRepository, tenants *domain.TenantRegistry, audit ports.AuditSink, period time.Duration, interval time.Duration) (*RetentionSweeper, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("retention period must be positive")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("retention sweep interval must be positive")
+	}
+	return &RetentionSweeper{
+		repo:     repo,
+		tenants:  tenants,
+		audit:    audit,
+		period:   period,
+		interval: interval,
+		now:      time.Now,
+	}, nil
+}
+
+// Run sweeps immediately and then on every interval until ctx is cancelled.
+func (sweeper *RetentionSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(sweeper.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := sweeper.Sweep(ctx); err != nil {
+			telemetry.LoggerFromContext(ctx).Error().Err(err).Msg("Retention sweep failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes every expired audio object once and returns how many were purged.
+func (sweeper *RetentionSweeper) Sweep(ctx context.Context) (int, error) {
+	ctx = domain.ContextWithCaller(ctx, domain.Caller{ID: domain.SystemCaller})
+	ctx, span := telemetry.StartSpan(ctx, "RetentionSweep")
+	defer span.End()
+
+	cutoff := sweeper.now().Add(-sweeper.period)
+	buckets := []string{sweeper.repo.config.MinioBucket}
+	if sweeper.tenants != nil {
+		buckets = sweeper.tenants.Buckets()
+	}
+	purged := 0
+	for _, bucket := range buckets {
+		for object := range sweeper.repo.Client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Recursive: true}) {
+			if object.Err != nil {
+				span.RecordError(object.Err)
+				span.SetStatus(codes.Error, "Failed to list objects")
+				return purged, object.Err
+			}
+			if object.LastModified.After(cutoff) || strings.HasSuffix(object.Key, "/") {
+				continue
+			}
+			// Objects are purged on behalf of their tenant, from the tenant's bucket.
+			objectCtx := ctx
+			if sweeper.tenants != nil {
+				tenant, owned := sweeper.tenants.TenantOfObject(bucket, object.Key)
+				if !owned {
+					continue
+				}
+				objectCtx = domain.ContextWithTenant(ctx, tenant)
+			}
+			if !sweeper.isAudio(ctx, bucket, object.Key) {
+				continue
+			}
+			if err := PurgeAudio(objectCtx, sweeper.repo, sweeper.audit, object.Key, PurgeReasonExpired); err == nil {
+				purged++
+			}
+		}
+	}
+
+	span.SetAttributes(attribute.Int("retention.purged", purged))
+	span.SetStatus(codes.Ok, "Retention sweep completed")
+	if purged > 0 {
+		telemetry.LoggerFromContext(ctx).Info().Int("retention.purged", purged).Msg("Purged expired audio objects")
+	}
+	return purged, nil
+}
+
+// isAudio reports whether the object carries the audio kind tag set on upload. Objects
+// whose tags cannot be read are kept until the next sweep.
+func (sweeper *RetentionSweeper) isAudio(ctx context.Context, bucket string, key string) bool {
+	objectTags, err := sweeper.repo.Client.GetObjectTagging(ctx, bucket, key, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code != "NoSuchKey" {
+			telemetry.LoggerFromContext(ctx).Warn().Err(err).Str("minio.bucket", bucket).Str("file.name", key).Msg("Failed to read object tags")
+		}
+		return false
+	}
+	return objectTags.ToMap()[ObjectKindTag] == ObjectKindAudio
+}
+
+// PurgeAudio removes an audio object, counting the purge and recording it in the audit
+// log when a sink is configured. audit may be nil.
+func PurgeAudio(ctx context.Context, repo *MinioRepository, audit ports.AuditSink, objectKey string, reason string) error {
+	record := domain.NewAuditRecord(ctx, domain.AuditActionPurge)
+	record.ObjectKey = objectKey
+	record.Reason = reason
+	record.Outcome = domain.AuditOutcomeSuccess
+
+	err := repo.RemoveObjectWithContext(ctx, objectKey)
+	telemetry.MetricsFromContext(ctx).RecordPurge(ctx, reason, err == nil)
+	if err != nil {
+		record.Outcome = domain.AuditOutcomeFailed
+		record.Error = "Failed to remove object"
+	}
+
+	if audit != nil {
+		if auditErr := audit.Append(context.WithoutCancel(ctx), record); auditErr != nil {
+			telemetry.LoggerFromContext(ctx).Error().Err(auditErr).Str("audit.id", record.ID).Msg("Failed to write audit record")
+		}
+	}
+	return err
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"fmt"
+	"sr-api/internal/config"
+	"sr-api/internal/core/ports"
+)
+
+// NewScanner returns the configured content scanner, or nil when scanning is disabled.
+func NewScanner(cfg *config.AppConfig) (ports.Scanner, error) {
+	switch cfg.Scanner {
+	case "":
+		return nil, nil
+	case "clamav":
+		return NewClamAVScanner(cfg.ClamAVAddress, cfg.ScanTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown scanner: %s", cfg.Scanner)
+	}
+}
@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// BM25 ranking parameters.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	maxHighlights      = 3
+	highlightRadius    = 80
+	highlightOpenMark  = "<mark>"
+	highlightCloseMark = "</mark>"
+)
+
+// searchDocument is the persisted form of an indexed transcript; postings are rebuilt on load.
+type searchDocument struct {
+	ID        string    `json:"id"`
+	Language  string    `json:"language"`
+	Tenant    string    `json:"tenant,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Text      string    `json:"text"`
+}
+
+// MemorySearchIndex is an in-memory inverted index ranked with BM25. When a path is
+// set, the indexed documents are written to it after every change and reloaded on start.
+type MemorySearchIndex struct {
+	path string
+
+	// rebuildMu serializes rebuilds.
+	rebuildMu sync.Mutex
+
+	mu       sync.RWMutex
+	docs     map[string]searchDocument
+	lengths  map[string]int
+	postings map[string]map[string]int
+	totalLen int
+	// changed holds the IDs indexed or removed during a rebuild, nil otherwise.
+	changed map[string]bool
+}
+
+// NewMemorySearchIndex creates an index persisted to path, loading any documents already
+// stored there. An empty path keeps the index in memory only.
+func NewMemorySearchIndex(path string) (*MemorySearchIndex, error) {
+	index := &MemorySearchIndex{path: path}
+	index.reset()
+	if path == "" {
+		return index, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var docs []searchDocument
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		index.add(doc)
+	}
+	return index, nil
+}
+
+// Len returns the number of indexed transcripts.
+func (index *MemorySearchIndex) Len() int {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+	return len(index.docs)
+}
+
+func (index *MemorySearchIndex) Index(ctx context.Context, transcript domain.Transcript) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	index.markChanged(transcript.ID)
+	index.remove(transcript.ID)
+	index.add(newSearchDocument(transcript))
+	return index.persist(ctx)
+}
+
+func (index *MemorySearchIndex) Remove(ctx context.Context, id string) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	index.markChanged(id)
+	if _, ok := index.docs[id]; !ok {
+		return nil
+	}
+	index.remove(id)
+	return index.persist(ctx)
+}
+
+// Rebuild replaces the whole index with the transcripts returned by list. The index keeps
+// serving while list runs; the IDs indexed or removed meanwhile keep their current
+// document, or absence, instead of the listed one.
+func (index *MemorySearchIndex) Rebuild(ctx context.Context, list func(ctx context.Context) ([]domain.Transcript, error)) error {
+	index.rebuildMu.Lock()
+	defer index.rebuildMu.Unlock()
+
+	index.mu.Lock()
+	index.changed = make(map[string]bool)
+	index.mu.Unlock()
+
+	transcripts, err := list(ctx)
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	changed := index.changed
+	index.changed = nil
+	if err != nil {
+		return err
+	}
+
+	var kept []searchDocument
+	for id := range changed {
+		if doc, ok := index.docs[id]; ok {
+			kept = append(kept, doc)
+		}
+	}
+	index.reset()
+	for _, transcript := range transcripts {
+		if !changed[transcript.ID] {
+			index.add(newSearchDocument(transcript))
+		}
+	}
+	for _, doc := range kept {
+		index.add(doc)
+	}
+	return index.persist(ctx)
+}
+
+func (index *MemorySearchIndex) Search(ctx context.Context, query domain.SearchQuery) (domain.SearchResult, error) {
+	_, span := telemetry.StartSpan(ctx, "SearchTranscripts")
+	defer span.End()
+
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	result := domain.SearchResult{Hits: []domain.SearchHit{}}
+	terms := uniqueTerms(query.Text)
+	if len(terms) == 0 || len(index.docs) == 0 {
+		return result, nil
+	}
+
+	// Every term must occur in a document for it to match.
+	scores := make(map[string]float64)
+	for i, term := range terms {
+		postings := index.postings[term]
+		if len(postings) == 0 {
+			return result, nil
+		}
+		idf := math.Log(1 + (float64(len(index.docs))-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+		avgLen := float64(index.totalLen) / float64(len(index.docs))
+		next := make(map[string]float64)
+		for id, tf := range postings {
+			if i > 0 {
+				if _, ok := scores[id]; !ok {
+					continue
+				}
+			}
+			doc := index.docs[id]
+			if doc.Tenant != query.Tenant || query.Language != "" && doc.Language != query.Language {
+				continue
+			}
+			norm := float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*(1-bm25B+bm25B*float64(index.lengths[id])/avgLen))
+			next[id] = scores[id] + idf*norm
+		}
+		scores = next
+	}
+
+	hits := make([]domain.SearchHit, 0, len(scores))
+	for id, score := range scores {
+		doc := index.docs[id]
+		hits = append(hits, domain.SearchHit{
+			ID:           id,
+			Score:        score,
+			DetectedLang: doc.Language,
+			CreatedAt:    doc.CreatedAt,
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt)
+	})
+
+	result.Total = len(hits)
+	if query.Offset >= len(hits) {
+		return result, nil
+	}
+	end := len(hits)
+	if query.Limit > 0 && query.Offset+query.Limit < end {
+		end = query.Offset + query.Limit
+		result.NextOffset = &end
+	}
+	result.Hits = hits[query.Offset:end]
+	for i := range result.Hits {
+		result.Hits[i].Highlights = highlight(index.docs[result.Hits[i].ID].Text, terms)
+	}
+	return result, nil
+}
+
+func newSearchDocument(transcript domain.Transcript) searchDocument {
+	return searchDocument{
+		ID:        transcript.ID,
+		Language:  transcript.DetectedLang,
+		Tenant:    transcript.Tenant,
+		CreatedAt: transcript.CreatedAt,
+		Text:      transcript.RecognizedText,
+	}
+}
+
+// markChanged records an indexed or removed ID while a rebuild runs. Callers must hold
+// the lock.
+func (index *MemorySearchIndex) markChanged(id string) {
+	if index.changed != nil {
+		index.changed[id] = true
+	}
+}
+
+func (index *MemorySearchIndex) reset() {
+	index.docs = make(map[string]searchDocument)
+	index.lengths = make(map[string]int)
+	index.postings = make(map[string]map[string]int)
+	index.totalLen = 0
+}
+
+func (index *MemorySearchIndex) add(doc searchDocument) {
+	tokens := domain.Tokenize(doc.Text)
+	index.docs[doc.ID] = doc
+	index.lengths[doc.ID] = len(tokens)
+	index.totalLen += len(tokens)
+	for _, token := range tokens {
+		postings, ok := index.postings[token.Text]
+		if !ok {
+			postings = make(map[string]int)
+			index.postings[token.Text] = postings
+		}
+		postings[doc.ID]++
+	}
+}
+
+func (index *MemorySearchIndex) remove(id string) {
+	doc, ok := index.docs[id]
+	if !ok {
+		return
+	}
+	for _, token := range domain.Tokenize(doc.Text) {
+		if postings, ok := index.postings[token.Text]; ok {
+			delete(postings, id)
+			if len(postings) == 0 {
+				delete(index.postings, token.Text)
+			}
+		}
+	}
+	index.totalLen -= index.lengths[id]
+	delete(index.lengths, id)
+	delete(index.docs, id)
+}
+
+// persist atomically writes the documents to the index file. Callers must hold the lock.
+func (index *MemorySearchIndex) persist(ctx context.Context) error {
+	if index.path == "" {
+		return nil
+	}
+	docs := make([]searchDocument, 0, len(index.docs))
+	for _, doc := range index.docs {
+		docs = append(docs, doc)
+	}
+	data, err := json.Marshal(docs)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(index.path), filepath.Base(index.path)+".*.tmp")
+	if err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("search.path", index.path).Msg("Failed to write search index")
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), index.path)
+}
+
+func uniqueTerms(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, token := range domain.Tokenize(text) {
+		if !seen[token.Text] {
+			seen[token.Text] = true
+			terms = append(terms, token.Text)
+		}
+	}
+	return terms
+}
+
+// highlight returns up to maxHighlights non-overlapping excerpts of text around
+// occurrences of the terms, with every occurrence wrapped in <mark> tags.
+func highlight(text string, terms []string) []string {
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+	var matches []domain.Token
+	for _, token := range domain.Tokenize(text) {
+		if wanted[token.Text] {
+			matches = append(matches, token)
+		}
+	}
+
+	highlights := make([]string, 0, maxHighlights)
+	windowEnd := -1
+	for i, match := range matches {
+		if match.Start < windowEnd || len(highlights) == maxHighlights {
+			continue
+		}
+		start := wordBoundary(text, match.Start-highlightRadius, false)
+		end := wordBoundary(text, match.End+highlightRadius, true)
+		windowEnd = end
+
+		var excerpt strings.Builder
+		if start > 0 {
+			excerpt.WriteString("…")
+		}
+		cursor := start
+		for _, inner := range matches[i:] {
+			if inner.End > end {
+				break
+			}
+			excerpt.WriteString(html.EscapeString(text[cursor:inner.Start]))
+			excerpt.WriteString(highlightOpenMark)
+			excerpt.WriteString(html.EscapeString(text[inner.Start:inner.End]))
+			excerpt.WriteString(highlightCloseMark)
+			cursor = inner.End
+		}
+		excerpt.WriteString(html.EscapeString(text[cursor:end]))
+		if end < len(text) {
+			excerpt.WriteString("…")
+		}
+		highlights = append(highlights, strings.TrimSpace(excerpt.String()))
+	}
+	return highlights
+}
+
+// wordBoundary moves pos to the nearest whitespace so that excerpts do not cut words,
+// searching forward or backward and clamping to the text bounds.
+func wordBoundary(text string, pos int, forward bool) int {
+	if pos <= 0 {
+		return 0
+	}
+	if pos >= len(text) {
+		return len(text)
+	}
+	if forward {
+		if i := strings.IndexAny(text[pos:], " \t\n"); i >= 0 {
+			return pos + i
+		}
+		return len(text)
+	}
+	if i := strings.LastIndexAny(text[:pos], " \t\n"); i >= 0 {
+		return i + 1
+	}
+	return 0
+}
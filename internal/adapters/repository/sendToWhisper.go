@@ -2,9 +2,8 @@ package repository
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"net/http"
@@ -12,8 +11,10 @@ import (
 	"path"
 	"sr-api/internal/adapters/handler/handlerStructure"
 	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
 	http2 "sr-api/internal/core/ports"
 	"sr-api/internal/core/ports/telemetry"
+	"time"
 )
 
 type WhisperRepository struct {
@@ -26,70 +27,92 @@ func NewWhisperRepository(cfg *config.AppConfig) *WhisperRepository {
 	}
 }
 
-func (repo *WhisperRepository) SendToWhisper(c *gin.Context, fileName string) (handlerStructure.RecognitionSuccess, error) {
-	ctx, span := telemetry.StartSpanFromGinContext(c, "SendToWhisper")
+// SendToWhisper asks Whisper to transcribe the object with the given options and echoes
+// the options in the result. The object is read from the bucket of the tenant in ctx,
+// by the tenant's Whisper service when it has one.
+func (repo *WhisperRepository) SendToWhisper(ctx context.Context, fileName string, options domain.TranscriptionOptions) (domain.RecognitionSuccess, error) {
+	ctx, span := telemetry.StartSpan(ctx, "SendToWhisper")
 	defer span.End()
-	// Now using config from repo
+	// Now using config from repo, overridden by the tenant of the request
 	whisperEndpoint := repo.config.WhisperEndpoint
 	whisperTranscribe := repo.config.WhisperTranscribe
 	minioBucketName := repo.config.MinioBucket
+	tenant := domain.TenantFromContext(ctx)
+	if tenant.WhisperEndpoint != "" {
+		whisperEndpoint = tenant.WhisperEndpoint
+	}
+	if tenant.Bucket != "" {
+		minioBucketName = tenant.Bucket
+	}
 
-	spanID := telemetry.GetSpanId(span)
+	logger := telemetry.LoggerFromContext(ctx)
 
-	log.Debug().Str("span_id", spanID).Str("bucket", minioBucketName).Str("file", fileName).Msg("Initiating file processing")
+	logger.Debug().Str("bucket", minioBucketName).Str("file", fileName).Msg("Initiating file processing")
 
 	u, err := url.Parse(whisperEndpoint)
 	if err != nil {
-		log.Error().Str("span_id", spanID).Err(err).Msg("Invalid Whisper endpoint URL")
+		logger.Error().Err(err).Msg("Invalid Whisper endpoint URL")
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Invalid Whisper endpoint URL")
-		return handlerStructure.RecognitionSuccess{}, err
+		return domain.RecognitionSuccess{}, err
 	}
 
 	u.Path = path.Join(u.Path, whisperTranscribe)
 	whisperTranscribeURL := u.String()
 
-	log.Debug().Str("span_id", spanID).Str("whisperTranscribeURL", whisperTranscribeURL).Msg("Whisper transcribe URL constructed")
+	logger.Debug().Str("whisperTranscribeURL", whisperTranscribeURL).Msg("Whisper transcribe URL constructed")
 
-	data := handlerStructure.SendData{BucketName: minioBucketName, FileName: fileName}
+	data := handlerStructure.SendData{BucketName: minioBucketName, FileName: fileName, TranscriptionOptions: options}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
-		log.Error().Str("span_id", spanID).Err(err).Msg("Error marshaling request data")
+		logger.Error().Err(err).Msg("Error marshaling request data")
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Error marshaling request data")
-		return handlerStructure.RecognitionSuccess{}, err
+		return domain.RecognitionSuccess{}, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", whisperTranscribeURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		log.Error().Str("span_id", spanID).Err(err).Msg("Failed to create request")
+		logger.Error().Err(err).Msg("Failed to create request")
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to create request")
-		return handlerStructure.RecognitionSuccess{}, err
+		return domain.RecognitionSuccess{}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Connection", "keep-alive")
 
-	log.Debug().Str("span_id", spanID).Msg("Sending request to Whisper service")
+	logger.Debug().Msg("Sending request to Whisper service")
 
-	resp, err := http2.HttpClient(c, req)
+	metrics := telemetry.MetricsFromContext(ctx)
+	start := time.Now()
+	resp, err := http2.HttpClient(ctx, req)
 	if err != nil {
-		log.Error().Str("span_id", spanID).Err(err).Msg("Failed to send request to Whisper service")
+		metrics.RecordWhisper(ctx, time.Since(start), false)
+		logger.Error().Err(err).Msg("Failed to send request to Whisper service")
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to send request to Whisper service")
-		return handlerStructure.RecognitionSuccess{}, err
+		return domain.RecognitionSuccess{}, err
 	}
 	defer resp.Body.Close()
 
-	var recognitionResult handlerStructure.RecognitionSuccess
+	var recognitionResult domain.RecognitionSuccess
 	if err := json.NewDecoder(resp.Body).Decode(&recognitionResult); err != nil {
-		log.Error().Str("span_id", spanID).Err(err).Msg("Failed to decode Whisper service response")
+		metrics.RecordWhisper(ctx, time.Since(start), false)
+		metrics.RecordWhisperError(ctx, "decode")
+		logger.Error().Err(err).Msg("Failed to decode Whisper service response")
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to decode Whisper service response")
-		return handlerStructure.RecognitionSuccess{}, err
+		return domain.RecognitionSuccess{}, err
+	}
+
+	recognitionResult.Options = nil
+	if !options.IsZero() {
+		recognitionResult.Options = &options
 	}
+	metrics.RecordWhisper(ctx, time.Since(start), true)
+	metrics.RecordDetectedLanguage(ctx, recognitionResult.DetectedLang)
 
-	log.Info().Str("span_id", spanID).Str("file", fileName).Msg("File processing completed successfully")
+	logger.Info().Str("file", fileName).Msg("File processing completed successfully")
 	span.SetAttributes(attribute.String("response.status", "success"))
 	span.SetStatus(codes.Ok, "File processed successfully")
 	return recognitionResult, nil
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"fmt"
+	"sr-api/internal/config"
+	"sr-api/internal/core/ports"
+)
+
+// NewSummarizer returns the configured summarizer, or nil when summaries are extractive.
+func NewSummarizer(cfg *config.AppConfig) (ports.Summarizer, error) {
+	switch cfg.Summarizer {
+	case "":
+		return nil, nil
+	case "llm":
+		return NewLLMSummarizer(cfg.SummarizerEndpoint, cfg.SummarizerAPIKey, cfg.SummarizerModel, cfg.SummarizerTimeout)
+	default:
+		return nil, fmt.Errorf("unknown summarizer: %s", cfg.Summarizer)
+	}
+}
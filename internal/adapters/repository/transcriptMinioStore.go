@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"net/url"
+	"path"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+	"time"
+)
+
+// TranscriptSuffix is appended to the transcript ID to form the key of the JSON sidecar
+// holding its transcript.
+const TranscriptSuffix = ".transcript.json"
+
+// transcriptDir holds the sidecars of a tenant under its prefix, so that listing them
+// never walks the audio objects or the storage of other tenants.
+const transcriptDir = "transcripts/"
+
+// User metadata keys stored on sidecars, letting List filter and order transcripts from
+// the bucket listing without reading them.
+const (
+	metadataCreatedAt = "Created-At"
+	metadataLanguage  = "Language"
+	metadataObjectKey = "Object-Key"
+)
+
+// MinioTranscriptStore keeps each transcript as a JSON sidecar in the audio bucket of
+// its tenant. Transcripts of other tenants are never returned, listed or deleted.
+type MinioTranscriptStore struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewMinioTranscriptStore(client *minio.Client, bucket string) (*MinioTranscriptStore, error) {
+	if client == nil {
+		return nil, fmt.Errorf("minio client is nil")
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket name is empty")
+	}
+	return &MinioTranscriptStore{client: client, bucket: bucket}, nil
+}
+
+// TranscriptKey returns the sidecar key for a transcript ID, relative to the prefix of
+// its tenant. Sidecars are kept in one directory of the tenant prefix so that they can
+// be found by ID whatever the audio key prefix is.
+func TranscriptKey(id string) string {
+	return transcriptDir + id + TranscriptSuffix
+}
+
+func (store *MinioTranscriptStore) Save(ctx context.Context, transcript domain.Transcript) error {
+	ctx, span := telemetry.StartSpan(ctx, "SaveTranscript", attribute.String("transcript.id", transcript.ID))
+	defer span.End()
+
+	body, err := json.Marshal(transcript)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	bucket, prefix := store.location(ctx)
+	key := prefix + TranscriptKey(transcript.ID)
+	_, err = store.client.PutObject(ctx, bucket, key, bytes.NewReader(body), int64(len(body)), minio.PutObjectOptions{
+		ContentType: "application/json",
+		UserMetadata: map[string]string{
+			metadataCreatedAt: transcript.CreatedAt.Format(time.RFC3339Nano),
+			metadataLanguage:  transcript.DetectedLang,
+			metadataObjectKey: url.PathEscape(transcript.ObjectKey),
+		},
+	})
+	if err != nil {
+		telemetry.LoggerFromContext(ctx).Error().Err(err).Str("transcript.key", key).Msg("Failed to store transcript")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to store transcript")
+		return err
+	}
+	span.SetStatus(codes.Ok, "Transcript stored")
+	return nil
+}
+
+func (store *MinioTranscriptStore) Get(ctx context.Context, id string) (domain.Transcript, error) {
+	ctx, span := telemetry.StartSpan(ctx, "GetTranscript", attribute.String("transcript.id", id))
+	defer span.End()
+
+	bucket, prefix := store.location(ctx)
+	transcript, err := store.read(ctx, bucket, prefix+TranscriptKey(id))
+	if err != nil {
+		span.RecordError(err)
+	}
+	return transcript, err
+}
+
+// List filters and orders the sidecars of the tenant by the creation time and language
+// stored in their metadata, then reads only the transcripts of the requested page.
+// Sidecars listed without metadata are read to be filtered.
+func (store *MinioTranscriptStore) List(ctx context.Context, query domain.TranscriptQuery) (domain.TranscriptPage, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ListTranscripts")
+	defer span.End()
+
+	bucket, prefix := store.location(ctx)
+	var entries []domain.Transcript
+	keys := make(map[string]string)
+	for object := range store.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix + transcriptDir, Recursive: true, WithMetadata: true}) {
+		if object.Err != nil {
+			span.RecordError(object.Err)
+			return domain.TranscriptPage{}, object.Err
+		}
+		if !strings.HasSuffix(object.Key, TranscriptSuffix) {
+			continue
+		}
+		entry, ok := listedTranscript(object)
+		if !ok {
+			transcript, err := store.read(ctx, bucket, object.Key)
+			if errors.Is(err, ports.ErrTranscriptNotFound) {
+				continue
+			}
+			if err != nil {
+				telemetry.LoggerFromContext(ctx).Warn().Err(err).Str("transcript.key", object.Key).Msg("Skipping unreadable transcript")
+				continue
+			}
+			entry = transcript
+		}
+		keys[entry.ID] = object.Key
+		entries = append(entries, entry)
+	}
+
+	page := domain.PaginateTranscripts(entries, query)
+	items := make([]domain.Transcript, 0, len(page.Items))
+	for _, entry := range page.Items {
+		if entry.ObjectKey != "" {
+			// Read in full because the sidecar had no metadata.
+			items = append(items, entry)
+			continue
+		}
+		transcript, err := store.read(ctx, bucket, keys[entry.ID])
+		if errors.Is(err, ports.ErrTranscriptNotFound) {
+			page.Total--
+			continue
+		}
+		if err != nil {
+			span.RecordError(err)
+			return domain.TranscriptPage{}, err
+		}
+		items = append(items, transcript)
+	}
+	page.Items = items
+	span.SetAttributes(attribute.Int("transcripts.listed", len(entries)), attribute.Int("transcripts.read", len(items)))
+	return page, nil
+}
+
+// listedTranscript returns the ID, creation time and language of a listed sidecar from
+// its metadata, false when the listing carries none.
+func listedTranscript(object minio.ObjectInfo) (domain.Transcript, bool) {
+	metadata := listedMetadata(object)
+	createdAt, err := time.Parse(time.RFC3339Nano, metadata[metadataCreatedAt])
+	if err != nil {
+		return domain.Transcript{}, false
+	}
+	id := strings.TrimSuffix(path.Base(object.Key), TranscriptSuffix)
+	entry := domain.Transcript{ID: id, CreatedAt: createdAt}
+	entry.DetectedLang = metadata[metadataLanguage]
+	return entry, true
+}
+
+// listedMetadata returns the user metadata of a listed object by canonical key.
+func listedMetadata(object minio.ObjectInfo) map[string]string {
+	metadata := make(map[string]string, len(object.UserMetadata))
+	for key, value := range object.UserMetadata {
+		key = http.CanonicalHeaderKey(key)
+		metadata[strings.TrimPrefix(key, "X-Amz-Meta-")] = value
+	}
+	return metadata
+}
+
+// Delete removes the transcript. Its audio objects are removed too unless another
+// transcript of the tenant, of a deduplicated upload, still references them; the second
+// return value reports whether they were.
+func (store *MinioTranscriptStore) Delete(ctx context.Context, id string) (domain.Transcript, bool, error) {
+	ctx, span := telemetry.StartSpan(ctx, "DeleteTranscript", attribute.String("transcript.id", id))
+	defer span.End()
+
+	bucket, prefix := store.location(ctx)
+	key := prefix + TranscriptKey(id)
+	transcript, err := store.read(ctx, bucket, key)
+	if err != nil {
+		span.RecordError(err)
+		return transcript, false, err
+	}
+	shared, err := store.referenced(ctx, bucket, prefix, id, transcript.ObjectKey)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to check audio object references")
+		return transcript, false, err
+	}
+	span.SetAttributes(attribute.Bool("transcript.audio_shared", shared))
+	if !shared {
+		if err := store.client.RemoveObject(ctx, bucket, transcript.ObjectKey, minio.RemoveObjectOptions{}); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to remove audio object")
+			return transcript, false, err
+		}
+		if transcript.NormalizedObjectKey != "" {
+			if err := store.client.RemoveObject(ctx, bucket, transcript.NormalizedObjectKey, minio.RemoveObjectOptions{}); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "Failed to remove normalized audio object")
+				return transcript, true, err
+			}
+		}
+	}
+	if err := store.client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to remove transcript")
+		return transcript, !shared, err
+	}
+	span.SetStatus(codes.Ok, "Transcript deleted")
+	return transcript, !shared, nil
+}
+
+// referenced reports whether a transcript of the tenant other than id references the
+// audio object. Sidecars listed without the object key in their metadata are read.
+func (store *MinioTranscriptStore) referenced(ctx context.Context, bucket string, prefix string, id string, objectKey string) (bool, error) {
+	for object := range store.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix + transcriptDir, Recursive: true, WithMetadata: true}) {
+		if object.Err != nil {
+			return false, object.Err
+		}
+		if !strings.HasSuffix(object.Key, TranscriptSuffix) || strings.TrimSuffix(path.Base(object.Key), TranscriptSuffix) == id {
+			continue
+		}
+		referencedKey, ok := listedMetadata(object)[metadataObjectKey]
+		if ok {
+			referencedKey, _ = url.PathUnescape(referencedKey)
+		} else {
+			transcript, err := store.read(ctx, bucket, object.Key)
+			if errors.Is(err, ports.ErrTranscriptNotFound) {
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+			referencedKey = transcript.ObjectKey
+		}
+		if referencedKey == objectKey {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// location returns the bucket and key prefix of the transcripts of the tenant in ctx.
+func (store *MinioTranscriptStore) location(ctx context.Context) (string, string) {
+	tenant := domain.TenantFromContext(ctx)
+	if tenant.Bucket != "" {
+		return tenant.Bucket, tenant.Prefix
+	}
+	return store.bucket, tenant.Prefix
+}
+
+// read decodes a sidecar, reporting transcripts of another tenant than the one in ctx
+// as not found.
+func (store *MinioTranscriptStore) read(ctx context.Context, bucket string, key string) (domain.Transcript, error) {
+	var transcript domain.Transcript
+	object, err := store.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return transcript, err
+	}
+	defer object.Close()
+	if err := json.NewDecoder(object).Decode(&transcript); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return transcript, ports.ErrTranscriptNotFound
+		}
+		return transcript, err
+	}
+	if transcript.Tenant != domain.TenantFromContext(ctx).ID {
+		return domain.Transcript{}, ports.ErrTranscriptNotFound
+	}
+	return transcript, nil
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"sr-api/internal/config"
+	"sr-api/internal/core/ports"
+)
+
+// NewTranscriptStore creates the transcript store selected in the configuration.
+// It returns nil when transcripts are not persisted.
+func NewTranscriptStore(cfg *config.AppConfig, client *minio.Client) (ports.TranscriptStore, error) {
+	switch cfg.TranscriptStore {
+	case "", "none":
+		return nil, nil
+	case "minio":
+		return NewMinioTranscriptStore(client, cfg.MinioBucket)
+	default:
+		return nil, fmt.Errorf("unknown transcript store: %s", cfg.TranscriptStore)
+	}
+}
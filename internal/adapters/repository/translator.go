@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"fmt"
+	"sr-api/internal/config"
+	"sr-api/internal/core/ports"
+)
+
+// NewTranslator returns the configured translator, or nil when translation is disabled.
+func NewTranslator(cfg *config.AppConfig) (ports.Translator, error) {
+	switch cfg.Translator {
+	case "":
+		return nil, nil
+	case "libretranslate":
+		return NewLibreTranslator(cfg.TranslationEndpoint, cfg.TranslationAPIKey, cfg.TranslationTimeout)
+	default:
+		return nil, fmt.Errorf("unknown translator: %s", cfg.Translator)
+	}
+}
@@ -1,12 +1,126 @@
 package config
 
+import "time"
+
 type AppConfig struct {
-	MinioAccessKey        string
-	MinioSecretKey        string
-	MinioEndpoint         string
-	MinioBucket           string
-	MinioUseSSL           bool
+	MinioAccessKey string
+	MinioSecretKey string
+	MinioEndpoint  string
+	MinioBucket    string
+	MinioUseSSL    bool
+	// ObjectKeyPrefix is prepended to uploaded object keys; {date} and {tenant} expand
+	// to the upload date and the caller ID.
+	ObjectKeyPrefix       string
 	WhisperEndpoint       string
 	WhisperTranscribe     string
 	TelemetryGrpcEndpoint string
+	// PrometheusEnabled exposes a /metrics pull endpoint in addition to the OTLP push exporter.
+	PrometheusEnabled bool
+	LogLevel          string
+	// LogFormat is either "json" or "console".
+	LogFormat string
+	// APIKeys maps an API key to the caller name it identifies.
+	APIKeys map[string]string
+	// AuditSink selects where audit records go: "file", "minio" or empty to disable auditing.
+	AuditSink     string
+	AuditFilePath string
+	AuditBucket   string
+	AuditPrefix   string
+	// AuditAdmins are the API key names allowed to read the audit records of every
+	// caller of their tenant; other callers only read their own.
+	AuditAdmins []string
+	// ResultCacheSize bounds the number of cached transcriptions and known objects,
+	// zero disables deduplication.
+	ResultCacheSize int
+	ResultCacheTTL  time.Duration
+	// TranscriptStore selects where transcripts are persisted: "minio" or "none".
+	TranscriptStore string
+	// SearchIndexEnabled indexes stored transcripts for full-text search.
+	SearchIndexEnabled bool
+	// SearchIndexPath persists the search index; when empty it is rebuilt on startup.
+	SearchIndexPath string
+	// RetentionPeriod is how long uploaded audio is kept, zero keeps it forever.
+	RetentionPeriod time.Duration
+	// RetentionMode is "sweeper" for the built-in background purge or "lifecycle"
+	// to delegate expiry to a MinIO bucket lifecycle rule.
+	RetentionMode                 string
+	RetentionSweepInterval        time.Duration
+	DeleteAudioAfterTranscription bool
+	// MediaAllowedTypes lists the accepted MIME types; empty accepts any audio or video type.
+	MediaAllowedTypes []string
+	// MediaCheckExtension and MediaCheckStructure enable the extension consistency and
+	// container structure checks of uploaded media.
+	MediaCheckExtension bool
+	MediaCheckStructure bool
+	// Scanner selects the content scanner run before storage: "clamav" or empty to disable.
+	Scanner       string
+	ClamAVAddress string
+	ScanTimeout   time.Duration
+	// ScanFailOpen stores and transcribes files when the scanner is unavailable instead
+	// of rejecting them.
+	ScanFailOpen bool
+	// QuarantinePrefix is the key prefix of files flagged by the scanner.
+	QuarantinePrefix string
+	// AudioNormalization converts WAV uploads to 16 kHz mono with normalized loudness and
+	// trimmed silence before transcription, unless a request opts out.
+	AudioNormalization bool
+	// TranscribeChunkDuration splits longer WAV recordings into overlapping chunks that
+	// are transcribed in parallel, zero disables chunking.
+	TranscribeChunkDuration    time.Duration
+	TranscribeChunkOverlap     time.Duration
+	TranscribeChunkConcurrency int
+	// Diarizer selects the speaker diarization service: "http" or empty to disable.
+	Diarizer            string
+	DiarizationEndpoint string
+	DiarizationTimeout  time.Duration
+	// Diarization labels transcript segments with speakers unless a request opts out.
+	Diarization bool
+	// Redaction removes personal data from transcripts unless a request opts out.
+	Redaction bool
+	// RedactionRules is a JSON object mapping custom redaction categories to regular
+	// expressions, applied after the built-in detectors.
+	RedactionRules string
+	// PostProcessSteps are the post-processing steps run after Whisper unless a request
+	// selects others: "dedupe", "vocabulary", "numbers" and "profanity".
+	PostProcessSteps []string
+	// PostProcessVocabulary is a JSON object mapping phrases to their replacement.
+	PostProcessVocabulary string
+	// PostProcessProfanityWords are masked in addition to the built-in lists.
+	PostProcessProfanityWords []string
+	// Translator selects the translation service: "libretranslate" or empty to disable.
+	Translator          string
+	TranslationEndpoint string
+	TranslationAPIKey   string
+	TranslationTimeout  time.Duration
+	// Analysis adds a summary and keywords to transcripts unless a request opts out.
+	Analysis                 bool
+	AnalysisSummarySentences int
+	AnalysisKeywords         int
+	// Summarizer selects the service writing summaries: "llm" for an OpenAI-compatible
+	// chat completion service or empty for extractive summaries.
+	Summarizer         string
+	SummarizerEndpoint string
+	SummarizerAPIKey   string
+	SummarizerModel    string
+	SummarizerTimeout  time.Duration
+	// TranscribeURLAllowlist lists the hosts audio may be fetched from; entries starting
+	// with a dot match subdomains. An empty list disables transcription from URLs.
+	TranscribeURLAllowlist []string
+	TranscribeURLMaxBytes  int64
+	TranscribeURLTimeout   time.Duration
+	// TranscribeSourceBuckets lists the buckets existing objects may be transcribed from.
+	TranscribeSourceBuckets []string
+	// BatchMaxFiles bounds the files of a batch upload, counting files inside archives.
+	BatchMaxFiles int
+	// BatchConcurrency is how many files of a batch are processed in parallel.
+	BatchConcurrency int
+	// BatchMaxArchiveBytes bounds the uncompressed size of an uploaded ZIP archive.
+	BatchMaxArchiveBytes int64
+	// PresignExpiry is how long presigned upload URLs and their pending uploads are valid.
+	PresignExpiry time.Duration
+	// Tenants is a JSON object mapping tenant IDs to their callers, storage, Whisper
+	// endpoint and quotas; callers no tenant claims belong to the default tenant.
+	Tenants string
+	// TenantFromAPIKey gives every API key name no tenant claims a tenant of its own.
+	TenantFromAPIKey bool
 }
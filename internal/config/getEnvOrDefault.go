@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetEnvOrDefault returns the value of an optional environment variable,
+// falling back to the given default when it is not set.
+func GetEnvOrDefault(key string, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// GetBoolEnvOrDefault parses an optional boolean environment variable,
+// falling back to the given default when it is not set or malformed.
+func GetBoolEnvOrDefault(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetIntEnvOrDefault parses an optional integer environment variable,
+// falling back to the given default when it is not set or malformed.
+func GetIntEnvOrDefault(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetDurationEnvOrDefault parses an optional duration environment variable such as "24h",
+// falling back to the given default when it is not set or malformed.
+func GetDurationEnvOrDefault(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
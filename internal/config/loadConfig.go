@@ -1,7 +1,10 @@
 package config
 
 import (
+	"github.com/rs/zerolog/log"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func LoadConfig() *AppConfig {
@@ -10,16 +13,113 @@ func LoadConfig() *AppConfig {
 		minioUseSSL = false
 	}
 
+	minioBucket := GetEnv("MINIO_BUCKET")
+
 	config := &AppConfig{
-		MinioAccessKey:        GetEnv("MINIO_ACCESS_KEY"),
-		MinioSecretKey:        GetEnv("MINIO_SECRET_KEY"),
-		MinioEndpoint:         GetEnv("MINIO_ENDPOINT"),
-		MinioBucket:           GetEnv("MINIO_BUCKET"),
-		MinioUseSSL:           minioUseSSL,
-		WhisperEndpoint:       GetEnv("WHISPER_ENDPOINT"),
-		WhisperTranscribe:     GetEnv("WHISPER_TRANSCRIBE"),
-		TelemetryGrpcEndpoint: GetEnv("TELEMETRY_GRPC_TARGET"),
+		MinioAccessKey:                GetEnv("MINIO_ACCESS_KEY"),
+		MinioSecretKey:                GetEnv("MINIO_SECRET_KEY"),
+		MinioEndpoint:                 GetEnv("MINIO_ENDPOINT"),
+		MinioBucket:                   minioBucket,
+		MinioUseSSL:                   minioUseSSL,
+		ObjectKeyPrefix:               GetEnvOrDefault("OBJECT_KEY_PREFIX", ""),
+		WhisperEndpoint:               GetEnv("WHISPER_ENDPOINT"),
+		WhisperTranscribe:             GetEnv("WHISPER_TRANSCRIBE"),
+		TelemetryGrpcEndpoint:         GetEnv("TELEMETRY_GRPC_TARGET"),
+		PrometheusEnabled:             GetBoolEnvOrDefault("PROMETHEUS_ENABLED", false),
+		LogLevel:                      GetEnvOrDefault("LOG_LEVEL", "info"),
+		LogFormat:                     GetEnvOrDefault("LOG_FORMAT", "json"),
+		APIKeys:                       parseAPIKeys(GetEnvOrDefault("API_KEYS", "")),
+		AuditSink:                     GetEnvOrDefault("AUDIT_SINK", ""),
+		AuditFilePath:                 GetEnvOrDefault("AUDIT_FILE_PATH", "audit.jsonl"),
+		AuditBucket:                   GetEnvOrDefault("AUDIT_BUCKET", minioBucket),
+		AuditPrefix:                   GetEnvOrDefault("AUDIT_PREFIX", "audit/"),
+		AuditAdmins:                   parseList(GetEnvOrDefault("AUDIT_ADMINS", "")),
+		ResultCacheSize:               GetIntEnvOrDefault("RESULT_CACHE_SIZE", 1000),
+		ResultCacheTTL:                GetDurationEnvOrDefault("RESULT_CACHE_TTL", 24*time.Hour),
+		TranscriptStore:               GetEnvOrDefault("TRANSCRIPT_STORE", "minio"),
+		SearchIndexEnabled:            GetBoolEnvOrDefault("SEARCH_INDEX_ENABLED", true),
+		SearchIndexPath:               GetEnvOrDefault("SEARCH_INDEX_PATH", ""),
+		RetentionPeriod:               GetDurationEnvOrDefault("RETENTION_PERIOD", 0),
+		RetentionMode:                 GetEnvOrDefault("RETENTION_MODE", "sweeper"),
+		RetentionSweepInterval:        GetDurationEnvOrDefault("RETENTION_SWEEP_INTERVAL", time.Hour),
+		DeleteAudioAfterTranscription: GetBoolEnvOrDefault("DELETE_AUDIO_AFTER_TRANSCRIPTION", false),
+		MediaAllowedTypes:             parseList(GetEnvOrDefault("MEDIA_ALLOWED_TYPES", "")),
+		MediaCheckExtension:           GetBoolEnvOrDefault("MEDIA_CHECK_EXTENSION", true),
+		MediaCheckStructure:           GetBoolEnvOrDefault("MEDIA_CHECK_STRUCTURE", true),
+		Scanner:                       GetEnvOrDefault("SCANNER", ""),
+		ClamAVAddress:                 GetEnvOrDefault("CLAMAV_ADDRESS", "localhost:3310"),
+		ScanTimeout:                   GetDurationEnvOrDefault("SCAN_TIMEOUT", time.Minute),
+		ScanFailOpen:                  GetBoolEnvOrDefault("SCAN_FAIL_OPEN", false),
+		QuarantinePrefix:              GetEnvOrDefault("QUARANTINE_PREFIX", "quarantine/"),
+		AudioNormalization:            GetBoolEnvOrDefault("AUDIO_NORMALIZATION_ENABLED", false),
+		TranscribeChunkDuration:       GetDurationEnvOrDefault("TRANSCRIBE_CHUNK_DURATION", 10*time.Minute),
+		TranscribeChunkOverlap:        GetDurationEnvOrDefault("TRANSCRIBE_CHUNK_OVERLAP", 5*time.Second),
+		TranscribeChunkConcurrency:    GetIntEnvOrDefault("TRANSCRIBE_CHUNK_CONCURRENCY", 4),
+		Diarizer:                      GetEnvOrDefault("DIARIZER", ""),
+		DiarizationEndpoint:           GetEnvOrDefault("DIARIZATION_ENDPOINT", ""),
+		DiarizationTimeout:            GetDurationEnvOrDefault("DIARIZATION_TIMEOUT", 10*time.Minute),
+		Diarization:                   GetBoolEnvOrDefault("DIARIZATION_ENABLED", false),
+		Redaction:                     GetBoolEnvOrDefault("REDACTION_ENABLED", false),
+		RedactionRules:                GetEnvOrDefault("REDACTION_RULES", ""),
+		PostProcessSteps:              parseList(GetEnvOrDefault("POSTPROCESS_STEPS", "")),
+		PostProcessVocabulary:         GetEnvOrDefault("POSTPROCESS_VOCABULARY", ""),
+		PostProcessProfanityWords:     parseList(GetEnvOrDefault("POSTPROCESS_PROFANITY_WORDS", "")),
+		Translator:                    GetEnvOrDefault("TRANSLATOR", ""),
+		TranslationEndpoint:           GetEnvOrDefault("TRANSLATION_ENDPOINT", ""),
+		TranslationAPIKey:             GetEnvOrDefault("TRANSLATION_API_KEY", ""),
+		TranslationTimeout:            GetDurationEnvOrDefault("TRANSLATION_TIMEOUT", 2*time.Minute),
+		Analysis:                      GetBoolEnvOrDefault("ANALYSIS_ENABLED", false),
+		AnalysisSummarySentences:      GetIntEnvOrDefault("ANALYSIS_SUMMARY_SENTENCES", 5),
+		AnalysisKeywords:              GetIntEnvOrDefault("ANALYSIS_KEYWORDS", 10),
+		Summarizer:                    GetEnvOrDefault("SUMMARIZER", ""),
+		SummarizerEndpoint:            GetEnvOrDefault("SUMMARIZER_ENDPOINT", ""),
+		SummarizerAPIKey:              GetEnvOrDefault("SUMMARIZER_API_KEY", ""),
+		SummarizerModel:               GetEnvOrDefault("SUMMARIZER_MODEL", ""),
+		SummarizerTimeout:             GetDurationEnvOrDefault("SUMMARIZER_TIMEOUT", 2*time.Minute),
+		BatchMaxFiles:                 GetIntEnvOrDefault("BATCH_MAX_FILES", 50),
+		BatchConcurrency:              GetIntEnvOrDefault("BATCH_CONCURRENCY", 4),
+		BatchMaxArchiveBytes:          int64(GetIntEnvOrDefault("BATCH_MAX_ARCHIVE_BYTES", 1<<30)),
+		PresignExpiry:                 GetDurationEnvOrDefault("PRESIGN_EXPIRY", 15*time.Minute),
+		TranscribeURLAllowlist:        parseList(GetEnvOrDefault("TRANSCRIBE_URL_ALLOWLIST", "")),
+		TranscribeURLMaxBytes:         int64(GetIntEnvOrDefault("TRANSCRIBE_URL_MAX_BYTES", 500<<20)),
+		TranscribeURLTimeout:          GetDurationEnvOrDefault("TRANSCRIBE_URL_TIMEOUT", 5*time.Minute),
+		TranscribeSourceBuckets:       parseList(GetEnvOrDefault("TRANSCRIBE_SOURCE_BUCKETS", minioBucket)),
+		Tenants:                       GetEnvOrDefault("TENANTS", ""),
+		TenantFromAPIKey:              GetBoolEnvOrDefault("TENANT_FROM_API_KEY", false),
+	}
+	if config.TranscribeChunkDuration > 0 && (config.TranscribeChunkOverlap < 0 || config.TranscribeChunkOverlap >= 2*config.TranscribeChunkDuration) {
+		log.Fatal().Dur("overlap", config.TranscribeChunkOverlap).Dur("duration", config.TranscribeChunkDuration).
+			Msg("TRANSCRIBE_CHUNK_OVERLAP must be at least 0 and less than twice TRANSCRIBE_CHUNK_DURATION")
 	}
 
 	return config
 }
+
+// parseList parses a comma-separated list, dropping empty entries.
+func parseList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseAPIKeys parses a comma-separated list of "name:key" pairs.
+func parseAPIKeys(raw string) map[string]string {
+	keys := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, key, ok := strings.Cut(entry, ":")
+		if !ok || name == "" || key == "" {
+			log.Warn().Msg("Ignoring malformed API_KEYS entry, expected name:key")
+			continue
+		}
+		keys[key] = name
+	}
+	return keys
+}
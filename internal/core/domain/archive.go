@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var zipSignature = []byte("PK\x03\x04")
+
+// IsZipArchive reports whether the content starts with a ZIP local file header.
+func IsZipArchive(file io.ReaderAt) bool {
+	header := make([]byte, len(zipSignature))
+	if _, err := file.ReadAt(header, 0); err != nil {
+		return false
+	}
+	return bytes.Equal(header, zipSignature)
+}
+
+// ZipEntries returns the regular files of a ZIP archive, skipping directories and macOS
+// resource forks. Archives with more than maxEntries files or whose declared
+// uncompressed size exceeds maxBytes are rejected before anything is extracted.
+func ZipEntries(file io.ReaderAt, size int64, maxEntries int, maxBytes int64) ([]*zip.File, error) {
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*zip.File
+	var total uint64
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || strings.HasPrefix(entry.Name, "__MACOSX/") {
+			continue
+		}
+		entries = append(entries, entry)
+		total += entry.UncompressedSize64
+		if len(entries) > maxEntries {
+			return nil, fmt.Errorf("archive holds more than %d files", maxEntries)
+		}
+		if total > uint64(maxBytes) {
+			return nil, fmt.Errorf("archive expands to more than %d bytes", maxBytes)
+		}
+	}
+	return entries, nil
+}
+
+// ExtractZipEntry buffers an archive entry to a temporary file. The archive reader fails
+// if the entry holds more data than its header declares.
+func ExtractZipEntry(entry *zip.File) (*TempFile, error) {
+	reader, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return BufferToTempFile(reader, int64(entry.UncompressedSize64))
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+)
+
+// maxCutSearchSeconds bounds how far before the nominal chunk end a cut is searched for.
+const maxCutSearchSeconds = 30
+
+// ErrChunkOverlap is returned when chunks would not advance through the recording, as
+// happens when the overlap is not less than twice the chunk duration.
+var ErrChunkOverlap = errors.New("chunk overlap must be less than twice the chunk duration")
+
+// AudioChunk is a window of a recording in seconds. Consecutive chunks overlap, Cut is
+// the point within the overlap where the transcripts of both chunks are stitched.
+type AudioChunk struct {
+	Start float64
+	End   float64
+	// Cut is where this chunk hands over to the next one, equal to End for the last chunk.
+	Cut float64
+}
+
+// PlanAudioChunks splits a PCM or float WAV file into windows of about chunkSeconds that
+// overlap by overlapSeconds. Each cut is placed at the quietest 10 ms window of the last
+// quarter of the chunk, at most 30 seconds, so that words are rarely split. A recording
+// shorter than chunkSeconds plus overlapSeconds yields a single chunk. ErrChunkOverlap is
+// returned when the chunks do not advance. The reader position is restored to the start
+// of the file.
+func PlanAudioChunks(file io.ReadSeeker, chunkSeconds float64, overlapSeconds float64) ([]AudioChunk, error) {
+	defer file.Seek(0, io.SeekStart)
+
+	format, dataSize, err := readWAVHeader(file)
+	if err != nil {
+		return nil, ErrUnsupportedAudio
+	}
+	decode := sampleDecoder(format)
+	if decode == nil || format.Channels == 0 || format.SampleRate == 0 {
+		return nil, ErrUnsupportedAudio
+	}
+
+	// Energy of consecutive 10 ms windows.
+	windowFrames := max(int(format.SampleRate)/100, 1)
+	var energies []float64
+	sum := 0.0
+	err = forEachMonoFrame(bufio.NewReader(io.LimitReader(file, int64(dataSize))), format, decode, func(n int, mono float64) {
+		sum += mono * mono
+		if (n+1)%windowFrames == 0 {
+			energies = append(energies, sum)
+			sum = 0
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	total := float64(dataSize/uint32(max(format.BlockAlign, 1))) / float64(format.SampleRate)
+	const windowSeconds = 0.01
+
+	var chunks []AudioChunk
+	start := 0.0
+	for start+chunkSeconds+overlapSeconds < total {
+		target := start + chunkSeconds
+		searchFrom := target - math.Min(chunkSeconds/4, maxCutSearchSeconds)
+		searchFrom = math.Max(searchFrom, start+overlapSeconds)
+
+		cut := target
+		quietest := math.Inf(1)
+		for i := int(searchFrom / windowSeconds); i < len(energies) && float64(i)*windowSeconds < target; i++ {
+			if energies[i] < quietest {
+				quietest = energies[i]
+				cut = (float64(i) + 0.5) * windowSeconds
+			}
+		}
+		chunks = append(chunks, AudioChunk{
+			Start: start,
+			End:   math.Min(cut+overlapSeconds/2, total),
+			Cut:   cut,
+		})
+		next := math.Max(cut-overlapSeconds/2, 0)
+		if next <= start {
+			return nil, ErrChunkOverlap
+		}
+		start = next
+	}
+	return append(chunks, AudioChunk{Start: start, End: total, Cut: total}), nil
+}
+
+// ExtractWAVChunk returns the samples of a chunk as a WAV file in the sample format of
+// the source. The reader position is restored to the start of the file.
+func ExtractWAVChunk(file io.ReadSeeker, chunk AudioChunk) ([]byte, error) {
+	defer file.Seek(0, io.SeekStart)
+
+	format, dataSize, err := readWAVHeader(file)
+	if err != nil || format.BlockAlign == 0 {
+		return nil, ErrUnsupportedAudio
+	}
+	frames := int64(dataSize / uint32(format.BlockAlign))
+	first := min(int64(math.Round(chunk.Start*float64(format.SampleRate))), frames)
+	last := min(int64(math.Round(chunk.End*float64(format.SampleRate))), frames)
+	size := (last - first) * int64(format.BlockAlign)
+
+	if _, err := file.Seek(first*int64(format.BlockAlign), io.SeekCurrent); err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 44+size))
+	writeWAVHeader(buf, format, uint32(size))
+	if _, err := io.CopyN(buf, file, size); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
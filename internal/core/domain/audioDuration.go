@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// WAVE format tags.
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+var errNotWAV = errors.New("not a RIFF/WAVE file")
+
+// wavFormatChunkSize is the size of the part of a fmt chunk read, enough for the
+// sub-format tag of WAVE_FORMAT_EXTENSIBLE files. The rest of the chunk is skipped.
+const wavFormatChunkSize = 26
+
+// wavFormat is the content of a WAVE fmt chunk. For WAVE_FORMAT_EXTENSIBLE files,
+// AudioFormat holds the format tag of the sub-format.
+type wavFormat struct {
+	AudioFormat   uint16
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+// AudioDurationSeconds returns the playback duration of a RIFF/WAVE file by reading
+// its fmt and data chunk headers. The second return value is false for other formats.
+// The reader position is restored to the start of the file.
+func AudioDurationSeconds(file io.ReadSeeker) (float64, bool) {
+	defer file.Seek(0, io.SeekStart)
+
+	format, dataSize, err := readWAVHeader(file)
+	if err != nil || format.ByteRate == 0 {
+		return 0, false
+	}
+	return float64(dataSize) / float64(format.ByteRate), true
+}
+
+// readWAVHeader parses the RIFF/WAVE chunks up to the data chunk, leaving the reader at
+// the first sample, and returns the format and the size of the sample data in bytes.
+func readWAVHeader(file io.ReadSeeker) (wavFormat, uint32, error) {
+	var format wavFormat
+	fileSize, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return format, 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return format, 0, err
+	}
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return format, 0, errNotWAV
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return format, 0, errNotWAV
+	}
+
+	hasFormat := false
+	chunkHeader := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(file, chunkHeader); err != nil {
+			return format, 0, errNotWAV
+		}
+		chunkID := string(chunkHeader[0:4])
+		chunkSize := binary.LittleEndian.Uint32(chunkHeader[4:8])
+		position, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return format, 0, err
+		}
+
+		switch chunkID {
+		case "fmt ":
+			// The size is checked before reading so a forged one cannot exhaust memory.
+			if chunkSize < 16 || int64(chunkSize) > fileSize-position {
+				return format, 0, errNotWAV
+			}
+			fmtChunk := make([]byte, min(chunkSize, wavFormatChunkSize))
+			if _, err := io.ReadFull(file, fmtChunk); err != nil {
+				return format, 0, errNotWAV
+			}
+			format = wavFormat{
+				AudioFormat:   binary.LittleEndian.Uint16(fmtChunk[0:2]),
+				Channels:      binary.LittleEndian.Uint16(fmtChunk[2:4]),
+				SampleRate:    binary.LittleEndian.Uint32(fmtChunk[4:8]),
+				ByteRate:      binary.LittleEndian.Uint32(fmtChunk[8:12]),
+				BlockAlign:    binary.LittleEndian.Uint16(fmtChunk[12:14]),
+				BitsPerSample: binary.LittleEndian.Uint16(fmtChunk[14:16]),
+			}
+			// The sub-format GUID of extensible files starts with the format tag.
+			if format.AudioFormat == wavFormatExtensible && chunkSize >= 26 {
+				format.AudioFormat = binary.LittleEndian.Uint16(fmtChunk[24:26])
+			}
+			hasFormat = true
+			if _, err := file.Seek(position+int64(chunkSize)+int64(chunkSize%2), io.SeekStart); err != nil {
+				return format, 0, err
+			}
+		case "data":
+			if !hasFormat {
+				return format, 0, errNotWAV
+			}
+			return format, chunkSize, nil
+		default:
+			// Chunks are word aligned, odd sizes carry a padding byte.
+			if _, err := file.Seek(int64(chunkSize)+int64(chunkSize%2), io.SeekCurrent); err != nil {
+				return format, 0, err
+			}
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	// NormalizedSampleRate is the sample rate Whisper works at.
+	NormalizedSampleRate = 16000
+
+	normalizedTargetRMS = 0.1   // -20 dBFS
+	normalizedPeakLimit = 0.891 // -1 dBFS
+	normalizedMaxGain   = 31.6  // +30 dB
+	silenceThreshold    = 0.00316
+	silenceWindow       = NormalizedSampleRate / 100 // 10 ms
+	silencePadding      = NormalizedSampleRate / 10  // 100 ms
+)
+
+var (
+	// ErrUnsupportedAudio is returned for inputs other than PCM or float WAV files.
+	ErrUnsupportedAudio = errors.New("audio format not supported for normalization")
+	// ErrSilentAudio is returned when nothing is left after trimming silence.
+	ErrSilentAudio = errors.New("audio is silent")
+)
+
+// NormalizeWAV converts a PCM or IEEE float WAV file to a 16 kHz mono 16-bit PCM WAV
+// file: channels are downmixed, the signal is resampled, leading and trailing silence
+// is trimmed and the loudness is normalized to -20 dBFS RMS without peaks above
+// -1 dBFS. The reader position is restored to the start of the file.
+func NormalizeWAV(file io.ReadSeeker) ([]byte, error) {
+	defer file.Seek(0, io.SeekStart)
+
+	format, dataSize, err := readWAVHeader(file)
+	if err != nil {
+		return nil, ErrUnsupportedAudio
+	}
+	decode := sampleDecoder(format)
+	if decode == nil || format.Channels == 0 || format.SampleRate == 0 {
+		return nil, ErrUnsupportedAudio
+	}
+
+	samples, err := decodeMono16k(bufio.NewReader(io.LimitReader(file, int64(dataSize))), format, decode)
+	if err != nil {
+		return nil, err
+	}
+	samples = trimSilence(samples)
+	if len(samples) == 0 {
+		return nil, ErrSilentAudio
+	}
+	normalizeLoudness(samples)
+	return encodePCM16WAV(samples), nil
+}
+
+// sampleDecoder returns a function converting one little-endian sample to [-1, 1], or
+// nil for unsupported formats.
+func sampleDecoder(format wavFormat) func([]byte) float64 {
+	switch {
+	case format.AudioFormat == wavFormatPCM && format.BitsPerSample == 8:
+		return func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }
+	case format.AudioFormat == wavFormatPCM && format.BitsPerSample == 16:
+		return func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) / 32768 }
+	case format.AudioFormat == wavFormatPCM && format.BitsPerSample == 24:
+		return func(b []byte) float64 {
+			return float64(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)>>8) / 8388608
+		}
+	case format.AudioFormat == wavFormatPCM && format.BitsPerSample == 32:
+		return func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648 }
+	case format.AudioFormat == wavFormatFloat && format.BitsPerSample == 32:
+		return func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) }
+	}
+	return nil
+}
+
+// forEachMonoFrame streams the sample data, calling fn with the index and the average
+// of the channels of every frame.
+func forEachMonoFrame(data io.Reader, format wavFormat, decode func([]byte) float64, fn func(n int, mono float64)) error {
+	bytesPerSample := int(format.BitsPerSample / 8)
+	channels := int(format.Channels)
+	frame := make([]byte, bytesPerSample*channels)
+	if int(format.BlockAlign) > len(frame) {
+		frame = make([]byte, format.BlockAlign)
+	}
+	for n := 0; ; n++ {
+		if _, err := io.ReadFull(data, frame); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+		mono := 0.0
+		for c := 0; c < channels; c++ {
+			mono += decode(frame[c*bytesPerSample:])
+		}
+		fn(n, mono/float64(channels))
+	}
+}
+
+// decodeMono16k downmixes the sample data and resamples it to NormalizedSampleRate by
+// linear interpolation. When downsampling, a moving average over one output period
+// filters out most of the aliasing.
+func decodeMono16k(data io.Reader, format wavFormat, decode func([]byte) float64) ([]float32, error) {
+	step := float64(format.SampleRate) / NormalizedSampleRate
+	window := make([]float64, max(int(math.Round(step)), 1))
+	windowSum := 0.0
+	output := make([]float32, 0, 1024)
+
+	var previous float64
+	next := 0.0 // source position of the next output sample
+	err := forEachMonoFrame(data, format, decode, func(n int, mono float64) {
+		windowSum += mono - window[n%len(window)]
+		window[n%len(window)] = mono
+		current := windowSum / float64(min(n+1, len(window)))
+
+		if n == 0 {
+			previous = current
+		}
+		for next <= float64(n) {
+			fraction := next - float64(n-1)
+			output = append(output, float32(previous+(current-previous)*fraction))
+			next += step
+		}
+		previous = current
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// trimSilence drops leading and trailing 10 ms windows below -50 dBFS RMS, keeping
+// 100 ms of padding around the speech.
+func trimSilence(samples []float32) []float32 {
+	loud := func(start int) bool {
+		end := min(start+silenceWindow, len(samples))
+		sum := 0.0
+		for _, sample := range samples[start:end] {
+			sum += float64(sample) * float64(sample)
+		}
+		return math.Sqrt(sum/float64(end-start)) > silenceThreshold
+	}
+
+	first, last := -1, -1
+	for start := 0; start < len(samples); start += silenceWindow {
+		if loud(start) {
+			if first < 0 {
+				first = start
+			}
+			last = min(start+silenceWindow, len(samples))
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	return samples[max(first-silencePadding, 0):min(last+silencePadding, len(samples))]
+}
+
+// normalizeLoudness scales the samples to the target RMS, limited by the peak ceiling
+// and the maximum gain.
+func normalizeLoudness(samples []float32) {
+	sum, peak := 0.0, 0.0
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+		peak = math.Max(peak, math.Abs(float64(sample)))
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+	if rms == 0 {
+		return
+	}
+	gain := math.Min(normalizedTargetRMS/rms, normalizedMaxGain)
+	if peak*gain > normalizedPeakLimit {
+		gain = normalizedPeakLimit / peak
+	}
+	for i := range samples {
+		samples[i] = float32(float64(samples[i]) * gain)
+	}
+}
+
+func encodePCM16WAV(samples []float32) []byte {
+	format := wavFormat{
+		AudioFormat:   wavFormatPCM,
+		Channels:      1,
+		SampleRate:    NormalizedSampleRate,
+		ByteRate:      NormalizedSampleRate * 2,
+		BlockAlign:    2,
+		BitsPerSample: 16,
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 44+len(samples)*2))
+	writeWAVHeader(buf, format, uint32(len(samples)*2))
+	pcm := make([]byte, 2)
+	for _, sample := range samples {
+		value := math.Max(-1, math.Min(1, float64(sample)))
+		binary.LittleEndian.PutUint16(pcm, uint16(int16(math.Round(value*32767))))
+		buf.Write(pcm)
+	}
+	return buf.Bytes()
+}
+
+// writeWAVHeader writes a canonical 44-byte RIFF/WAVE header for dataSize bytes of samples.
+func writeWAVHeader(buf *bytes.Buffer, format wavFormat, dataSize uint32) {
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, format)
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, dataSize)
+}
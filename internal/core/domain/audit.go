@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Audit actions and outcomes.
+const (
+	AuditActionUpload = "upload"
+	AuditActionDelete = "delete"
+	AuditActionPurge  = "purge"
+
+	AuditOutcomeSuccess  = "success"
+	AuditOutcomeRejected = "rejected"
+	AuditOutcomeFailed   = "failed"
+)
+
+// AuditRecord is a single append-only entry describing who did what with which file.
+type AuditRecord struct {
+	ID               string    `json:"id"`
+	Timestamp        time.Time `json:"timestamp"`
+	Action           string    `json:"action"`
+	Caller           string    `json:"caller"`
+	Tenant           string    `json:"tenant,omitempty"`
+	RequestID        string    `json:"request_id,omitempty"`
+	OriginalFilename string    `json:"original_filename,omitempty"`
+	// Source is the URL or bucket/object a file was transcribed from instead of uploaded.
+	Source           string `json:"source,omitempty"`
+	ObjectKey        string `json:"object_key,omitempty"`
+	Size             int64  `json:"size,omitempty"`
+	MIMEType         string `json:"mime_type,omitempty"`
+	SHA256           string `json:"sha256,omitempty"`
+	DetectedLanguage string `json:"detected_language,omitempty"`
+	CacheHit         bool   `json:"cache_hit,omitempty"`
+	// Reason explains why the action happened, e.g. why an object was purged.
+	Reason    string           `json:"reason,omitempty"`
+	Outcome   string           `json:"outcome"`
+	Error     string           `json:"error,omitempty"`
+	TimingsMs map[string]int64 `json:"timings_ms,omitempty"`
+}
+
+// AuditQuery filters audit records. Zero values disable the corresponding filter,
+// except Tenant: records of other tenants never match, the empty ID being the default
+// tenant.
+type AuditQuery struct {
+	From   time.Time
+	To     time.Time
+	Caller string
+	Tenant string
+	Limit  int
+}
+
+// Matches reports whether the record satisfies the query filters.
+func (q AuditQuery) Matches(record AuditRecord) bool {
+	if !q.From.IsZero() && record.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && record.Timestamp.After(q.To) {
+		return false
+	}
+	if q.Caller != "" && record.Caller != q.Caller {
+		return false
+	}
+	return record.Tenant == q.Tenant
+}
+
+// NewAuditRecord starts an audit record for the caller, tenant and request stored in ctx.
+func NewAuditRecord(ctx context.Context, action string) AuditRecord {
+	return AuditRecord{
+		ID:        uuid.NewString(),
+		Timestamp: time.Now().UTC(),
+		Action:    action,
+		Caller:    CallerFromContext(ctx).ID,
+		Tenant:    TenantFromContext(ctx).ID,
+		RequestID: RequestIDFromContext(ctx),
+		TimingsMs: make(map[string]int64),
+	}
+}
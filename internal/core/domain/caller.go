@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	// AnonymousCaller identifies requests made without an API key.
+	AnonymousCaller = "anonymous"
+	// SystemCaller identifies work started by the service itself, such as retention sweeps.
+	SystemCaller = "system"
+)
+
+// Caller describes the identity of the client making a request.
+type Caller struct {
+	// ID is the configured name of the API key, a fingerprint of an unknown key,
+	// or AnonymousCaller.
+	ID string
+	// Known reports whether the API key matched a configured entry.
+	Known bool
+}
+
+type callerContextKey struct{}
+
+// ResolveCaller maps the presented API key to a caller identity using the configured
+// key-to-name table. Unknown keys are identified by a short fingerprint so that the
+// raw secret never reaches logs.
+func ResolveCaller(apiKey string, apiKeys map[string]string) Caller {
+	if apiKey == "" {
+		return Caller{ID: AnonymousCaller}
+	}
+	if name, ok := apiKeys[apiKey]; ok {
+		return Caller{ID: name, Known: true}
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return Caller{ID: "key-" + hex.EncodeToString(sum[:4])}
+}
+
+// ContextWithCaller stores the caller identity in ctx.
+func ContextWithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, caller)
+}
+
+// CallerFromContext returns the caller stored in ctx, or the anonymous caller.
+func CallerFromContext(ctx context.Context) Caller {
+	if caller, ok := ctx.Value(callerContextKey{}).(Caller); ok {
+		return caller
+	}
+	return Caller{ID: AnonymousCaller}
+}
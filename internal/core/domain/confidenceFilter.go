@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Actions applied to words below the minimum confidence.
+const (
+	ConfidenceActionMark   = "mark"
+	ConfidenceActionRedact = "redact"
+)
+
+// ConfidenceFilter flags or redacts words whose probability is below MinConfidence.
+// It is applied to responses only; stored transcripts keep every word.
+type ConfidenceFilter struct {
+	MinConfidence float64 `json:"min_confidence,omitempty"`
+	// Action is ConfidenceActionMark (default) or ConfidenceActionRedact.
+	Action string `json:"confidence_action,omitempty"`
+}
+
+// RedactedWord replaces low-confidence words when redaction is requested.
+const RedactedWord = "[inaudible]"
+
+// ParseConfidenceFilter reads the min_confidence and confidence_action fields through get
+// and validates them.
+func ParseConfidenceFilter(get func(key string) string) (ConfidenceFilter, error) {
+	filter := ConfidenceFilter{Action: get("confidence_action")}
+	if raw := get("min_confidence"); raw != "" {
+		minConfidence, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid 'min_confidence', expected a number between 0 and 1")
+		}
+		filter.MinConfidence = minConfidence
+	}
+	return NormalizeConfidenceFilter(filter)
+}
+
+// NormalizeConfidenceFilter defaults the action to marking and validates the filter.
+func NormalizeConfidenceFilter(filter ConfidenceFilter) (ConfidenceFilter, error) {
+	filter.Action = strings.ToLower(strings.TrimSpace(filter.Action))
+	if filter.Action == "" {
+		filter.Action = ConfidenceActionMark
+	}
+	if math.IsNaN(filter.MinConfidence) || filter.MinConfidence < 0 || filter.MinConfidence > 1 {
+		return filter, fmt.Errorf("invalid 'min_confidence', expected a number between 0 and 1")
+	}
+	if filter.Action != ConfidenceActionMark && filter.Action != ConfidenceActionRedact {
+		return filter, fmt.Errorf("invalid 'confidence_action', expected %q or %q", ConfidenceActionMark, ConfidenceActionRedact)
+	}
+	return filter, nil
+}
+
+// ApplyConfidenceFilter returns a copy of the result with words below the minimum
+// confidence marked, or redacted in the words, segment texts and recognized text.
+// Results without word-level probabilities are returned unchanged.
+func ApplyConfidenceFilter(result RecognitionSuccess, filter ConfidenceFilter) RecognitionSuccess {
+	if filter.MinConfidence <= 0 || len(result.Segments) == 0 {
+		return result
+	}
+
+	redact := filter.Action == ConfidenceActionRedact
+	redacted := false
+	segments := make([]Segment, len(result.Segments))
+	for i, segment := range result.Segments {
+		if len(segment.Words) > 0 {
+			words := make([]Word, len(segment.Words))
+			segmentRedacted := false
+			for j, word := range segment.Words {
+				if word.Probability < filter.MinConfidence {
+					word.LowConfidence = true
+					if redact {
+						word.Word = redactWord(word.Word)
+						segmentRedacted = true
+					}
+				}
+				words[j] = word
+			}
+			segment.Words = words
+			if segmentRedacted {
+				segment.Text = joinWords(words)
+				redacted = true
+			}
+		}
+		segments[i] = segment
+	}
+	result.Segments = segments
+
+	if redacted {
+		texts := make([]string, len(segments))
+		for i, segment := range segments {
+			texts[i] = segment.Text
+		}
+		result.RecognizedText = strings.TrimSpace(strings.Join(texts, ""))
+	}
+	return result
+}
+
+// redactWord replaces the word, keeping the leading space Whisper attaches to words.
+func redactWord(word string) string {
+	if strings.HasPrefix(word, " ") {
+		return " " + RedactedWord
+	}
+	return RedactedWord
+}
+
+func joinWords(words []Word) string {
+	var text strings.Builder
+	for _, word := range words {
+		text.WriteString(word.Word)
+	}
+	return text.String()
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"math"
+)
+
+// SpeakerTurn is a span of audio attributed to one speaker by the diarizer, with its
+// timing in seconds.
+type SpeakerTurn struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Speaker string  `json:"speaker"`
+}
+
+// AssignSpeakers labels the segments of a transcription with the speaker of the turns
+// they overlap most. Segments with word timings are split where the speaker changes,
+// so that a segment spanning a change of speaker is attributed to both; the segments are
+// then renumbered. The recognized text is unchanged.
+func AssignSpeakers(result RecognitionSuccess, turns []SpeakerTurn) RecognitionSuccess {
+	if len(turns) == 0 || len(result.Segments) == 0 {
+		return result
+	}
+
+	segments := make([]Segment, 0, len(result.Segments))
+	for _, segment := range result.Segments {
+		if len(segment.Words) == 0 {
+			segment.Speaker = speakerAt(turns, segment.Start, segment.End)
+			segments = append(segments, segment)
+			continue
+		}
+
+		first := len(segments)
+		start := 0
+		speaker := speakerAt(turns, segment.Words[0].Start, segment.Words[0].End)
+		for i := 1; i <= len(segment.Words); i++ {
+			next := ""
+			if i < len(segment.Words) {
+				next = speakerAt(turns, segment.Words[i].Start, segment.Words[i].End)
+				if next == speaker {
+					continue
+				}
+			}
+			part := segment
+			part.Words = segment.Words[start:i]
+			part.Start, part.End = part.Words[0].Start, part.Words[len(part.Words)-1].End
+			part.Text = joinWords(part.Words)
+			part.Speaker = speaker
+			segments = append(segments, part)
+			start, speaker = i, next
+		}
+		// The split segments keep the bounds of the original one.
+		if len(segments)-first == 1 {
+			segments[first].Text = segment.Text
+		}
+		segments[first].Start = segment.Start
+		segments[len(segments)-1].End = segment.End
+	}
+
+	if len(segments) != len(result.Segments) {
+		for i := range segments {
+			segments[i].ID = i
+		}
+	}
+	result.Segments = segments
+	return result
+}
+
+// speakerAt returns the speaker talking the longest between start and end, or the
+// speaker of the closest turn when none overlaps.
+func speakerAt(turns []SpeakerTurn, start float64, end float64) string {
+	overlaps := make(map[string]float64)
+	best, bestOverlap := "", 0.0
+	closest, closestDistance := "", math.Inf(1)
+	middle := (start + end) / 2
+	for _, turn := range turns {
+		if overlap := math.Min(end, turn.End) - math.Max(start, turn.Start); overlap > 0 {
+			overlaps[turn.Speaker] += overlap
+			if overlaps[turn.Speaker] > bestOverlap {
+				best, bestOverlap = turn.Speaker, overlaps[turn.Speaker]
+			}
+		}
+		distance := math.Max(turn.Start-middle, middle-turn.End)
+		if distance < closestDistance {
+			closest, closestDistance = turn.Speaker, distance
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return closest
+}
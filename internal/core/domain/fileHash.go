@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"io"
+	"sr-api/internal/core/ports/telemetry"
+)
+
+// FileSHA256WithContext computes the hex-encoded SHA-256 digest of the file
+// and rewinds it, tracing the operation as a child of the span in ctx.
+func FileSHA256WithContext(ctx context.Context, file io.ReadSeeker) (string, error) {
+	ctx, span := telemetry.StartSpan(ctx, "FileSHA256")
+	defer span.End()
+	logger := telemetry.LoggerFromContext(ctx)
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		logAndSpanError(logger, span, err, "Failed to reset file pointer before hashing")
+		return "", err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		logAndSpanError(logger, span, err, "Failed to hash file")
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		logAndSpanError(logger, span, err, "Failed to reset file pointer after hashing")
+		return "", err
+	}
+
+	digest := hex.EncodeToString(hash.Sum(nil))
+	span.SetAttributes(attribute.String("file.sha256", digest))
+	span.SetStatus(codes.Ok, "File hashed")
+	return digest, nil
+}
@@ -1,10 +1,12 @@
 package domain
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"github.com/gin-gonic/gin"
 	"github.com/h2non/filetype"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -16,72 +18,110 @@ import (
 
 const SignatureLength = 261
 
-// CheckFileSignatureWithGinContext checks if the given file is a valid media file (audio or video)
-// within a Gin application context, utilizing tracing.
-func CheckFileSignatureWithGinContext(c *gin.Context, file multipart.File) error {
-	_, span := telemetry.StartSpanFromGinContext(c, "CheckFileSignatureWithGinContext")
+// Signature rejection reasons reported to the metrics pipeline.
+const (
+	rejectReasonIO          = "io_error"
+	rejectReasonTooSmall    = "too_small"
+	rejectReasonUnknownType = "unknown_type"
+	rejectReasonNotMedia    = "not_media"
+)
+
+// CheckFileSignatureWithContext checks if the given file is a valid media file (audio or video)
+// and returns its detected MIME type, tracing the operation as a child of the span in ctx.
+func CheckFileSignatureWithContext(ctx context.Context, file multipart.File) (string, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CheckFileSignature")
 	defer span.End()
 
-	spanID := telemetry.GetSpanId(span)
-	log.Debug().Str("span_id", spanID).Msg("Initiating file signature verification process")
+	logger := telemetry.LoggerFromContext(ctx)
+	metrics := telemetry.MetricsFromContext(ctx)
+	logger.Debug().Msg("Initiating file signature verification process")
 
 	// File size check...
 	size, err := file.Seek(0, io.SeekEnd)
 	if err != nil {
-		logAndSpanError(span, spanID, err, "Failed to seek file to end")
-		return err
+		logAndSpanError(logger, span, err, "Failed to seek file to end")
+		metrics.RecordSignatureRejection(ctx, rejectReasonIO)
+		return "", err
 	}
 	if size < SignatureLength {
 		err := fmt.Errorf("file size is too small")
-		logAndSpanError(span, spanID, err, "File size too small for signature check")
-		return err
+		logAndSpanError(logger, span, err, "File size too small for signature check")
+		metrics.RecordSignatureRejection(ctx, rejectReasonTooSmall)
+		return "", err
 	}
 	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		logAndSpanError(span, spanID, err, "Failed to reset file pointer to start")
-		return err
+		logAndSpanError(logger, span, err, "Failed to reset file pointer to start")
+		metrics.RecordSignatureRejection(ctx, rejectReasonIO)
+		return "", err
 	}
 
+	// A single Read may return fewer bytes than available, as object readers do.
 	buf := make([]byte, SignatureLength)
-	if _, err := file.Read(buf); err != nil {
-		logAndSpanError(span, spanID, err, "Failed to read file signature")
-		return err
+	_, err = io.ReadFull(file, buf)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		err := fmt.Errorf("file size is too small")
+		logAndSpanError(logger, span, err, "File shorter than its size for signature check")
+		metrics.RecordSignatureRejection(ctx, rejectReasonTooSmall)
+		return "", err
+	}
+	if err != nil {
+		logAndSpanError(logger, span, err, "Failed to read file signature")
+		metrics.RecordSignatureRejection(ctx, rejectReasonIO)
+		return "", err
 	}
 	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		logAndSpanError(span, spanID, err, "Failed to reset file pointer after signature read")
-		return err
+		logAndSpanError(logger, span, err, "Failed to reset file pointer after signature read")
+		metrics.RecordSignatureRejection(ctx, rejectReasonIO)
+		return "", err
 	}
 
 	kind, err := filetype.Match(buf)
 	if err != nil {
-		logAndSpanError(span, spanID, err, "Error matching file type")
-		return err
+		logAndSpanError(logger, span, err, "Error matching file type")
+		metrics.RecordSignatureRejection(ctx, rejectReasonUnknownType)
+		return "", err
 	}
 	if kind == filetype.Unknown {
 		err := fmt.Errorf("unknown file type")
-		logAndSpanError(span, spanID, err, "File type is unknown")
-		return err
+		logAndSpanError(logger, span, err, "File type is unknown")
+		metrics.RecordSignatureRejection(ctx, rejectReasonUnknownType)
+		return "", err
 	}
 
 	// Check if the file type is audio or video based on MIME prefix
 	if !isMediaFile(kind.MIME.Value) {
 		err := fmt.Errorf("invalid file type: %s", kind.MIME.Value)
-		logAndSpanError(span, spanID, err, "Non-media file type detected")
-		return err
+		logAndSpanError(logger, span, err, "Non-media file type detected")
+		metrics.RecordSignatureRejection(ctx, rejectReasonNotMedia)
+		return "", err
 	}
 
-	log.Info().Str("span_id", spanID).Str("file_type", kind.MIME.Value).Msg("File signature verification successful")
+	logger.Info().Str("file_type", kind.MIME.Value).Msg("File signature verification successful")
 	span.SetAttributes(attribute.String("file.type", kind.MIME.Value))
 	span.SetStatus(codes.Ok, "File signature verified successfully")
 
-	return nil
+	return kind.MIME.Value, nil
+}
+
+// memoryFile adapts an in-memory buffer to multipart.File.
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
+
+// NewMemoryFile wraps in-memory data, such as generated audio, so that it can be passed
+// where a multipart.File is expected.
+func NewMemoryFile(data []byte) multipart.File {
+	return memoryFile{bytes.NewReader(data)}
 }
 
 func isMediaFile(mimeType string) bool {
 	return strings.HasPrefix(mimeType, "audio/") || strings.HasPrefix(mimeType, "video/")
 }
 
-func logAndSpanError(span trace.Span, spanID string, err error, message string) {
-	log.Error().Str("span_id", spanID).Err(err).Msg(message)
+func logAndSpanError(logger *zerolog.Logger, span trace.Span, err error, message string) {
+	logger.Error().Err(err).Msg(message)
 	span.RecordError(err)
 	span.SetAttributes(attribute.String("error.detail", message))
 }
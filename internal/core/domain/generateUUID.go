@@ -1,9 +1,8 @@
 package domain
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel/codes"
 	"sr-api/internal/core/ports/telemetry"
 
@@ -11,12 +10,12 @@ import (
 )
 
 // GenerateUIDWithContext generates a unique identifier (UUID),
-// optionally tracing the operation.
-func GenerateUIDWithContext(c *gin.Context) (string, error) {
-	_, span := telemetry.StartSpanFromGinContext(c, "GenerateUID")
-	spanID := telemetry.GetSpanId(span)
+// tracing the operation as a child of the span in ctx.
+func GenerateUIDWithContext(ctx context.Context) (string, error) {
+	ctx, span := telemetry.StartSpan(ctx, "GenerateUID")
+	logger := telemetry.LoggerFromContext(ctx)
 	defer span.End()
-	log.Debug().Str("span_id", spanID).Msg("Generating UUID")
+	logger.Debug().Msg("Generating UUID")
 
 	uid, err := uuid.NewUUID()
 	if err != nil {
@@ -25,6 +24,6 @@ func GenerateUIDWithContext(c *gin.Context) (string, error) {
 	}
 	span.SetStatus(codes.Ok, "UUID generated")
 	span.SetAttributes(attribute.String("uuid", uid.String()))
-	log.Info().Str("span_id", spanID).Str("uuid", uid.String()).Msg("UUID generated")
+	logger.Info().Str("uuid", uid.String()).Msg("UUID generated")
 	return uid.String(), nil
 }
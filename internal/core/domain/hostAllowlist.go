@@ -0,0 +1,25 @@
+package domain
+
+import "strings"
+
+// HostAllowed reports whether host matches an allowlist entry. Entries match the host
+// exactly, ignoring case, and entries starting with a dot match any subdomain.
+func HostAllowed(host string, allowlist []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return false
+	}
+	for _, entry := range allowlist {
+		entry = strings.ToLower(entry)
+		if strings.HasPrefix(entry, ".") {
+			if strings.HasSuffix(host, entry) {
+				return true
+			}
+			continue
+		}
+		if host == entry {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type numberKind int
+
+const (
+	numberNone numberKind = iota
+	numberZero
+	numberUnit
+	numberTeen
+	numberTens
+	// numberHundreds is a word naming whole hundreds, such as the Russian "двести".
+	numberHundreds
+	// numberHundredMultiplier is the English "hundred" multiplying what precedes it.
+	numberHundredMultiplier
+	numberScale
+	// numberAnd is the English "and" in "one hundred and five".
+	numberAnd
+)
+
+type numberWord struct {
+	kind  numberKind
+	value int64
+}
+
+// numberWords maps the cardinal number words of each language to their value. Russian
+// words are only recognized in the nominative case.
+var numberWords = map[string]map[string]numberWord{
+	"en": {
+		"zero": {numberZero, 0}, "one": {numberUnit, 1}, "two": {numberUnit, 2}, "three": {numberUnit, 3},
+		"four": {numberUnit, 4}, "five": {numberUnit, 5}, "six": {numberUnit, 6}, "seven": {numberUnit, 7},
+		"eight": {numberUnit, 8}, "nine": {numberUnit, 9}, "ten": {numberTeen, 10}, "eleven": {numberTeen, 11},
+		"twelve": {numberTeen, 12}, "thirteen": {numberTeen, 13}, "fourteen": {numberTeen, 14},
+		"fifteen": {numberTeen, 15}, "sixteen": {numberTeen, 16}, "seventeen": {numberTeen, 17},
+		"eighteen": {numberTeen, 18}, "nineteen": {numberTeen, 19}, "twenty": {numberTens, 20},
+		"thirty": {numberTens, 30}, "forty": {numberTens, 40}, "fifty": {numberTens, 50}, "sixty": {numberTens, 60},
+		"seventy": {numberTens, 70}, "eighty": {numberTens, 80}, "ninety": {numberTens, 90},
+		"hundred": {numberHundredMultiplier, 100}, "thousand": {numberScale, 1_000},
+		"million": {numberScale, 1_000_000}, "billion": {numberScale, 1_000_000_000}, "and": {numberAnd, 0},
+	},
+	"ru": {
+		"ноль": {numberZero, 0}, "нуль": {numberZero, 0}, "один": {numberUnit, 1}, "одна": {numberUnit, 1},
+		"одно": {numberUnit, 1}, "два": {numberUnit, 2}, "две": {numberUnit, 2}, "три": {numberUnit, 3},
+		"четыре": {numberUnit, 4}, "пять": {numberUnit, 5}, "шесть": {numberUnit, 6}, "семь": {numberUnit, 7},
+		"восемь": {numberUnit, 8}, "девять": {numberUnit, 9}, "десять": {numberTeen, 10},
+		"одиннадцать": {numberTeen, 11}, "двенадцать": {numberTeen, 12}, "тринадцать": {numberTeen, 13},
+		"четырнадцать": {numberTeen, 14}, "пятнадцать": {numberTeen, 15}, "шестнадцать": {numberTeen, 16},
+		"семнадцать": {numberTeen, 17}, "восемнадцать": {numberTeen, 18}, "девятнадцать": {numberTeen, 19},
+		"двадцать": {numberTens, 20}, "тридцать": {numberTens, 30}, "сорок": {numberTens, 40},
+		"пятьдесят": {numberTens, 50}, "шестьдесят": {numberTens, 60}, "семьдесят": {numberTens, 70},
+		"восемьдесят": {numberTens, 80}, "девяносто": {numberTens, 90}, "сто": {numberHundreds, 100},
+		"двести": {numberHundreds, 200}, "триста": {numberHundreds, 300}, "четыреста": {numberHundreds, 400},
+		"пятьсот": {numberHundreds, 500}, "шестьсот": {numberHundreds, 600}, "семьсот": {numberHundreds, 700},
+		"восемьсот": {numberHundreds, 800}, "девятьсот": {numberHundreds, 900},
+		"тысяча": {numberScale, 1_000}, "тысячи": {numberScale, 1_000}, "тысяч": {numberScale, 1_000},
+		"миллион": {numberScale, 1_000_000}, "миллиона": {numberScale, 1_000_000}, "миллионов": {numberScale, 1_000_000},
+		"миллиард": {numberScale, 1_000_000_000}, "миллиарда": {numberScale, 1_000_000_000},
+		"миллиардов": {numberScale, 1_000_000_000},
+	},
+}
+
+// numberParser accumulates the value of consecutive number words.
+type numberParser struct {
+	total     int64
+	current   int64
+	last      numberKind
+	lastScale int64
+	closed    bool
+}
+
+// accept adds a number word if it can continue the number, so that "twenty twenty" is
+// read as two numbers and not as forty.
+func (p *numberParser) accept(word numberWord) bool {
+	if p.closed {
+		return false
+	}
+	afterHigherPlace := p.last == numberNone || p.last == numberHundreds || p.last == numberHundredMultiplier || p.last == numberScale || p.last == numberAnd
+	switch word.kind {
+	case numberZero:
+		if p.last != numberNone {
+			return false
+		}
+		p.closed = true
+	case numberUnit:
+		if p.current%10 != 0 || !(afterHigherPlace || p.last == numberTens) {
+			return false
+		}
+		p.current += word.value
+	case numberTeen, numberTens:
+		if p.current%100 != 0 || !afterHigherPlace {
+			return false
+		}
+		p.current += word.value
+	case numberHundreds:
+		if p.current != 0 || !(p.last == numberNone || p.last == numberScale) {
+			return false
+		}
+		p.current = word.value
+	case numberHundredMultiplier:
+		if p.current == 0 || p.current >= 100 || !(p.last == numberUnit || p.last == numberTeen || p.last == numberTens) {
+			return false
+		}
+		p.current *= word.value
+	case numberScale:
+		// A scale word alone, as in "thousands of people", is not a number.
+		if p.last == numberNone || p.last == numberScale || p.last == numberAnd || (p.lastScale != 0 && word.value >= p.lastScale) {
+			return false
+		}
+		p.total += max(p.current, 1) * word.value
+		p.current = 0
+		p.lastScale = word.value
+	case numberAnd:
+		if p.last != numberHundredMultiplier && p.last != numberScale {
+			return false
+		}
+	}
+	p.last = word.kind
+	return true
+}
+
+func (p *numberParser) value() int64 {
+	return p.total + p.current
+}
+
+// NormalizeNumbers writes the cardinal numbers spelled out in English or Russian with
+// digits, such as "two thousand and twenty four" as "2024". Numbers below ten written as
+// a single word are kept, following the usual style of spelling them out. Transcriptions
+// in other languages are returned unchanged.
+func NormalizeNumbers(result RecognitionSuccess, language string) RecognitionSuccess {
+	vocabulary, ok := numberWords[language]
+	if !ok {
+		return result
+	}
+	return mapWords(result, func(words []Word) ([]Word, bool) {
+		normalized := make([]Word, 0, len(words))
+		changed := false
+		for i := 0; i < len(words); {
+			end, value := parseNumberWords(words[i:], vocabulary)
+			singleDigit := end == 1 && value < 10
+			if end == 0 || singleDigit {
+				normalized = append(normalized, words[i])
+				i++
+				continue
+			}
+			normalized = append(normalized, mergeWords(words, i, i+end, strconv.FormatInt(value, 10)))
+			i += end
+			changed = true
+		}
+		return normalized, changed
+	})
+}
+
+// parseNumberWords returns how many leading words form a number and its value. A word
+// may hold several number words joined by hyphens, as in "twenty-four". Punctuation
+// after a word ends the number.
+func parseNumberWords(words []Word, vocabulary map[string]numberWord) (int, int64) {
+	var parser numberParser
+	end, endValue := 0, int64(0)
+	for i, word := range words {
+		text := strings.TrimSpace(word.Word)
+		core := strings.TrimFunc(text, unicode.IsPunct)
+		if core == "" || (i > 0 && !strings.HasPrefix(text, core)) {
+			break
+		}
+		candidate := parser
+		accepted := true
+		for _, part := range strings.Split(strings.ReplaceAll(strings.ToLower(core), "ё", "е"), "-") {
+			number, ok := vocabulary[part]
+			if !ok || !candidate.accept(number) {
+				accepted = false
+				break
+			}
+		}
+		if !accepted {
+			break
+		}
+		parser = candidate
+		// A trailing "and" is not part of the number.
+		if parser.last != numberAnd {
+			end, endValue = i+1, parser.value()
+		}
+		if len(core) != len(text) {
+			break
+		}
+	}
+	return end, endValue
+}
@@ -0,0 +1,420 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Matroska element IDs, including their length marker bits.
+const (
+	ebmlHeaderID         = 0x1A45DFA3
+	ebmlDocTypeID        = 0x4282
+	matroskaSegmentID    = 0x18538067
+	matroskaSeekHeadID   = 0x114D9B74
+	matroskaInfoID       = 0x1549A966
+	matroskaTracksID     = 0x1654AE6B
+	matroskaTrackEntryID = 0xAE
+	matroskaTrackNumber  = 0xD7
+	matroskaTrackType    = 0x83
+	matroskaCodecID      = 0x86
+	matroskaLanguage     = 0x22B59C
+	matroskaFlagDefault  = 0x88
+	matroskaFlagEnabled  = 0xB9
+	matroskaClusterID    = 0x1F43B675
+	matroskaTimestampID  = 0xE7
+	matroskaSimpleBlock  = 0xA3
+	matroskaBlockGroup   = 0xA0
+	matroskaBlock        = 0xA1
+	matroskaCuesID       = 0x1C53BB6B
+	matroskaTagsID       = 0x1254C367
+	matroskaChaptersID   = 0x1043A770
+	matroskaAttachments  = 0x1941A469
+
+	matroskaTrackTypeAudio = 2
+	// maxMatroskaHeaderElement bounds the Info and Tracks elements read into memory.
+	maxMatroskaHeaderElement = 16 << 20
+)
+
+// matroskaLevel1 lists the segment children that end a cluster of unknown size.
+var matroskaLevel1 = map[uint32]bool{
+	matroskaSeekHeadID: true, matroskaInfoID: true, matroskaTracksID: true, matroskaClusterID: true,
+	matroskaCuesID: true, matroskaTagsID: true, matroskaChaptersID: true, matroskaAttachments: true,
+}
+
+// ebmlElement is an element located in the file. Elements of unknown size end at the
+// end of their parent.
+type ebmlElement struct {
+	id         uint32
+	offset     int64
+	dataOffset int64
+	end        int64
+	unknown    bool
+}
+
+// readEBMLElement reads the element header at offset, limit is the end of the parent.
+func readEBMLElement(r io.ReaderAt, offset int64, limit int64) (ebmlElement, error) {
+	header := make([]byte, 12)
+	n, _ := r.ReadAt(header[:min(int64(len(header)), limit-offset)], offset)
+	header = header[:n]
+
+	idLength := vintLength(header)
+	if idLength == 0 || idLength > 4 || len(header) < idLength {
+		return ebmlElement{}, fmt.Errorf("%w: invalid element ID at offset %d", ErrUnsupportedVideo, offset)
+	}
+	var id uint32
+	for _, b := range header[:idLength] {
+		id = id<<8 | uint32(b)
+	}
+	sizeLength := vintLength(header[idLength:])
+	if sizeLength == 0 || len(header) < idLength+sizeLength {
+		return ebmlElement{}, fmt.Errorf("%w: invalid element size at offset %d", ErrUnsupportedVideo, offset)
+	}
+	size, unknown := vintValue(header[idLength : idLength+sizeLength])
+
+	element := ebmlElement{id: id, offset: offset, dataOffset: offset + int64(idLength+sizeLength), unknown: unknown}
+	element.end = limit
+	if !unknown {
+		element.end = element.dataOffset + int64(size)
+		if size > uint64(limit) || element.end > limit {
+			return ebmlElement{}, fmt.Errorf("%w: element at offset %d exceeds its parent", ErrUnsupportedVideo, offset)
+		}
+	}
+	return element, nil
+}
+
+// vintLength returns the length of the variable-size integer starting data, 0 if invalid.
+func vintLength(data []byte) int {
+	if len(data) == 0 || data[0] == 0 {
+		return 0
+	}
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	return length
+}
+
+// vintValue decodes a variable-size integer, reporting the reserved all-ones value.
+func vintValue(data []byte) (uint64, bool) {
+	value := uint64(data[0] & (0xFF >> len(data)))
+	allOnes := value == uint64(0xFF>>len(data))
+	for _, b := range data[1:] {
+		value = value<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+	return value, allOnes
+}
+
+func readEBMLData(r io.ReaderAt, element ebmlElement) ([]byte, error) {
+	if element.end-element.dataOffset > maxMatroskaHeaderElement {
+		return nil, fmt.Errorf("%w: element too large", ErrUnsupportedVideo)
+	}
+	data := make([]byte, element.end-element.dataOffset)
+	return data, readAtFull(r, data, element.dataOffset)
+}
+
+// ebmlChildren returns the children of an element read into memory.
+func ebmlChildren(data []byte) ([]ebmlElement, error) {
+	r := bytes.NewReader(data)
+	var children []ebmlElement
+	for offset := int64(0); offset < int64(len(data)); {
+		child, err := readEBMLElement(r, offset, int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+		offset = child.end
+	}
+	return children, nil
+}
+
+func ebmlUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// appendEBMLElement appends an element with the shortest size encoding to buf. Tools
+// sniffing the DocType expect the EBML header to use one-byte sizes.
+func appendEBMLElement(buf []byte, id uint32, payload []byte) []byte {
+	buf = appendEBMLID(buf, id)
+	length := 1
+	for uint64(len(payload)) >= 1<<(7*length)-1 {
+		length++
+	}
+	size := uint64(len(payload)) | 1<<(7*length)
+	for i := length - 1; i >= 0; i-- {
+		buf = append(buf, byte(size>>(8*i)))
+	}
+	return append(buf, payload...)
+}
+
+func appendEBMLID(buf []byte, id uint32) []byte {
+	switch {
+	case id > 0xFFFFFF:
+		return append(buf, byte(id>>24), byte(id>>16), byte(id>>8), byte(id))
+	case id > 0xFFFF:
+		return append(buf, byte(id>>16), byte(id>>8), byte(id))
+	case id > 0xFF:
+		return append(buf, byte(id>>8), byte(id))
+	}
+	return append(buf, byte(id))
+}
+
+// appendEBMLSize appends an 8-byte size, used where the size is patched afterwards.
+func appendEBMLSize(buf []byte, size uint64) []byte {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, size|0x01<<56)
+	return append(buf, encoded...)
+}
+
+// matroskaAudioTrack is an audio TrackEntry with its raw element.
+type matroskaAudioTrack struct {
+	raw     []byte
+	track   AudioTrack
+	number  uint64
+	enabled bool
+}
+
+// matroskaWriter is the output file: a sequential writer whose Segment size is patched
+// once all clusters are written.
+type matroskaWriter interface {
+	io.Writer
+	io.WriterAt
+}
+
+// extractMatroskaAudio writes a WebM or Matroska file holding the segment info, the
+// chosen audio track and its blocks. Cues, tags, chapters and attachments are dropped.
+func extractMatroskaAudio(r io.ReaderAt, size int64, out io.Writer) (AudioTrack, error) {
+	writer, ok := out.(matroskaWriter)
+	if !ok {
+		return AudioTrack{}, fmt.Errorf("%w: output does not support patching", ErrUnsupportedVideo)
+	}
+
+	header, err := readEBMLElement(r, 0, size)
+	if err != nil || header.id != ebmlHeaderID {
+		return AudioTrack{}, fmt.Errorf("%w: missing EBML header", ErrUnsupportedVideo)
+	}
+	headerData, err := readEBMLData(r, header)
+	if err != nil {
+		return AudioTrack{}, err
+	}
+	docType := "matroska"
+	if children, err := ebmlChildren(headerData); err == nil {
+		for _, child := range children {
+			if child.id == ebmlDocTypeID {
+				docType = string(headerData[child.dataOffset:child.end])
+			}
+		}
+	}
+
+	segment, err := readEBMLElement(r, header.end, size)
+	for err == nil && segment.id != matroskaSegmentID {
+		segment, err = readEBMLElement(r, segment.end, size)
+	}
+	if err != nil {
+		return AudioTrack{}, fmt.Errorf("%w: missing segment", ErrUnsupportedVideo)
+	}
+
+	var info []byte
+	var chosen *matroskaAudioTrack
+	var audioTracks int
+	written := int64(0)
+	segmentSizeOffset := int64(-1)
+	write := func(data []byte) error {
+		n, err := writer.Write(data)
+		written += int64(n)
+		return err
+	}
+	// The output header is written before the first cluster.
+	writeHeader := func() error {
+		if chosen == nil {
+			return ErrNoAudioTrack
+		}
+		outDocType := "matroska"
+		if docType == "webm" && (chosen.track.Codec == "A_OPUS" || chosen.track.Codec == "A_VORBIS") {
+			outDocType = "webm"
+		}
+		chosen.track.Container = outDocType
+		ebml := appendEBMLElement(nil, 0x4286, []byte{1})
+		ebml = appendEBMLElement(ebml, 0x42F7, []byte{1})
+		ebml = appendEBMLElement(ebml, 0x42F2, []byte{4})
+		ebml = appendEBMLElement(ebml, 0x42F3, []byte{8})
+		ebml = appendEBMLElement(ebml, ebmlDocTypeID, []byte(outDocType))
+		ebml = appendEBMLElement(ebml, 0x4287, []byte{4})
+		ebml = appendEBMLElement(ebml, 0x4285, []byte{2})
+
+		out := appendEBMLElement(nil, ebmlHeaderID, ebml)
+		out = appendEBMLID(out, matroskaSegmentID)
+		segmentSizeOffset = int64(len(out))
+		out = appendEBMLSize(out, 0)
+		if info != nil {
+			out = appendEBMLElement(out, matroskaInfoID, info)
+		}
+		out = appendEBMLElement(out, matroskaTracksID, chosen.raw)
+		return write(out)
+	}
+
+	for offset := segment.dataOffset; offset < segment.end; {
+		element, err := readEBMLElement(r, offset, segment.end)
+		if err != nil {
+			return AudioTrack{}, err
+		}
+		switch element.id {
+		case matroskaInfoID:
+			if info, err = readEBMLData(r, element); err != nil {
+				return AudioTrack{}, err
+			}
+		case matroskaTracksID:
+			data, err := readEBMLData(r, element)
+			if err != nil {
+				return AudioTrack{}, err
+			}
+			tracks, err := parseMatroskaAudioTracks(data)
+			if err != nil {
+				return AudioTrack{}, err
+			}
+			audioTracks += len(tracks)
+			for i := range tracks {
+				if chosen == nil || (tracks[i].enabled && !chosen.enabled) {
+					chosen = &tracks[i]
+				}
+			}
+		case matroskaClusterID:
+			if segmentSizeOffset < 0 {
+				if err := writeHeader(); err != nil {
+					return AudioTrack{}, err
+				}
+			}
+			cluster, end, err := copyMatroskaCluster(r, element, chosen.number)
+			if err != nil {
+				return AudioTrack{}, err
+			}
+			if err := write(cluster); err != nil {
+				return AudioTrack{}, err
+			}
+			element.end = end
+		}
+		offset = element.end
+	}
+	if segmentSizeOffset < 0 {
+		if err := writeHeader(); err != nil {
+			return AudioTrack{}, err
+		}
+	}
+
+	segmentSize := appendEBMLSize(nil, uint64(written-segmentSizeOffset-8))
+	if _, err := writer.WriteAt(segmentSize, segmentSizeOffset); err != nil {
+		return AudioTrack{}, err
+	}
+	chosen.track.AudioTracks = audioTracks
+	return chosen.track, nil
+}
+
+// parseMatroskaAudioTracks returns the audio entries of a Tracks element.
+func parseMatroskaAudioTracks(data []byte) ([]matroskaAudioTrack, error) {
+	entries, err := ebmlChildren(data)
+	if err != nil {
+		return nil, err
+	}
+	var tracks []matroskaAudioTrack
+	for _, entry := range entries {
+		if entry.id != matroskaTrackEntryID {
+			continue
+		}
+		entryData := data[entry.dataOffset:entry.end]
+		fields, err := ebmlChildren(entryData)
+		if err != nil {
+			return nil, err
+		}
+		track := matroskaAudioTrack{raw: data[entry.offset:entry.end]}
+		trackType := uint64(0)
+		isDefault, isEnabled := true, true
+		for _, field := range fields {
+			value := entryData[field.dataOffset:field.end]
+			switch field.id {
+			case matroskaTrackNumber:
+				track.number = ebmlUint(value)
+			case matroskaTrackType:
+				trackType = ebmlUint(value)
+			case matroskaCodecID:
+				track.track.Codec = strings.TrimRight(string(value), "\x00")
+			case matroskaLanguage:
+				track.track.Language = strings.TrimRight(string(value), "\x00")
+			case matroskaFlagDefault:
+				isDefault = ebmlUint(value) != 0
+			case matroskaFlagEnabled:
+				isEnabled = ebmlUint(value) != 0
+			}
+		}
+		if trackType != matroskaTrackTypeAudio {
+			continue
+		}
+		track.track.ID = int(track.number)
+		track.enabled = isDefault && isEnabled
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// copyMatroskaCluster returns a cluster holding the timestamp and the blocks of the
+// given track, and where the source cluster ends.
+func copyMatroskaCluster(r io.ReaderAt, cluster ebmlElement, trackNumber uint64) ([]byte, int64, error) {
+	var payload []byte
+	offset := cluster.dataOffset
+	for offset < cluster.end {
+		element, err := readEBMLElement(r, offset, cluster.end)
+		if err != nil {
+			return nil, 0, err
+		}
+		if cluster.unknown && matroskaLevel1[element.id] {
+			break
+		}
+		keep := false
+		switch element.id {
+		case matroskaTimestampID:
+			keep = true
+		case matroskaSimpleBlock:
+			keep = blockTrackNumber(r, element.dataOffset, element.end) == trackNumber
+		case matroskaBlockGroup:
+			for child := element.dataOffset; child < element.end; {
+				block, err := readEBMLElement(r, child, element.end)
+				if err != nil {
+					return nil, 0, err
+				}
+				if block.id == matroskaBlock {
+					keep = blockTrackNumber(r, block.dataOffset, block.end) == trackNumber
+					break
+				}
+				child = block.end
+			}
+		}
+		if keep {
+			raw := make([]byte, element.end-element.offset)
+			if err := readAtFull(r, raw, element.offset); err != nil {
+				return nil, 0, err
+			}
+			payload = append(payload, raw...)
+		}
+		offset = element.end
+	}
+	return appendEBMLElement(nil, matroskaClusterID, payload), offset, nil
+}
+
+// blockTrackNumber decodes the track number at the start of a Block or SimpleBlock.
+func blockTrackNumber(r io.ReaderAt, start int64, end int64) uint64 {
+	data := make([]byte, min(8, end-start))
+	n, _ := r.ReadAt(data, start)
+	data = data[:n]
+	length := vintLength(data)
+	if length == 0 || length > len(data) {
+		return 0
+	}
+	number, _ := vintValue(data[:length])
+	return number
+}
@@ -0,0 +1,285 @@
+package domain
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"slices"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+)
+
+// Media policy rejection reasons, reported to the metrics pipeline alongside the
+// signature rejection reasons.
+const (
+	RejectReasonTypeNotAllowed     = "type_not_allowed"
+	RejectReasonExtensionMismatch  = "extension_mismatch"
+	RejectReasonMalformedContainer = "malformed_container"
+)
+
+// maxOggPagesChecked bounds the Ogg pages whose checksum is verified.
+const maxOggPagesChecked = 16
+
+// MediaPolicy decides which media files are accepted beyond the signature check.
+type MediaPolicy struct {
+	// AllowedTypes lists the accepted MIME types; empty accepts any audio or video type.
+	AllowedTypes []string
+	// CheckExtension rejects files whose extension does not match the detected type.
+	CheckExtension bool
+	// CheckStructure validates the container headers of WAV, MP4, Ogg and FLAC files.
+	CheckStructure bool
+}
+
+// MediaRejection is a file rejected by the media policy, with a message for the client.
+type MediaRejection struct {
+	Reason  string
+	Message string
+}
+
+func (e *MediaRejection) Error() string { return e.Message }
+
+// extensionsByType lists the file extensions accepted for each detected MIME type.
+var extensionsByType = map[string][]string{
+	"audio/mpeg":       {"mp3", "mpga", "mpeg"},
+	"audio/m4a":        {"m4a", "m4b", "mp4"},
+	"audio/ogg":        {"ogg", "oga", "opus", "ogv"},
+	"audio/x-flac":     {"flac"},
+	"audio/x-wav":      {"wav", "wave"},
+	"audio/amr":        {"amr"},
+	"audio/aac":        {"aac"},
+	"audio/x-aiff":     {"aiff", "aif"},
+	"audio/midi":       {"mid", "midi"},
+	"video/mp4":        {"mp4", "m4a", "m4v"},
+	"video/x-m4v":      {"m4v", "mp4"},
+	"video/x-matroska": {"mkv", "mka"},
+	"video/webm":       {"webm", "weba"},
+	"video/quicktime":  {"mov", "qt"},
+	"video/x-msvideo":  {"avi"},
+	"video/x-ms-wmv":   {"wmv"},
+	"video/mpeg":       {"mpg", "mpeg"},
+	"video/x-flv":      {"flv"},
+	"video/3gpp":       {"3gp"},
+}
+
+// ValidateMediaWithContext checks the file signature, then applies the policy: the MIME
+// allowlist, the consistency of the extension of filename with the content and the
+// structure of the container. It returns the detected MIME type; policy failures are
+// reported as *MediaRejection. The reader position is restored to the start of the file.
+func ValidateMediaWithContext(ctx context.Context, file multipart.File, filename string, policy MediaPolicy) (string, error) {
+	mimeType, err := CheckFileSignatureWithContext(ctx, file)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, span := telemetry.StartSpan(ctx, "ValidateMedia", attribute.String("file.type", mimeType))
+	defer span.End()
+
+	rejection := checkMediaPolicy(file, mimeType, filename, policy)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+	if rejection != nil {
+		telemetry.LoggerFromContext(ctx).Warn().Str("reason", rejection.Reason).Msg(rejection.Message)
+		telemetry.MetricsFromContext(ctx).RecordSignatureRejection(ctx, rejection.Reason)
+		span.RecordError(rejection)
+		span.SetAttributes(attribute.String("rejection.reason", rejection.Reason))
+		span.SetStatus(codes.Error, rejection.Message)
+		return "", rejection
+	}
+	span.SetStatus(codes.Ok, "Media validated")
+	return mimeType, nil
+}
+
+func checkMediaPolicy(file multipart.File, mimeType string, filename string, policy MediaPolicy) *MediaRejection {
+	if len(policy.AllowedTypes) > 0 && !slices.Contains(policy.AllowedTypes, mimeType) {
+		return &MediaRejection{RejectReasonTypeNotAllowed, fmt.Sprintf("File type %s is not allowed", mimeType)}
+	}
+	if policy.CheckExtension {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+		if ext != "" && !slices.Contains(extensionsByType[mimeType], ext) {
+			return &MediaRejection{RejectReasonExtensionMismatch, fmt.Sprintf("File extension .%s does not match the detected type %s", ext, mimeType)}
+		}
+	}
+	if policy.CheckStructure {
+		if err := checkContainer(file, mimeType); err != nil {
+			return &MediaRejection{RejectReasonMalformedContainer, fmt.Sprintf("Malformed %s file: %s", mimeType, err)}
+		}
+	}
+	return nil
+}
+
+// checkContainer validates the container structure of the formats it knows, other
+// formats pass.
+func checkContainer(file multipart.File, mimeType string) error {
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	switch mimeType {
+	case "audio/x-wav":
+		return checkRIFF(file, size)
+	case "audio/m4a", "video/mp4", "video/x-m4v", "video/quicktime", "video/3gpp":
+		return checkISOBoxes(file, size, mimeType != "video/quicktime")
+	case "audio/ogg":
+		return checkOggPages(file, size)
+	case "audio/x-flac":
+		return checkFLAC(file)
+	}
+	return nil
+}
+
+// checkRIFF verifies that the RIFF size fits the file, that the fmt chunk describes a
+// usable stream and that the data chunk is not truncated.
+func checkRIFF(file multipart.File, size int64) error {
+	header := make([]byte, 8)
+	if err := readAtFull(file, header, 0); err != nil {
+		return errors.New("truncated RIFF header")
+	}
+	if riffSize := int64(binary.LittleEndian.Uint32(header[4:8])); riffSize+8 > size {
+		return fmt.Errorf("RIFF size %d exceeds the file size %d", riffSize+8, size)
+	}
+	format, dataSize, err := readWAVHeader(file)
+	if err != nil {
+		return errors.New("missing fmt or data chunk")
+	}
+	if format.Channels == 0 || format.SampleRate == 0 || format.BlockAlign == 0 {
+		return errors.New("invalid fmt chunk")
+	}
+	dataStart, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if dataStart+int64(dataSize) > size {
+		return fmt.Errorf("data chunk of %d bytes is truncated", dataSize)
+	}
+	return nil
+}
+
+// checkISOBoxes walks the top-level boxes of an ISO base media file, verifying that they
+// tile the file, that the first one is ftyp when required and that a moov box exists.
+func checkISOBoxes(file multipart.File, size int64, requireFtyp bool) error {
+	header := make([]byte, 16)
+	hasMoov := false
+	for offset, index := int64(0), 0; offset < size; index++ {
+		if size-offset < 8 {
+			return fmt.Errorf("truncated box header at offset %d", offset)
+		}
+		if err := readAtFull(file, header[:8], offset); err != nil {
+			return err
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header[0:4]))
+		boxType := string(header[4:8])
+		switch boxSize {
+		case 0:
+			boxSize = size - offset
+		case 1:
+			if err := readAtFull(file, header[8:16], offset+8); err != nil {
+				return fmt.Errorf("truncated %q box header", boxType)
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+		}
+		if boxSize < 8 || boxSize > size-offset {
+			return fmt.Errorf("%q box at offset %d exceeds the file", boxType, offset)
+		}
+		if index == 0 && requireFtyp && boxType != "ftyp" {
+			return errors.New("file does not start with an ftyp box")
+		}
+		if boxType == "moov" {
+			hasMoov = true
+		}
+		offset += boxSize
+	}
+	if !hasMoov {
+		return errors.New("missing moov box")
+	}
+	return nil
+}
+
+// checkOggPages verifies the layout and checksum of the leading Ogg pages.
+func checkOggPages(file multipart.File, size int64) error {
+	offset := int64(0)
+	for page := 0; page < maxOggPagesChecked && offset < size; page++ {
+		header := make([]byte, 27)
+		if err := readAtFull(file, header, offset); err != nil {
+			return fmt.Errorf("truncated page header at offset %d", offset)
+		}
+		if string(header[0:4]) != "OggS" || header[4] != 0 {
+			return fmt.Errorf("invalid page header at offset %d", offset)
+		}
+		segments := make([]byte, header[26])
+		if err := readAtFull(file, segments, offset+27); err != nil {
+			return fmt.Errorf("truncated segment table at offset %d", offset)
+		}
+		bodySize := 0
+		for _, segment := range segments {
+			bodySize += int(segment)
+		}
+		pageData := make([]byte, 27+len(segments)+bodySize)
+		if err := readAtFull(file, pageData, offset); err != nil {
+			return fmt.Errorf("truncated page at offset %d", offset)
+		}
+		expected := binary.LittleEndian.Uint32(pageData[22:26])
+		clear(pageData[22:26])
+		if oggCRC(pageData) != expected {
+			return fmt.Errorf("checksum mismatch in page %d", page)
+		}
+		offset += int64(len(pageData))
+	}
+	return nil
+}
+
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		crc := uint32(i) << 24
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+// oggCRC is the unreflected CRC-32 used by Ogg pages.
+func oggCRC(data []byte) uint32 {
+	crc := uint32(0)
+	for _, b := range data {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// checkFLAC verifies that the first metadata block is a complete STREAMINFO block.
+func checkFLAC(file multipart.File) error {
+	header := make([]byte, 8+34)
+	if err := readAtFull(file, header, 0); err != nil {
+		return errors.New("truncated STREAMINFO block")
+	}
+	if header[4]&0x7F != 0 || int(header[5])<<16|int(header[6])<<8|int(header[7]) != 34 {
+		return errors.New("first metadata block is not STREAMINFO")
+	}
+	return nil
+}
+
+// readAtFull reads exactly len(p) bytes at off, treating a short read as truncation.
+func readAtFull(file io.ReaderAt, p []byte, off int64) error {
+	n, err := file.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,377 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// maxMoovSize bounds the movie box read into memory.
+const maxMoovSize = 64 << 20
+
+// isoBoxHeader is a box of an ISO base media file located in its parent.
+type isoBoxHeader struct {
+	boxType    string
+	offset     int64
+	headerSize int64
+	size       int64
+}
+
+func (box isoBoxHeader) payload() (int64, int64) {
+	return box.offset + box.headerSize, box.offset + box.size
+}
+
+// readISOBoxHeader reads the box header at offset, limit is the end of the parent.
+func readISOBoxHeader(r io.ReaderAt, offset int64, limit int64) (isoBoxHeader, error) {
+	header := make([]byte, 16)
+	if limit-offset < 8 {
+		return isoBoxHeader{}, fmt.Errorf("%w: truncated box at offset %d", ErrUnsupportedVideo, offset)
+	}
+	if err := readAtFull(r, header[:8], offset); err != nil {
+		return isoBoxHeader{}, err
+	}
+	box := isoBoxHeader{boxType: string(header[4:8]), offset: offset, headerSize: 8, size: int64(binary.BigEndian.Uint32(header[0:4]))}
+	switch box.size {
+	case 0:
+		box.size = limit - offset
+	case 1:
+		if err := readAtFull(r, header[8:16], offset+8); err != nil {
+			return isoBoxHeader{}, err
+		}
+		box.headerSize, box.size = 16, int64(binary.BigEndian.Uint64(header[8:16]))
+	}
+	if box.size < box.headerSize || box.size > limit-offset {
+		return isoBoxHeader{}, fmt.Errorf("%w: %q box exceeds its parent", ErrUnsupportedVideo, box.boxType)
+	}
+	return box, nil
+}
+
+// isoChildren returns the boxes between start and end.
+func isoChildren(r io.ReaderAt, start int64, end int64) ([]isoBoxHeader, error) {
+	var boxes []isoBoxHeader
+	for offset := start; offset < end; {
+		box, err := readISOBoxHeader(r, offset, end)
+		if err != nil {
+			return nil, err
+		}
+		boxes = append(boxes, box)
+		offset += box.size
+	}
+	return boxes, nil
+}
+
+// findISOBox returns the first box of the given type along a path of nested box types.
+func findISOBox(r io.ReaderAt, start int64, end int64, path ...string) (isoBoxHeader, bool) {
+	children, err := isoChildren(r, start, end)
+	if err != nil {
+		return isoBoxHeader{}, false
+	}
+	for _, child := range children {
+		if child.boxType != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return child, true
+		}
+		return findISOBox(r, child.offset+child.headerSize, child.offset+child.size, path[1:]...)
+	}
+	return isoBoxHeader{}, false
+}
+
+// mp4AudioTrack is an audio trak box of the movie box with its sample layout.
+type mp4AudioTrack struct {
+	trak    isoBoxHeader
+	track   AudioTrack
+	enabled bool
+	// chunkOffsets is the stco or co64 box whose entries locate the chunks.
+	chunkOffsets isoBoxHeader
+	chunks       []mp4Chunk
+}
+
+type mp4Chunk struct {
+	offset int64
+	size   int64
+}
+
+// extractMP4Audio writes an M4A file holding the movie header and the chosen audio
+// track of an MP4 or QuickTime file, with its chunks copied into a new media data box.
+func extractMP4Audio(r io.ReaderAt, size int64, out io.Writer) (AudioTrack, error) {
+	topLevel, err := isoChildren(r, 0, size)
+	if err != nil {
+		return AudioTrack{}, err
+	}
+	var moovBox isoBoxHeader
+	for _, box := range topLevel {
+		switch box.boxType {
+		case "moov":
+			moovBox = box
+		case "moof":
+			return AudioTrack{}, fmt.Errorf("%w: fragmented MP4", ErrUnsupportedVideo)
+		}
+	}
+	if moovBox.size == 0 {
+		return AudioTrack{}, fmt.Errorf("%w: missing moov box", ErrUnsupportedVideo)
+	}
+	if moovBox.size > maxMoovSize {
+		return AudioTrack{}, fmt.Errorf("%w: moov box too large", ErrUnsupportedVideo)
+	}
+
+	// The movie box is parsed from memory, offsets below are relative to it.
+	moov := make([]byte, moovBox.size)
+	if err := readAtFull(r, moov, moovBox.offset); err != nil {
+		return AudioTrack{}, err
+	}
+	moovReader := bytes.NewReader(moov)
+	children, err := isoChildren(moovReader, moovBox.headerSize, moovBox.size)
+	if err != nil {
+		return AudioTrack{}, err
+	}
+
+	var mvhd isoBoxHeader
+	var tracks []mp4AudioTrack
+	for _, child := range children {
+		switch child.boxType {
+		case "mvhd":
+			mvhd = child
+		case "mvex":
+			return AudioTrack{}, fmt.Errorf("%w: fragmented MP4", ErrUnsupportedVideo)
+		case "trak":
+			track, ok, err := parseMP4AudioTrack(moovReader, child, size)
+			if err != nil {
+				return AudioTrack{}, err
+			}
+			if ok {
+				tracks = append(tracks, track)
+			}
+		}
+	}
+	if len(tracks) == 0 {
+		return AudioTrack{}, ErrNoAudioTrack
+	}
+	if mvhd.size == 0 {
+		return AudioTrack{}, fmt.Errorf("%w: missing mvhd box", ErrUnsupportedVideo)
+	}
+	chosen := tracks[0]
+	for _, track := range tracks {
+		if track.enabled {
+			chosen = track
+			break
+		}
+	}
+	chosen.track.AudioTracks = len(tracks)
+
+	ftyp := []byte("\x00\x00\x00\x18ftypM4A \x00\x00\x00\x00M4A mp42isom")
+	binary.BigEndian.PutUint32(ftyp[0:4], uint32(len(ftyp)))
+
+	newMoovSize := 8 + mvhd.size + chosen.trak.size
+	newMoov := make([]byte, 8, newMoovSize)
+	binary.BigEndian.PutUint32(newMoov[0:4], uint32(newMoovSize))
+	copy(newMoov[4:8], "moov")
+	newMoov = append(newMoov, moov[mvhd.offset:mvhd.offset+mvhd.size]...)
+	trakStart := int64(len(newMoov))
+	newMoov = append(newMoov, moov[chosen.trak.offset:chosen.trak.offset+chosen.trak.size]...)
+
+	// Rewrite the chunk offsets to the chunks packed one after another in mdat.
+	var mdatSize int64 = 8
+	for _, chunk := range chosen.chunks {
+		mdatSize += chunk.size
+	}
+	if mdatSize > math.MaxUint32 {
+		return AudioTrack{}, fmt.Errorf("%w: audio track too large", ErrUnsupportedVideo)
+	}
+	entries := trakStart + chosen.chunkOffsets.offset - chosen.trak.offset + chosen.chunkOffsets.headerSize + 8
+	offset := int64(len(ftyp)) + newMoovSize + 8
+	for i, chunk := range chosen.chunks {
+		if chosen.chunkOffsets.boxType == "co64" {
+			binary.BigEndian.PutUint64(newMoov[entries+int64(i)*8:], uint64(offset))
+		} else {
+			if offset > math.MaxUint32 {
+				return AudioTrack{}, fmt.Errorf("%w: audio track too large", ErrUnsupportedVideo)
+			}
+			binary.BigEndian.PutUint32(newMoov[entries+int64(i)*4:], uint32(offset))
+		}
+		offset += chunk.size
+	}
+
+	mdatHeader := make([]byte, 8)
+	binary.BigEndian.PutUint32(mdatHeader[0:4], uint32(mdatSize))
+	copy(mdatHeader[4:8], "mdat")
+	for _, part := range [][]byte{ftyp, newMoov, mdatHeader} {
+		if _, err := out.Write(part); err != nil {
+			return AudioTrack{}, err
+		}
+	}
+	for _, chunk := range chosen.chunks {
+		if _, err := io.Copy(out, io.NewSectionReader(r, chunk.offset, chunk.size)); err != nil {
+			return AudioTrack{}, err
+		}
+	}
+	return chosen.track, nil
+}
+
+// parseMP4AudioTrack reads a trak box of the in-memory movie box, returning false for
+// tracks other than sound tracks.
+func parseMP4AudioTrack(moov *bytes.Reader, trak isoBoxHeader, fileSize int64) (mp4AudioTrack, bool, error) {
+	start, end := trak.payload()
+	hdlr, ok := findISOBox(moov, start, end, "mdia", "hdlr")
+	if !ok || boxField(moov, hdlr, 8, 4) != "soun" {
+		return mp4AudioTrack{}, false, nil
+	}
+	track := mp4AudioTrack{trak: trak, track: AudioTrack{Container: "mp4"}}
+
+	if tkhd, ok := findISOBox(moov, start, end, "tkhd"); ok {
+		versionFlags := boxUint(moov, tkhd, 0, 4)
+		track.enabled = versionFlags&1 != 0
+		idOffset := int64(12)
+		if versionFlags>>24 == 1 {
+			idOffset = 20
+		}
+		track.track.ID = int(boxUint(moov, tkhd, idOffset, 4))
+	}
+	if mdhd, ok := findISOBox(moov, start, end, "mdia", "mdhd"); ok {
+		languageOffset := int64(20)
+		if boxUint(moov, mdhd, 0, 1) == 1 {
+			languageOffset = 32
+		}
+		track.track.Language = isoLanguage(uint16(boxUint(moov, mdhd, languageOffset, 2)))
+	}
+	stbl, ok := findISOBox(moov, start, end, "mdia", "minf", "stbl")
+	if !ok {
+		return mp4AudioTrack{}, false, fmt.Errorf("%w: audio track without sample table", ErrUnsupportedVideo)
+	}
+	stblStart, stblEnd := stbl.payload()
+	if stsd, ok := findISOBox(moov, stblStart, stblEnd, "stsd"); ok {
+		track.track.Codec = boxField(moov, stsd, 12, 4)
+	}
+
+	chunkOffsets, err := readChunkOffsets(moov, stblStart, stblEnd)
+	if err != nil {
+		return mp4AudioTrack{}, false, err
+	}
+	track.chunkOffsets = chunkOffsets.box
+	sizes, err := readChunkSizes(moov, stblStart, stblEnd, len(chunkOffsets.offsets), fileSize)
+	if err != nil {
+		return mp4AudioTrack{}, false, err
+	}
+	for i, offset := range chunkOffsets.offsets {
+		track.chunks = append(track.chunks, mp4Chunk{offset: offset, size: sizes[i]})
+	}
+	return track, true, nil
+}
+
+type mp4ChunkOffsets struct {
+	box     isoBoxHeader
+	offsets []int64
+}
+
+func readChunkOffsets(moov *bytes.Reader, start int64, end int64) (mp4ChunkOffsets, error) {
+	box, ok := findISOBox(moov, start, end, "stco")
+	width := int64(4)
+	if !ok {
+		if box, ok = findISOBox(moov, start, end, "co64"); !ok {
+			return mp4ChunkOffsets{}, fmt.Errorf("%w: missing chunk offsets", ErrUnsupportedVideo)
+		}
+		width = 8
+	}
+	count := int64(boxUint(moov, box, 4, 4))
+	if box.headerSize+8+count*width > box.size {
+		return mp4ChunkOffsets{}, fmt.Errorf("%w: truncated chunk offsets", ErrUnsupportedVideo)
+	}
+	offsets := make([]int64, count)
+	for i := range offsets {
+		offsets[i] = int64(boxUint(moov, box, 8+int64(i)*width, width))
+	}
+	return mp4ChunkOffsets{box: box, offsets: offsets}, nil
+}
+
+// readChunkSizes sums the sample sizes of every chunk using the sample-to-chunk and
+// sample size tables. Samples must fit in the file, which bounds the work done for
+// forged tables.
+func readChunkSizes(moov *bytes.Reader, start int64, end int64, chunkCount int, fileSize int64) ([]int64, error) {
+	stsc, ok := findISOBox(moov, start, end, "stsc")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing sample-to-chunk table", ErrUnsupportedVideo)
+	}
+	stsz, ok := findISOBox(moov, start, end, "stsz")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing sample size table", ErrUnsupportedVideo)
+	}
+	runs := int64(boxUint(moov, stsc, 4, 4))
+	if stsc.headerSize+8+runs*12 > stsc.size {
+		return nil, fmt.Errorf("%w: truncated sample-to-chunk table", ErrUnsupportedVideo)
+	}
+	constantSize := int64(boxUint(moov, stsz, 4, 4))
+	sampleCount := int64(boxUint(moov, stsz, 8, 4))
+	if constantSize == 0 && stsz.headerSize+12+sampleCount*4 > stsz.size {
+		return nil, fmt.Errorf("%w: truncated sample size table", ErrUnsupportedVideo)
+	}
+	limit := min(fileSize, math.MaxUint32)
+	if constantSize != 0 && sampleCount > limit/constantSize {
+		return nil, fmt.Errorf("%w: audio track too large", ErrUnsupportedVideo)
+	}
+
+	sizes := make([]int64, chunkCount)
+	sample, total := int64(0), int64(0)
+	for run := int64(0); run < runs; run++ {
+		firstChunk := int64(boxUint(moov, stsc, 8+run*12, 4))
+		samplesPerChunk := int64(boxUint(moov, stsc, 12+run*12, 4))
+		lastChunk := int64(chunkCount)
+		if run+1 < runs {
+			lastChunk = int64(boxUint(moov, stsc, 8+(run+1)*12, 4)) - 1
+		}
+		for chunk := firstChunk; chunk <= lastChunk && chunk >= 1 && chunk <= int64(chunkCount); chunk++ {
+			if samplesPerChunk > sampleCount-sample {
+				return nil, fmt.Errorf("%w: sample tables disagree", ErrUnsupportedVideo)
+			}
+			chunkSize := samplesPerChunk * constantSize
+			if constantSize == 0 {
+				for i := int64(0); i < samplesPerChunk; i++ {
+					chunkSize += int64(boxUint(moov, stsz, 12+(sample+i)*4, 4))
+				}
+			}
+			sample += samplesPerChunk
+			sizes[chunk-1] += chunkSize
+			total += chunkSize
+			if total > limit {
+				return nil, fmt.Errorf("%w: audio track too large", ErrUnsupportedVideo)
+			}
+		}
+	}
+	return sizes, nil
+}
+
+// boxUint reads a big-endian unsigned integer of width bytes at offset in the payload.
+func boxUint(r *bytes.Reader, box isoBoxHeader, offset int64, width int64) uint64 {
+	buf := make([]byte, 8)
+	start, end := box.payload()
+	if start+offset+width > end {
+		return 0
+	}
+	r.ReadAt(buf[8-width:], start+offset)
+	return binary.BigEndian.Uint64(buf)
+}
+
+func boxField(r *bytes.Reader, box isoBoxHeader, offset int64, width int64) string {
+	start, end := box.payload()
+	if start+offset+width > end {
+		return ""
+	}
+	buf := make([]byte, width)
+	r.ReadAt(buf, start+offset)
+	return string(buf)
+}
+
+// isoLanguage decodes a packed ISO 639-2 language code, "und" is returned as empty.
+func isoLanguage(packed uint16) string {
+	code := []byte{
+		byte(packed>>10&0x1F) + 0x60,
+		byte(packed>>5&0x1F) + 0x60,
+		byte(packed&0x1F) + 0x60,
+	}
+	if packed == 0 || string(code) == "und" {
+		return ""
+	}
+	return string(code)
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ObjectMetadata describes a stored audio object and the upload it came from.
+type ObjectMetadata struct {
+	OriginalFilename string  `json:"original_filename,omitempty"`
+	Uploader         string  `json:"uploader,omitempty"`
+	SHA256           string  `json:"sha256,omitempty"`
+	ContentType      string  `json:"content_type,omitempty"`
+	Size             int64   `json:"size,omitempty"`
+	DurationSeconds  float64 `json:"duration_seconds,omitempty"`
+}
+
+// User metadata keys stored on uploaded audio objects.
+const (
+	MetadataOriginalFilename = "Original-Filename"
+	MetadataUploader         = "Uploader"
+	MetadataSHA256           = "Sha256"
+	MetadataDurationSeconds  = "Duration-Seconds"
+)
+
+// ObjectUserMetadata converts object metadata to user metadata headers. Values are
+// percent-encoded because headers only carry ASCII and filenames often do not.
+func ObjectUserMetadata(metadata ObjectMetadata) map[string]string {
+	userMetadata := make(map[string]string)
+	if metadata.OriginalFilename != "" {
+		userMetadata[MetadataOriginalFilename] = url.PathEscape(metadata.OriginalFilename)
+	}
+	if metadata.Uploader != "" {
+		userMetadata[MetadataUploader] = url.PathEscape(metadata.Uploader)
+	}
+	if metadata.SHA256 != "" {
+		userMetadata[MetadataSHA256] = metadata.SHA256
+	}
+	if metadata.DurationSeconds > 0 {
+		userMetadata[MetadataDurationSeconds] = strconv.FormatFloat(metadata.DurationSeconds, 'f', 3, 64)
+	}
+	return userMetadata
+}
+
+// ObjectMetadataFromUser restores object metadata from the user metadata returned by
+// the object store, whose keys may differ in case from the ones written.
+func ObjectMetadataFromUser(contentType string, size int64, userMetadata map[string]string) ObjectMetadata {
+	canonical := make(map[string]string, len(userMetadata))
+	for key, value := range userMetadata {
+		canonical[http.CanonicalHeaderKey(key)] = value
+	}
+	metadata := ObjectMetadata{
+		OriginalFilename: unescapeMetadata(canonical[MetadataOriginalFilename]),
+		Uploader:         unescapeMetadata(canonical[MetadataUploader]),
+		SHA256:           canonical[MetadataSHA256],
+		ContentType:      contentType,
+		Size:             size,
+	}
+	if duration, err := strconv.ParseFloat(canonical[MetadataDurationSeconds], 64); err == nil {
+		metadata.DurationSeconds = duration
+	}
+	return metadata
+}
+
+func unescapeMetadata(value string) string {
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
+
+// ObjectKeyPrefix expands the {date} (YYYY/MM/DD, UTC) and {tenant} (caller ID)
+// placeholders of an object key prefix template. Characters that are unsafe in object
+// keys are replaced in the tenant, and a non-empty prefix always ends with a slash.
+func ObjectKeyPrefix(template string, callerID string, now time.Time) string {
+	if template == "" {
+		return ""
+	}
+	prefix := strings.NewReplacer(
+		"{date}", now.UTC().Format("2006/01/02"),
+		"{tenant}", sanitizeKeySegment(callerID),
+	).Replace(template)
+	prefix = strings.TrimLeft(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+func sanitizeKeySegment(segment string) string {
+	if segment == "" {
+		return AnonymousCaller
+	}
+	sanitized := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, segment)
+	if strings.Trim(sanitized, ".") == "" {
+		// "." and ".." are not valid key segments.
+		return strings.Repeat("_", len(sanitized))
+	}
+	return sanitized
+}
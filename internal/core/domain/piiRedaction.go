@@ -0,0 +1,344 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RedactionReport summarizes the personal data removed from a transcript. The redacted
+// values themselves are never reported.
+type RedactionReport struct {
+	// Categories counts the redacted values of the recognized text by category.
+	Categories map[string]int `json:"categories"`
+	Total      int            `json:"total"`
+}
+
+// Categories of the built-in personal data detectors.
+const (
+	RedactionCategoryEmail = "email"
+	RedactionCategoryCard  = "card_number"
+	RedactionCategoryPhone = "phone_number"
+	RedactionCategoryIBAN  = "iban"
+)
+
+// redactionRule replaces the matches of pattern with a placeholder naming the category.
+// Matches of rules with a validator must not be glued to surrounding letters or digits,
+// so that "order 12345678901234567" is not partly redacted as a phone number. With
+// shrink set, a match is shortened to its longest valid prefix, so that a card number
+// followed by another number is still found.
+type redactionRule struct {
+	category string
+	pattern  *regexp.Regexp
+	validate func(match string) bool
+	shrink   bool
+}
+
+// Redactor removes personal data from transcripts with the built-in detectors for email
+// addresses, IBANs, card numbers and phone numbers, then the custom rules.
+type Redactor struct {
+	rules []redactionRule
+}
+
+// NewRedactor returns a redactor with the built-in detectors and the custom rules, which
+// map a category name to a regular expression.
+func NewRedactor(custom map[string]string) (*Redactor, error) {
+	redactor := &Redactor{rules: []redactionRule{
+		{RedactionCategoryEmail, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), nil, false},
+		{RedactionCategoryIBAN, regexp.MustCompile(`[A-Z]{2}\d{2}(?:[ ]?[A-Z0-9]){11,30}`), validIBAN, true},
+		{RedactionCategoryCard, regexp.MustCompile(`\d(?:[ -]?\d){12,18}`), validCardNumber, true},
+		{RedactionCategoryPhone, regexp.MustCompile(`\+?\(?\d(?:[ ().-]{0,2}\d){7,14}`), validPhoneNumber, false},
+	}}
+
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("redaction rule without a name")
+		}
+		pattern, err := regexp.Compile(custom[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %q: %w", name, err)
+		}
+		if pattern.MatchString("") {
+			return nil, fmt.Errorf("invalid redaction rule %q: matches empty text", name)
+		}
+		redactor.rules = append(redactor.rules, redactionRule{category: name, pattern: pattern})
+	}
+	return redactor, nil
+}
+
+// RedactionPlaceholder is the text replacing a redacted value of the category.
+func RedactionPlaceholder(category string) string {
+	return "[" + strings.ToUpper(category) + "]"
+}
+
+// RedactResult redacts the recognized text, the segments and their words of a
+// transcription, its translation and analysis, and attaches the redaction report. The report
+// counts the values of the original text only.
+func (redactor *Redactor) RedactResult(result RecognitionSuccess) RecognitionSuccess {
+	report := RedactionReport{Categories: make(map[string]int)}
+	var counts map[string]int
+	result.RecognizedText, counts = redactor.RedactText(result.RecognizedText)
+
+	segments := make([]Segment, len(result.Segments))
+	for i, segment := range result.Segments {
+		var segmentCounts map[string]int
+		segment.Text, segmentCounts = redactor.RedactText(segment.Text)
+		if result.RecognizedText == "" {
+			for category, count := range segmentCounts {
+				counts[category] += count
+			}
+		}
+		if len(segment.Words) > 0 {
+			segment.Words = redactor.redactWords(segment.Words)
+		}
+		segments[i] = segment
+	}
+	if result.Segments != nil {
+		result.Segments = segments
+	}
+	if result.Translation != nil {
+		translation := *result.Translation
+		translation.Text, _ = redactor.RedactText(translation.Text)
+		if translation.Segments != nil {
+			translation.Segments = make([]TranslatedSegment, len(result.Translation.Segments))
+			for i, segment := range result.Translation.Segments {
+				segment.Text, _ = redactor.RedactText(segment.Text)
+				translation.Segments[i] = segment
+			}
+		}
+		result.Translation = &translation
+	}
+	if result.Analysis != nil {
+		analysis := *result.Analysis
+		analysis.Summary, _ = redactor.RedactText(analysis.Summary)
+		analysis.Keywords = redactor.redactKeywords(analysis.Keywords)
+		analysis.Keyphrases = redactor.redactKeywords(analysis.Keyphrases)
+		result.Analysis = &analysis
+	}
+
+	for category, count := range counts {
+		report.Categories[category] = count
+		report.Total += count
+	}
+	result.Redaction = &report
+	return result
+}
+
+// redactKeywords returns a copy of keywords with personal data replaced.
+func (redactor *Redactor) redactKeywords(keywords []Keyword) []Keyword {
+	redacted := make([]Keyword, len(keywords))
+	for i, keyword := range keywords {
+		keyword.Text, _ = redactor.RedactText(keyword.Text)
+		redacted[i] = keyword
+	}
+	return redacted
+}
+
+// RedactText replaces the personal data in text and counts the replacements by category.
+func (redactor *Redactor) RedactText(text string) (string, map[string]int) {
+	counts := make(map[string]int)
+	spans := redactor.find(text)
+	if len(spans) == 0 {
+		return text, counts
+	}
+	for _, span := range spans {
+		counts[span.category]++
+	}
+	return applyRedactions(text, spans, 0), counts
+}
+
+type redactionSpan struct {
+	start    int
+	end      int
+	category string
+}
+
+// find returns the non-overlapping, non-empty values to redact in text ordered by
+// position, earlier rules taking precedence.
+func (redactor *Redactor) find(text string) []redactionSpan {
+	var spans []redactionSpan
+	for _, rule := range redactor.rules {
+		for _, loc := range rule.pattern.FindAllStringIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if rule.validate != nil {
+				if start > 0 && isWordByte(text, start-1) {
+					continue
+				}
+				end = validPrefixEnd(text, start, end, rule)
+				if end < 0 {
+					continue
+				}
+			}
+			if end <= start {
+				continue
+			}
+			overlaps := false
+			for _, span := range spans {
+				if start < span.end && end > span.start {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				spans = append(spans, redactionSpan{start: start, end: end, category: rule.category})
+			}
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	return spans
+}
+
+// validPrefixEnd returns the end of the longest valid prefix of the match ending on a
+// letter or digit that is not followed by one, or -1. Without shrink only the whole
+// match is considered.
+func validPrefixEnd(text string, start int, end int, rule redactionRule) int {
+	for last := end; end > start; end-- {
+		if !rule.shrink && end < last {
+			break
+		}
+		if !isWordByte(text, end-1) || (end < len(text) && isWordByte(text, end)) {
+			continue
+		}
+		if rule.validate(text[start:end]) {
+			return end
+		}
+	}
+	return -1
+}
+
+func isWordByte(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	if r == utf8.RuneError {
+		// Inside a multi-byte rune, which is a letter for our purposes.
+		return text[i] >= utf8.RuneSelf
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// applyRedactions replaces the spans, given relative to text shifted by offset.
+func applyRedactions(text string, spans []redactionSpan, offset int) string {
+	var out strings.Builder
+	last := 0
+	for _, span := range spans {
+		out.WriteString(text[last : span.start-offset])
+		out.WriteString(RedactionPlaceholder(span.category))
+		last = span.end - offset
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// redactWords redacts the words of a segment. A value spread over several words is
+// replaced by a single word spanning their timings.
+func (redactor *Redactor) redactWords(words []Word) []Word {
+	text := joinWords(words)
+	spans := redactor.find(text)
+	if len(spans) == 0 {
+		return words
+	}
+
+	starts := make([]int, len(words)+1)
+	for i, word := range words {
+		starts[i+1] = starts[i] + len(word.Word)
+	}
+	redacted := make([]Word, 0, len(words))
+	next := 0
+	for i := 0; i < len(words); {
+		// Group the words touched by consecutive spans.
+		j := i
+		var group []redactionSpan
+		for next < len(spans) && spans[next].start < starts[j+1] {
+			if spans[next].end <= starts[i] {
+				next++
+				continue
+			}
+			group = append(group, spans[next])
+			for starts[j+1] < spans[next].end {
+				j++
+			}
+			next++
+		}
+		if len(group) == 0 {
+			redacted = append(redacted, words[i])
+			i++
+			continue
+		}
+		word := words[i]
+		word.Word = applyRedactions(text[starts[i]:starts[j+1]], group, starts[i])
+		word.End = words[j].End
+		for _, merged := range words[i+1 : j+1] {
+			word.Probability = min(word.Probability, merged.Probability)
+			word.LowConfidence = word.LowConfidence || merged.LowConfidence
+		}
+		redacted = append(redacted, word)
+		i = j + 1
+	}
+	return redacted
+}
+
+// validCardNumber checks the length and Luhn checksum of a card number.
+func validCardNumber(match string) bool {
+	digits := digitsOf(match)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		digit := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// validPhoneNumber accepts 10 to 15 digits, or 8 with an international prefix.
+func validPhoneNumber(match string) bool {
+	digits := len(digitsOf(match))
+	minDigits := 10
+	if strings.HasPrefix(match, "+") {
+		minDigits = 8
+	}
+	return digits >= minDigits && digits <= 15 && strings.Count(match, "(") == strings.Count(match, ")")
+}
+
+// validIBAN checks the length and the ISO 13616 mod-97 checksum of an IBAN.
+func validIBAN(match string) bool {
+	compact := strings.ReplaceAll(match, " ", "")
+	if len(compact) < 15 || len(compact) > 34 {
+		return false
+	}
+	var numeric strings.Builder
+	for _, r := range compact[4:] + compact[:4] {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&numeric, "%d", r-'A'+10)
+		} else {
+			numeric.WriteRune(r)
+		}
+	}
+	value, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(value, big.NewInt(97)).Int64() == 1
+}
+
+func digitsOf(match string) string {
+	var digits strings.Builder
+	for _, r := range match {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Post-processing steps, run in this order after Whisper.
+const (
+	PostProcessDedupe     = "dedupe"
+	PostProcessVocabulary = "vocabulary"
+	PostProcessNumbers    = "numbers"
+	PostProcessProfanity  = "profanity"
+	// PostProcessNone disables post-processing when requested alone.
+	PostProcessNone = "none"
+)
+
+var postProcessSteps = []string{PostProcessDedupe, PostProcessVocabulary, PostProcessNumbers, PostProcessProfanity}
+
+// PostProcessConfig holds the data of the configurable post-processing steps.
+type PostProcessConfig struct {
+	// Vocabulary maps phrases, matched case-insensitively on whole words, to their
+	// replacement.
+	Vocabulary map[string]string
+	// ProfanityWords are masked in addition to the built-in lists.
+	ProfanityWords []string
+}
+
+// ParsePostProcessSteps validates the requested steps and returns them in the order they
+// run, without duplicates. "none" alone selects no step.
+func ParsePostProcessSteps(requested []string) ([]string, error) {
+	steps := []string{}
+	for _, step := range requested {
+		step = strings.ToLower(strings.TrimSpace(step))
+		switch {
+		case step == "":
+		case step == PostProcessNone:
+			if len(requested) > 1 {
+				return nil, fmt.Errorf("invalid 'postprocess', %q cannot be combined with other steps", PostProcessNone)
+			}
+		case !slices.Contains(postProcessSteps, step):
+			return nil, fmt.Errorf("invalid 'postprocess' step %q, expected one of %s", step, strings.Join(postProcessSteps, ", "))
+		case !slices.Contains(steps, step):
+			steps = append(steps, step)
+		}
+	}
+	slices.SortFunc(steps, func(a, b string) int {
+		return slices.Index(postProcessSteps, a) - slices.Index(postProcessSteps, b)
+	})
+	return steps, nil
+}
+
+// PostProcess runs the steps, as returned by ParsePostProcessSteps, over a transcription
+// in the given language and records them in the result. Steps rewrite the words of the
+// segments, keeping their timings, and the recognized text is rebuilt from the segments.
+func PostProcess(result RecognitionSuccess, steps []string, language string, config PostProcessConfig) RecognitionSuccess {
+	for _, step := range steps {
+		switch step {
+		case PostProcessDedupe:
+			result = RemoveRepetitions(result)
+		case PostProcessVocabulary:
+			result = ReplaceVocabulary(result, config.Vocabulary)
+		case PostProcessNumbers:
+			result = NormalizeNumbers(result, language)
+		case PostProcessProfanity:
+			result = MaskProfanity(result, language, config.ProfanityWords)
+		}
+	}
+	if len(steps) > 0 {
+		result.PostProcessing = steps
+	}
+	return result
+}
+
+// textWordPattern splits text without word timings into words with their leading spaces,
+// like the words returned by Whisper.
+var textWordPattern = regexp.MustCompile(`\s*\S+`)
+
+// textWords returns the words of text with zero timings.
+func textWords(text string) []Word {
+	matches := textWordPattern.FindAllString(text, -1)
+	words := make([]Word, len(matches))
+	for i, match := range matches {
+		words[i] = Word{Word: match}
+	}
+	return words
+}
+
+// mapWords applies fn to the words of every segment, or of the recognized text when the
+// transcription has no segments. Segments without word timings are split into words
+// first. The recognized text is rebuilt from the segments when one of them changed.
+func mapWords(result RecognitionSuccess, fn func(words []Word) ([]Word, bool)) RecognitionSuccess {
+	if len(result.Segments) == 0 {
+		if words, changed := fn(textWords(result.RecognizedText)); changed {
+			result.RecognizedText = strings.TrimSpace(joinWords(words))
+		}
+		return result
+	}
+
+	segments := make([]Segment, len(result.Segments))
+	anyChanged := false
+	for i, segment := range result.Segments {
+		words := segment.Words
+		if len(words) == 0 {
+			words = textWords(segment.Text)
+		}
+		if words, changed := fn(words); changed {
+			if len(segment.Words) > 0 {
+				segment.Words = words
+			}
+			segment.Text = joinWords(words)
+			anyChanged = true
+		}
+		segments[i] = segment
+	}
+	result.Segments = segments
+	if anyChanged {
+		result.RecognizedText = segmentsText(segments)
+	}
+	return result
+}
+
+func segmentsText(segments []Segment) string {
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.Text
+	}
+	return strings.TrimSpace(strings.Join(texts, ""))
+}
+
+// mergeWords replaces words[start:end] by one word with the given core text, keeping the
+// leading space and punctuation of the first word, the trailing punctuation of the last
+// one and the timings of both.
+func mergeWords(words []Word, start int, end int, core string) Word {
+	merged := words[start]
+	last := words[end-1]
+	leading := merged.Word[:len(merged.Word)-len(strings.TrimLeftFunc(merged.Word, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}))]
+	merged.Word = leading + core + trailingPunctuation(last.Word)
+	merged.End = last.End
+	for _, word := range words[start+1 : end] {
+		merged.Probability = min(merged.Probability, word.Probability)
+		merged.LowConfidence = merged.LowConfidence || word.LowConfidence
+	}
+	return merged
+}
+
+func trailingPunctuation(word string) string {
+	trimmed := strings.TrimRightFunc(word, unicode.IsPunct)
+	return word[len(trimmed):]
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// profanityPatterns match whole lower-cased words. Russian obscenities are matched by
+// their roots with the usual prefixes and endings, avoiding words such as "хуже".
+var profanityPatterns = map[string]*regexp.Regexp{
+	"en": regexp.MustCompile(`^(?:(?:mother)?fuck(?:s|ed|er|ers|in|ing)?|(?:bull)?shit(?:s|ty|ting)?|bitch(?:es|y)?|cunts?|assholes?|bastards?)$`),
+	"ru": regexp.MustCompile(`^(?:(?:за|на|по|от|вы|при|у|с|раз|)ху[йеёияю]\p{L}*|\p{L}*пизд\p{L}*|(?:за|на|по|от|вы|у|съ|раз|до|)[её]б(?:ать|ал\p{L}*|ан\p{L}*|у\p{L}*|[её]т\p{L}*|ли|нут\p{L}*)|бля(?:дь|ди|ть)?|бляд\p{L}*|сук(?:а|и|у|ой|е)|муда[кч]\p{L}*|мудил\p{L}*)$`),
+}
+
+// MaskProfanity masks the profanity of the transcription language and the extra words,
+// keeping the first letter: "shit" becomes "s***".
+func MaskProfanity(result RecognitionSuccess, language string, extra []string) RecognitionSuccess {
+	pattern := profanityPatterns[language]
+	extraWords := make(map[string]bool, len(extra))
+	for _, word := range extra {
+		if word = normalizeWord(word); word != "" {
+			extraWords[word] = true
+		}
+	}
+	if pattern == nil && len(extraWords) == 0 {
+		return result
+	}
+
+	return mapWords(result, func(words []Word) ([]Word, bool) {
+		masked := make([]Word, len(words))
+		changed := false
+		for i, word := range words {
+			core := strings.ReplaceAll(normalizeWord(word.Word), "ё", "е")
+			if core != "" && (extraWords[core] || (pattern != nil && pattern.MatchString(core))) {
+				word.Word = maskWord(word.Word)
+				changed = true
+			}
+			masked[i] = word
+		}
+		return masked, changed
+	})
+}
+
+// maskWord replaces every letter but the first one with an asterisk.
+func maskWord(word string) string {
+	var masked strings.Builder
+	seenLetter := false
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			if seenLetter {
+				r = '*'
+			}
+			seenLetter = true
+		}
+		masked.WriteRune(r)
+	}
+	return masked.String()
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxRepeatedPhrase is the longest phrase, in words, collapsed when repeated.
+	maxRepeatedPhrase = 8
+	// minRepeats is how many consecutive occurrences of a phrase make a repetition loop.
+	minRepeats = 3
+	// minRepeatedSegmentWords keeps short segments such as "Yes." repeated by two speakers.
+	minRepeatedSegmentWords = 3
+)
+
+// hallucinatedCredits are fragments of subtitle credits Whisper produces on silence or
+// music, learned from its training data.
+var hallucinatedCredits = []string{
+	"amara org",
+	"субтитры сделал",
+	"субтитры создавал",
+	"субтитры подогнал",
+	"редактор субтитров",
+}
+
+// hallucinatedEndings are produced on trailing silence and only removed from the last
+// segment, since they may also be spoken.
+var hallucinatedEndings = map[string]bool{
+	"thanks for watching":     true,
+	"thank you for watching":  true,
+	"спасибо за просмотр":     true,
+	"продолжение следует":     true,
+	"благодарю за просмотр":   true,
+	"thank you for listening": true,
+}
+
+// RemoveRepetitions removes the repetition loops and phrases Whisper hallucinates:
+// phrases of up to 8 words repeated 3 times or more in a row are kept once, segments
+// repeating the previous one are dropped, and so are subtitle credits and, at the end of
+// the transcription, sign-offs such as "Thanks for watching!".
+func RemoveRepetitions(result RecognitionSuccess) RecognitionSuccess {
+	if len(result.Segments) > 0 {
+		kept := make([]Segment, 0, len(result.Segments))
+		previous := ""
+		for i, segment := range result.Segments {
+			text := normalizePhrase(segment.Text)
+			if isHallucinatedCredit(text) || (i == len(result.Segments)-1 && hallucinatedEndings[text]) {
+				continue
+			}
+			if text != "" && text == previous && len(strings.Fields(text)) >= minRepeatedSegmentWords {
+				continue
+			}
+			previous = text
+			kept = append(kept, segment)
+		}
+		if len(kept) != len(result.Segments) {
+			for i := range kept {
+				kept[i].ID = i
+			}
+			result.Segments = kept
+			result.RecognizedText = segmentsText(kept)
+		}
+	}
+	return mapWords(result, collapseRepeatedPhrases)
+}
+
+// collapseRepeatedPhrases keeps one occurrence of every phrase repeated minRepeats times
+// or more in a row, trying the shortest phrases first.
+func collapseRepeatedPhrases(words []Word) ([]Word, bool) {
+	normalized := make([]string, len(words))
+	for i, word := range words {
+		normalized[i] = normalizeWord(word.Word)
+	}
+
+	kept := make([]Word, 0, len(words))
+	for i := 0; i < len(words); {
+		repeated := false
+		for n := 1; n <= maxRepeatedPhrase && i+minRepeats*n <= len(words); n++ {
+			repeats := 1
+			for i+(repeats+1)*n <= len(words) && sameWords(normalized[i:i+n], normalized[i+repeats*n:i+(repeats+1)*n]) {
+				repeats++
+			}
+			if repeats >= minRepeats && normalized[i] != "" {
+				kept = append(kept, words[i:i+n]...)
+				i += repeats * n
+				repeated = true
+				break
+			}
+		}
+		if !repeated {
+			kept = append(kept, words[i])
+			i++
+		}
+	}
+	return kept, len(kept) != len(words)
+}
+
+// normalizePhrase lower-cases text and replaces punctuation with single spaces.
+func normalizePhrase(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func isHallucinatedCredit(text string) bool {
+	for _, credit := range hallucinatedCredits {
+		if strings.Contains(text, credit) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID stores the request ID in ctx.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,7 @@
+package domain
+
+// ResultCacheKey identifies a transcription by the content hash of the audio and the
+// canonical form of the transcription options it was produced with.
+func ResultCacheKey(digest string, optionsKey string) string {
+	return digest + ":" + optionsKey
+}
@@ -0,0 +1,8 @@
+package domain
+
+// ScanResult is the verdict of a content scanner.
+type ScanResult struct {
+	Infected bool
+	// Signature names the detected threat of infected content.
+	Signature string
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// SearchQuery describes a full-text search over stored transcripts. Only transcripts
+// of Tenant match, the empty ID being the default tenant.
+type SearchQuery struct {
+	Text     string
+	Language string
+	Tenant   string
+	Offset   int
+	Limit    int
+}
+
+// SearchHit is a transcript matching a search, with highlighted excerpts.
+// Highlights are HTML-escaped with matches wrapped in <mark> tags.
+type SearchHit struct {
+	ID           string    `json:"id"`
+	Score        float64   `json:"score"`
+	DetectedLang string    `json:"detected_language"`
+	CreatedAt    time.Time `json:"created_at"`
+	Highlights   []string  `json:"highlights"`
+}
+
+// SearchResult is one page of hits ordered by relevance.
+type SearchResult struct {
+	Hits       []SearchHit `json:"hits"`
+	Total      int         `json:"total"`
+	NextOffset *int        `json:"next_offset,omitempty"`
+}
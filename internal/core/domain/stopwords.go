@@ -0,0 +1,87 @@
+package domain
+
+import "strings"
+
+// stopwordLists hold the function words and speech fillers of the most common languages
+// Whisper detects, which carry no topic and are left out of summaries and keywords.
+var stopwordLists = map[string]string{
+	"en": `a about above after again against all also am an and any are aren't as at be because been before being
+		below between both but by can can't cannot could couldn't did didn't do does doesn't doing don't down during
+		each even few for from further get gets getting go goes going gonna got had hadn't has hasn't have haven't
+		having he he'd he'll he's her here here's hers herself him himself his how how's i i'd i'll i'm i've if in
+		into is isn't it it's its itself just know let's like make many may maybe me might more most much must
+		mustn't my myself need no nor not now of off okay ok on once one only or other ought our ours ourselves out
+		over own really right said same say says see shall shan't she she'd she'll she's should shouldn't so some
+		something such take than that that's the their theirs them themselves then there there's these they they'd
+		they'll they're they've thing things think this those though through to too two uh um under until up upon
+		us very wanna want was wasn't way we we'd we'll we're we've well were weren't what what's when when's where
+		where's whether which while who who's whom why why's will with won't would wouldn't yeah yes yet you you'd
+		you'll you're you've your yours yourself yourselves still
+		everyone everybody anyone anything everything nothing thanks thank please sure actually basically kind sort`,
+	"ru": `а без более бы был была были было быть в вам вас весь во вот все всего всех всё вы где да даже для до его
+		ее её если есть еще ещё же за здесь и из или им их к как какая какой когда кто ли либо мне может мы на над
+		надо наш не него нее неё нет ни них но ну о об однако он она они оно от очень по под после потом потому при
+		про раз с сам свой себе себя со так также такой там те тем то того тоже той только том ты у уже хотя чего
+		чей чем что чтобы чье эта эти это этого этой этом этот я вообще вроде значит короче просто типа кстати ладно
+		хорошо сейчас тут вот какие который которая которые которых можно нужно будет будут было будем есть эм ээ ага всем всеми
+		спасибо пожалуйста давайте сегодня`,
+	"uk": `а або але б без би був була були було бути в вам вас весь від вони воно все всі вже ви де для до є його
+		її з за і й із інших їх к коли котрий котра котрі лише майже мене ми мені на над навіть не неї нема немає ні
+		ну о об один однак він вона по під після при про раз с так також там те теж тим то тобто того той тому ти
+		тут у хоча це цей ця ці цього цієї цим через що щоб як яка який які я вже ось просто значить ну от`,
+	"de": `aber alle allem allen aller alles als also am an ander andere anderen auch auf aus bei bin bis bist da
+		damit dann das dass dem den denn der des dich die dir doch dort du durch ein eine einem einen einer eines
+		er es etwas euch euer für gegen gewesen hab habe haben hat hatte hier hin ich ihm ihn ihnen ihr ihre im in
+		ins ist ja jede jeder jetzt kann kein keine können man mein meine mich mir mit muss nach nicht nichts noch
+		nun nur ob oder ohne schon sehr sein seine sich sie sind so soll über um und uns unser unter viel vom von
+		vor war waren warum was weil weiter welche wenn wer werden wie wieder will wir wird wo wollen würde zu zum
+		zur ähm äh halt mal eben genau`,
+	"fr": `a ai alors au aussi autre aux avec avoir bien c ça car ce cela ces cet cette ceux chaque comme comment d
+		dans de des donc du elle elles en encore est et étaient était être eu fait faire il ils j je juste l la là
+		le les leur leurs lui m ma mais me même mes moi mon n ne ni nos notre nous on ont ou où par parce pas peu
+		peut plus pour pourquoi qu quand que quel quelle qui s sa sans se ses si son sont sur t ta te tes toi ton
+		tous tout toute toutes très tu un une vos votre vous y euh bon voilà hein`,
+	"es": `a al algo algunos ante antes así aun aunque bien cada como con contra cual cuando de del desde donde dos
+		el ella ellas ellos en entre era eres es esa ese eso esta está están este esto estos fue fueron ha había
+		han hasta hay la las le les lo los más me mi mientras mis mucho muy nada ni no nos nosotros o otra otro
+		para pero poco por porque que qué se sea ser si sí sin sobre son su sus también tan te tener tiene todo
+		todos tu tú un una uno unos usted y ya yo eh este pues bueno vale`,
+	"it": `a abbiamo ad al alla alle allora anche ancora avere c che chi ci come con cosa così da dal dalla dei del
+		della delle di dove e è ed era essere fa gli ha hanno ho i il in io la le lei li lo loro lui ma mi mia mio
+		molto ne nei nel nella no noi non o per perché più può qua quale quando quello questa questo qui se sei si
+		sia siamo sono su sua sue suo sul sulla tu tutti tutto un una uno voi vi allora cioè ecco ehm beh`,
+	"pt": `a ao aos aquela aquele aquilo as até com como da das de dela dele deles depois do dos e é ela elas ele
+		eles em entre era essa esse isso esta está estão este eu foi foram há isto já la lhe mais mas me mesmo meu
+		minha muito na não nas nem no nos nós o os ou para pela pelo por porque quando que quem se sem ser seu sua
+		são também te tem ter teu tu um uma você vocês né tipo então assim`,
+	"nl": `aan al alles als altijd ben bij daar dan dat de der deze die dit doch doen door dus een en er ga geen
+		geweest haar had heb hebben heeft hem het hier hij hoe hun iemand ik in is ja je kan kon kunnen maar me
+		meer men met mij mijn moet na naar niet niets nog nu of om omdat ons ook op over reeds te tegen toch toen
+		tot u uit uw van veel voor want waren was wat we wel werd wie wij wil worden zal ze zei zelf zich zij zijn
+		zo zonder zou eh nou dus gewoon`,
+	"pl": `a aby ale bardzo bez bo by był była były było być co czy dla do gdy gdzie go i ich ile im innych jak
+		jakie jako je jego jej jest jestem jeszcze jeśli już ją każdy kiedy kto która które którzy ma mam mi mnie
+		może można mu my na nad nam nas nie nic nich nim no o od oraz po pod przez przy również się są ta tak
+		także tam te tego tej ten też to tu tylko tym u w we więc właśnie wszystko z za że żeby no yyy znaczy`,
+}
+
+// stopwords are the parsed stopwordLists, keyed by language.
+var stopwords = parseStopwordLists(stopwordLists)
+
+func parseStopwordLists(lists map[string]string) map[string]map[string]bool {
+	parsed := make(map[string]map[string]bool, len(lists))
+	for language, list := range lists {
+		words := make(map[string]bool)
+		for _, word := range strings.Fields(list) {
+			words[strings.ReplaceAll(word, "ё", "е")] = true
+		}
+		parsed[language] = words
+	}
+	return parsed
+}
+
+// IsStopword reports whether a lower-cased word is a stopword of the language. Languages
+// without a list have no stopwords.
+func IsStopword(language string, word string) bool {
+	return stopwords[language][strings.ReplaceAll(word, "ё", "е")]
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Subtitle formats a transcript can be rendered in.
+const (
+	SubtitleFormatSRT = "srt"
+	SubtitleFormatVTT = "vtt"
+)
+
+// RenderSRT renders the segments as SubRip cues, prefixing the text with the speaker
+// when the segment has one.
+func RenderSRT(segments []Segment) string {
+	var out strings.Builder
+	for i, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if segment.Speaker != "" {
+			text = segment.Speaker + ": " + text
+		}
+		fmt.Fprintf(&out, "%d\n%s --> %s\n%s\n\n", i+1, subtitleTimestamp(segment.Start, ","), subtitleTimestamp(segment.End, ","), text)
+	}
+	return out.String()
+}
+
+// RenderVTT renders the segments as a WebVTT file, attributing the text of segments with
+// a speaker to a voice span named after it.
+func RenderVTT(segments []Segment) string {
+	var out strings.Builder
+	out.WriteString("WEBVTT\n\n")
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if segment.Speaker != "" {
+			text = "<v " + segment.Speaker + ">" + text
+		}
+		fmt.Fprintf(&out, "%s --> %s\n%s\n\n", subtitleTimestamp(segment.Start, "."), subtitleTimestamp(segment.End, "."), text)
+	}
+	return out.String()
+}
+
+// subtitleTimestamp formats seconds as hh:mm:ss followed by the separator and milliseconds.
+func subtitleTimestamp(seconds float64, separator string) string {
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, separator, ms%1000)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrFileTooLarge is returned when buffered content exceeds its size limit.
+var ErrFileTooLarge = errors.New("file exceeds the size limit")
+
+// TempFile is content buffered on disk. Closing it removes the file.
+type TempFile struct {
+	*os.File
+	Size int64
+}
+
+func (file *TempFile) Close() error {
+	err := file.File.Close()
+	os.Remove(file.File.Name())
+	return err
+}
+
+// BufferToTempFile copies at most maxBytes from r into a temporary file rewound to its
+// start, returning ErrFileTooLarge when r holds more.
+func BufferToTempFile(r io.Reader, maxBytes int64) (*TempFile, error) {
+	tmp, err := os.CreateTemp("", "sr-api-*")
+	if err != nil {
+		return nil, err
+	}
+	file := &TempFile{File: tmp}
+	file.Size, err = io.Copy(tmp, io.LimitReader(r, maxBytes+1))
+	if err == nil && file.Size > maxBytes {
+		err = ErrFileTooLarge
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+)
+
+// DefaultTenantName reports the default tenant, which has an empty ID, in logs and errors.
+const DefaultTenantName = "default"
+
+// tenantPrefixRoot holds the objects of tenants sharing the default bucket without a
+// prefix of their own, each under tenantPrefixRoot + ID + "/".
+const tenantPrefixRoot = "tenants/"
+
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`)
+
+// Tenant isolates the audio, transcripts and results of a group of callers.
+type Tenant struct {
+	// ID is empty for the default tenant, which serves callers no tenant claims.
+	ID string `json:"-"`
+	// Bucket holds the audio and transcripts of the tenant, under Prefix.
+	Bucket string `json:"bucket,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	// WhisperEndpoint overrides the configured Whisper service when set.
+	WhisperEndpoint string `json:"whisper_endpoint,omitempty"`
+	// Callers are the names of the API keys belonging to the tenant.
+	Callers []string `json:"callers,omitempty"`
+	// SourceBuckets lists the buckets existing objects may be transcribed from.
+	SourceBuckets []string    `json:"source_buckets,omitempty"`
+	Quota         TenantQuota `json:"quota"`
+}
+
+// TenantQuota bounds what a tenant stores per UTC day. Zero values are unlimited.
+type TenantQuota struct {
+	UploadsPerDay int   `json:"uploads_per_day,omitempty"`
+	BytesPerDay   int64 `json:"bytes_per_day,omitempty"`
+}
+
+// Name returns the tenant ID, or DefaultTenantName for the default tenant.
+func (tenant Tenant) Name() string {
+	if tenant.ID == "" {
+		return DefaultTenantName
+	}
+	return tenant.ID
+}
+
+// TenantRegistry maps callers to the tenant they belong to.
+type TenantRegistry struct {
+	defaultTenant Tenant
+	tenants       map[string]Tenant
+	callers       map[string]string
+}
+
+// NewTenantRegistry validates the configured tenants, keyed by ID, and fills in their
+// storage: tenants without a bucket share the bucket of the default tenant, under
+// tenants/<id>/ unless they set a prefix. Every caller in apiKeyCallers not claimed by
+// a configured tenant gets a tenant of its own named after it; apiKeyCallers is nil
+// when tenants are not derived from API keys.
+func NewTenantRegistry(defaultTenant Tenant, tenants map[string]Tenant, apiKeyCallers []string) (*TenantRegistry, error) {
+	defaultTenant.ID, defaultTenant.Prefix = "", ""
+	registry := &TenantRegistry{
+		defaultTenant: defaultTenant,
+		tenants:       make(map[string]Tenant),
+		callers:       make(map[string]string),
+	}
+	for id, tenant := range tenants {
+		if err := validateTenantID(id); err != nil {
+			return nil, err
+		}
+		tenant.ID = id
+		registry.tenants[id] = registry.withStorage(tenant)
+		for _, caller := range tenant.Callers {
+			if other, ok := registry.callers[caller]; ok {
+				return nil, fmt.Errorf("caller %s belongs to tenants %s and %s", caller, other, id)
+			}
+			registry.callers[caller] = id
+		}
+	}
+	for _, caller := range apiKeyCallers {
+		if _, ok := registry.callers[caller]; ok {
+			continue
+		}
+		if _, ok := registry.tenants[caller]; !ok {
+			if err := validateTenantID(caller); err != nil {
+				return nil, fmt.Errorf("cannot derive a tenant from API key name: %w", err)
+			}
+			registry.tenants[caller] = registry.withStorage(Tenant{ID: caller})
+		}
+		registry.callers[caller] = caller
+	}
+
+	all := registry.All()[1:]
+	for i, tenant := range all {
+		for _, other := range all[i+1:] {
+			if tenant.Bucket == other.Bucket && (strings.HasPrefix(tenant.Prefix, other.Prefix) || strings.HasPrefix(other.Prefix, tenant.Prefix)) {
+				return nil, fmt.Errorf("tenants %s and %s share storage in bucket %s", tenant.ID, other.ID, tenant.Bucket)
+			}
+		}
+	}
+	return registry, nil
+}
+
+func validateTenantID(id string) error {
+	if !tenantIDPattern.MatchString(id) || id == DefaultTenantName {
+		return fmt.Errorf("invalid tenant ID %q, expected letters, digits, '.', '_' or '-' and not %q", id, DefaultTenantName)
+	}
+	return nil
+}
+
+// withStorage fills in the bucket and prefix a tenant stores its objects under.
+func (registry *TenantRegistry) withStorage(tenant Tenant) Tenant {
+	if tenant.Bucket == "" {
+		tenant.Bucket = registry.defaultTenant.Bucket
+	}
+	tenant.Prefix = strings.TrimLeft(tenant.Prefix, "/")
+	if tenant.Prefix != "" && !strings.HasSuffix(tenant.Prefix, "/") {
+		tenant.Prefix += "/"
+	}
+	if tenant.Prefix == "" && tenant.Bucket == registry.defaultTenant.Bucket {
+		tenant.Prefix = tenantPrefixRoot + tenant.ID + "/"
+	}
+	return tenant
+}
+
+// Resolve returns the tenant of a caller. Callers with an unknown or no API key always
+// belong to the default tenant.
+func (registry *TenantRegistry) Resolve(caller Caller) Tenant {
+	if caller.Known {
+		if id, ok := registry.callers[caller.ID]; ok {
+			return registry.tenants[id]
+		}
+	}
+	return registry.defaultTenant
+}
+
+// All returns the default tenant followed by the other tenants ordered by ID.
+func (registry *TenantRegistry) All() []Tenant {
+	tenants := make([]Tenant, 0, len(registry.tenants)+1)
+	for _, tenant := range registry.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return append([]Tenant{registry.defaultTenant}, tenants...)
+}
+
+// Buckets returns the distinct buckets tenants store objects in, the default one first.
+func (registry *TenantRegistry) Buckets() []string {
+	var buckets []string
+	for _, tenant := range registry.All() {
+		if !slices.Contains(buckets, tenant.Bucket) {
+			buckets = append(buckets, tenant.Bucket)
+		}
+	}
+	return buckets
+}
+
+// TenantOfObject returns the tenant whose storage holds an object. Objects of the
+// default bucket outside any tenant prefix belong to the default tenant; the second
+// return value is false for objects no tenant owns.
+func (registry *TenantRegistry) TenantOfObject(bucket string, key string) (Tenant, bool) {
+	// Storage never overlaps, at most one tenant matches.
+	for _, tenant := range registry.tenants {
+		if tenant.Bucket == bucket && strings.HasPrefix(key, tenant.Prefix) {
+			return tenant, true
+		}
+	}
+	return registry.defaultTenant, bucket == registry.defaultTenant.Bucket
+}
+
+// MayTranscribeFrom reports whether a tenant, by ID, may transcribe an existing object:
+// the bucket must be one of its source buckets and the object must not be stored by
+// another tenant.
+func (registry *TenantRegistry) MayTranscribeFrom(tenantID string, bucket string, key string) bool {
+	tenant, ok := registry.tenants[tenantID]
+	if tenantID == "" {
+		tenant, ok = registry.defaultTenant, true
+	}
+	if !ok || !slices.Contains(tenant.SourceBuckets, bucket) {
+		return false
+	}
+	owner, owned := registry.TenantOfObject(bucket, key)
+	return !owned || owner.ID == tenant.ID
+}
+
+type tenantContextKey struct{}
+
+// ContextWithTenant stores the tenant in ctx and attributes the spans, metrics and log
+// lines derived from ctx to it.
+func ContextWithTenant(ctx context.Context, tenant Tenant) context.Context {
+	ctx = context.WithValue(ctx, tenantContextKey{}, tenant)
+	return telemetry.ContextWithTenant(ctx, tenant.ID)
+}
+
+// TenantFromContext returns the tenant stored in ctx. Without one, the zero Tenant is
+// the default tenant using the configured bucket.
+func TenantFromContext(ctx context.Context) Tenant {
+	tenant, _ := ctx.Value(tenantContextKey{}).(Tenant)
+	return tenant
+}
+
+// TenantContentKey scopes a content hash to a tenant, so that deduplication and cached
+// results are never shared between tenants.
+func TenantContentKey(tenantID string, digest string) string {
+	if tenantID == "" {
+		return digest
+	}
+	return tenantID + "/" + digest
+}
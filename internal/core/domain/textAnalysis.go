@@ -0,0 +1,307 @@
+package domain
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Analysis is an overview of a transcript: a summary with its keywords and keyphrases.
+type Analysis struct {
+	Summary string `json:"summary"`
+	// SummaryMethod is "extractive" for sentences picked from the transcript or "llm"
+	// for a summary written by the configured summarizer.
+	SummaryMethod string    `json:"summary_method"`
+	Keywords      []Keyword `json:"keywords"`
+	Keyphrases    []Keyword `json:"keyphrases"`
+}
+
+// Keyword is a keyword or keyphrase with its relevance, 1 for the most relevant one.
+type Keyword struct {
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+// Summary methods reported in an analysis.
+const (
+	SummaryMethodExtractive = "extractive"
+	SummaryMethodLLM        = "llm"
+)
+
+const (
+	// textRankDamping and the convergence threshold of the TextRank iteration.
+	textRankDamping   = 0.85
+	textRankEpsilon   = 1e-6
+	textRankMaxRounds = 100
+	// maxKeyphraseWords bounds the length of keyphrase candidates.
+	maxKeyphraseWords = 4
+)
+
+// AnalysisConfig bounds the summary and keyword lists of an analysis.
+type AnalysisConfig struct {
+	// SummarySentences is the maximum number of sentences of a summary.
+	SummarySentences int
+	// Keywords is the maximum number of keywords and of keyphrases.
+	Keywords int
+}
+
+// Analyze summarizes a transcription in the given language by picking its most central
+// sentences with TextRank, and extracts its keywords by TF-IDF over the sentences and
+// its keyphrases from runs of keywords between stopwords.
+func Analyze(result RecognitionSuccess, language string, config AnalysisConfig) Analysis {
+	sentences := transcriptSentences(result)
+	runs := make([][][]string, len(sentences))
+	words := make([][]string, len(sentences))
+	for i, sentence := range sentences {
+		runs[i] = contentRuns(sentence, language)
+		for _, run := range runs[i] {
+			words[i] = append(words[i], run...)
+		}
+	}
+
+	scores := textRank(words)
+	count := SummaryLength(len(sentences), config.SummarySentences)
+	picked := make([]int, len(sentences))
+	for i := range picked {
+		picked[i] = i
+	}
+	slices.SortStableFunc(picked, func(a, b int) int {
+		return compareScores(scores[a], scores[b])
+	})
+	picked = picked[:count]
+	slices.Sort(picked)
+	summary := make([]string, len(picked))
+	for i, index := range picked {
+		summary[i] = sentences[index]
+	}
+
+	wordScores := tfidf(words)
+	phraseScores := make(map[string]float64)
+	for _, sentenceRuns := range runs {
+		for _, run := range sentenceRuns {
+			if len(run) < 2 || len(run) > maxKeyphraseWords {
+				continue
+			}
+			var score float64
+			for _, word := range run {
+				score += wordScores[word]
+			}
+			phraseScores[strings.Join(run, " ")] += score / float64(len(run))
+		}
+	}
+
+	return Analysis{
+		Summary:       strings.Join(summary, " "),
+		SummaryMethod: SummaryMethodExtractive,
+		Keywords:      topKeywords(wordScores, config.Keywords),
+		Keyphrases:    topKeywords(phraseScores, config.Keywords),
+	}
+}
+
+// SummaryLength is the number of sentences of the summary of a text: a third of its
+// sentences, at least one and at most the configured maximum.
+func SummaryLength(sentences int, maxSentences int) int {
+	if sentences == 0 {
+		return 0
+	}
+	return max(1, min(maxSentences, (sentences+2)/3))
+}
+
+// transcriptSentences splits the recognized text into sentences. Without sentence
+// punctuation, the segments are used as sentences instead.
+func transcriptSentences(result RecognitionSuccess) []string {
+	sentences := SplitSentences(result.RecognizedText)
+	if len(sentences) > 1 || len(result.Segments) < 2 {
+		return sentences
+	}
+	sentences = sentences[:0]
+	for _, segment := range result.Segments {
+		if text := strings.TrimSpace(segment.Text); text != "" {
+			sentences = append(sentences, text)
+		}
+	}
+	return sentences
+}
+
+// SplitSentences splits text after sentence-ending punctuation followed by a space, or
+// after ideographic full stops.
+func SplitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i, r := range text {
+		end := i + utf8.RuneLen(r)
+		switch r {
+		case '.', '!', '?', '…':
+			next, _ := utf8.DecodeRuneInString(text[end:])
+			if end < len(text) && !unicode.IsSpace(next) {
+				continue
+			}
+		case '。', '！', '？':
+		default:
+			continue
+		}
+		if sentence := strings.TrimSpace(text[start:end]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = end
+	}
+	if sentence := strings.TrimSpace(text[start:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// contentRuns returns the runs of consecutive content words of a sentence, broken by
+// stopwords, numbers and punctuation.
+func contentRuns(sentence string, language string) [][]string {
+	var runs [][]string
+	var run []string
+	previousEnd := 0
+	for _, token := range Tokenize(sentence) {
+		if strings.TrimSpace(sentence[previousEnd:token.Start]) != "" && len(run) > 0 {
+			runs, run = append(runs, run), nil
+		}
+		previousEnd = token.End
+		if !isContentWord(token.Text, language) {
+			if len(run) > 0 {
+				runs, run = append(runs, run), nil
+			}
+			continue
+		}
+		run = append(run, token.Text)
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// isContentWord reports whether a lower-cased word may be a keyword: not a stopword,
+// at least two characters long and not a number.
+func isContentWord(word string, language string) bool {
+	if utf8.RuneCountInString(word) < 2 || IsStopword(language, word) {
+		return false
+	}
+	return strings.IndexFunc(word, unicode.IsLetter) >= 0
+}
+
+// textRank scores sentences, given as their content words, by their centrality in the
+// graph of sentences linked by the words they share.
+func textRank(sentences [][]string) []float64 {
+	sets := make([]map[string]bool, len(sentences))
+	for i, words := range sentences {
+		sets[i] = make(map[string]bool, len(words))
+		for _, word := range words {
+			sets[i][word] = true
+		}
+	}
+	weights := make([][]float64, len(sentences))
+	for i := range weights {
+		weights[i] = make([]float64, len(sentences))
+	}
+	totals := make([]float64, len(sentences))
+	for i := range sets {
+		for j := i + 1; j < len(sets); j++ {
+			overlap := 0
+			for word := range sets[i] {
+				if sets[j][word] {
+					overlap++
+				}
+			}
+			if overlap == 0 {
+				continue
+			}
+			norm := math.Log(float64(len(sets[i]))) + math.Log(float64(len(sets[j])))
+			if norm <= 0 {
+				norm = 1
+			}
+			weight := float64(overlap) / norm
+			weights[i][j], weights[j][i] = weight, weight
+			totals[i] += weight
+			totals[j] += weight
+		}
+	}
+
+	scores := make([]float64, len(sentences))
+	for i := range scores {
+		scores[i] = 1
+	}
+	next := make([]float64, len(sentences))
+	for round := 0; round < textRankMaxRounds; round++ {
+		delta := 0.0
+		for i := range next {
+			rank := 0.0
+			for j, weight := range weights[i] {
+				if weight > 0 {
+					rank += weight / totals[j] * scores[j]
+				}
+			}
+			next[i] = 1 - textRankDamping + textRankDamping*rank
+			delta = max(delta, math.Abs(next[i]-scores[i]))
+		}
+		scores, next = next, scores
+		if delta < textRankEpsilon {
+			break
+		}
+	}
+	return scores
+}
+
+// tfidf scores words by their frequency in the text weighted by their inverse frequency
+// across sentences, so words spread over the whole text do not dominate.
+func tfidf(sentences [][]string) map[string]float64 {
+	frequency := make(map[string]int)
+	sentenceFrequency := make(map[string]int)
+	for _, words := range sentences {
+		seen := make(map[string]bool, len(words))
+		for _, word := range words {
+			frequency[word]++
+			if !seen[word] {
+				seen[word] = true
+				sentenceFrequency[word]++
+			}
+		}
+	}
+	scores := make(map[string]float64, len(frequency))
+	for word, count := range frequency {
+		idf := math.Log(float64(1+len(sentences))/float64(1+sentenceFrequency[word])) + 1
+		scores[word] = float64(count) * idf
+	}
+	return scores
+}
+
+// topKeywords returns the limit best scored entries, scores scaled to the best one.
+func topKeywords(scores map[string]float64, limit int) []Keyword {
+	keywords := make([]Keyword, 0, len(scores))
+	for text, score := range scores {
+		keywords = append(keywords, Keyword{Text: text, Score: score})
+	}
+	slices.SortFunc(keywords, func(a, b Keyword) int {
+		if order := compareScores(a.Score, b.Score); order != 0 {
+			return order
+		}
+		return strings.Compare(a.Text, b.Text)
+	})
+	keywords = keywords[:min(limit, len(keywords))]
+	if len(keywords) == 0 {
+		return keywords
+	}
+	best := keywords[0].Score
+	for i := range keywords {
+		keywords[i].Score = math.Round(keywords[i].Score/best*1000) / 1000
+	}
+	return keywords
+}
+
+// compareScores orders higher scores first.
+func compareScores(a float64, b float64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
+}
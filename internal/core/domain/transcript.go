@@ -0,0 +1,100 @@
+package domain
+
+import "time"
+
+// RecognitionSuccess is the result of a transcription, decoded from the Whisper response
+// and extended by the processing of this service.
+type RecognitionSuccess struct {
+	// TranscriptID is assigned by this service, it is not part of the Whisper response.
+	TranscriptID   string `json:"transcript_id,omitempty"`
+	DetectedLang   string `json:"detected_language"`
+	RecognizedText string `json:"recognized_text"`
+	// Segments are decoded from Whisper's verbose JSON when it returns them.
+	Segments []Segment `json:"segments,omitempty"`
+	// Options echoes the transcription options the result was produced with.
+	Options *TranscriptionOptions `json:"options,omitempty"`
+	// AudioTrack describes the track transcribed when the upload was a video.
+	AudioTrack *AudioTrack `json:"audio_track,omitempty"`
+	// Redaction reports the personal data removed when redaction was requested.
+	Redaction *RedactionReport `json:"redaction,omitempty"`
+	// PostProcessing lists the post-processing steps applied to the transcription.
+	PostProcessing []string `json:"post_processing,omitempty"`
+	// Translation is the transcript in the requested target language.
+	Translation *Translation `json:"translation,omitempty"`
+	// Analysis is the summary and keywords of the transcript when analysis was requested.
+	Analysis *Analysis `json:"analysis,omitempty"`
+}
+
+// Segment is a span of recognized speech with its timing in seconds.
+type Segment struct {
+	ID           int     `json:"id"`
+	Start        float64 `json:"start"`
+	End          float64 `json:"end"`
+	Text         string  `json:"text"`
+	AvgLogprob   float64 `json:"avg_logprob,omitempty"`
+	NoSpeechProb float64 `json:"no_speech_prob"`
+	// Speaker labels the segment when the audio was diarized.
+	Speaker string `json:"speaker,omitempty"`
+	// Words are only present when word timestamps were requested.
+	Words []Word `json:"words,omitempty"`
+}
+
+// Word is a recognized word with its timing in seconds and recognition probability.
+type Word struct {
+	Word        string  `json:"word"`
+	Start       float64 `json:"start"`
+	End         float64 `json:"end"`
+	Probability float64 `json:"probability"`
+	// LowConfidence marks words below the requested minimum confidence.
+	LowConfidence bool `json:"low_confidence,omitempty"`
+}
+
+// Transcript is a stored transcription together with the provenance of its audio.
+type Transcript struct {
+	ID               string    `json:"id"`
+	ObjectKey        string    `json:"object_key"`
+	OriginalFilename string    `json:"original_filename,omitempty"`
+	Caller           string    `json:"caller,omitempty"`
+	Tenant           string    `json:"tenant,omitempty"`
+	SHA256           string    `json:"sha256,omitempty"`
+	MIMEType         string    `json:"mime_type,omitempty"`
+	Size             int64     `json:"size,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	// NormalizedObjectKey is the normalized derivative Whisper transcribed, if any.
+	NormalizedObjectKey string `json:"normalized_object_key,omitempty"`
+	// Audio is the metadata of the stored audio object, filled in on retrieval while
+	// the object still exists.
+	Audio *ObjectMetadata `json:"audio,omitempty"`
+	RecognitionSuccess
+}
+
+// TranscriptQuery filters and paginates stored transcripts. Zero values disable
+// the corresponding filter.
+type TranscriptQuery struct {
+	Language string
+	From     time.Time
+	To       time.Time
+	Offset   int
+	Limit    int
+}
+
+// TranscriptPage is one page of transcripts ordered from newest to oldest.
+type TranscriptPage struct {
+	Items      []Transcript `json:"items"`
+	Total      int          `json:"total"`
+	NextOffset *int         `json:"next_offset,omitempty"`
+}
+
+// Matches reports whether the transcript satisfies the query filters.
+func (q TranscriptQuery) Matches(transcript Transcript) bool {
+	if q.Language != "" && transcript.DetectedLang != q.Language {
+		return false
+	}
+	if !q.From.IsZero() && transcript.CreatedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && transcript.CreatedAt.After(q.To) {
+		return false
+	}
+	return true
+}
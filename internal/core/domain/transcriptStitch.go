@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxOverlapWords bounds the repeated words removed when stitching plain text.
+const maxOverlapWords = 50
+
+// StitchTranscripts merges the results of consecutive chunks into one result. Segment
+// and word times are shifted by the chunk start, and of the speech inside an overlap
+// only the segments before the cut are kept from the earlier chunk and the segments
+// from the cut onwards from the later one. Results without segments are joined by
+// dropping the longest run of words repeated at the seam. The detected language is
+// the one detected in most chunks.
+func StitchTranscripts(results []RecognitionSuccess, chunks []AudioChunk) RecognitionSuccess {
+	var stitched RecognitionSuccess
+	if len(results) == 0 {
+		return stitched
+	}
+	stitched.Options = results[0].Options
+
+	languages := make(map[string]int)
+	hasSegments := true
+	for _, result := range results {
+		languages[result.DetectedLang]++
+		if len(result.Segments) == 0 && strings.TrimSpace(result.RecognizedText) != "" {
+			hasSegments = false
+		}
+	}
+	for lang, count := range languages {
+		if lang != "" && (count > languages[stitched.DetectedLang] || stitched.DetectedLang == "") {
+			stitched.DetectedLang = lang
+		}
+	}
+
+	if !hasSegments {
+		for _, result := range results {
+			stitched.RecognizedText = joinOverlappingText(stitched.RecognizedText, result.RecognizedText)
+		}
+		return stitched
+	}
+
+	var texts []string
+	for i, result := range results {
+		chunk := chunks[i]
+		from := 0.0
+		if i > 0 {
+			from = chunks[i-1].Cut
+		}
+		for _, segment := range result.Segments {
+			start := segment.Start + chunk.Start
+			if (i > 0 && start < from) || (i < len(results)-1 && start >= chunk.Cut) {
+				continue
+			}
+			segment.ID = len(stitched.Segments)
+			segment.Start = start
+			segment.End += chunk.Start
+			words := make([]Word, len(segment.Words))
+			for j, word := range segment.Words {
+				word.Start += chunk.Start
+				word.End += chunk.Start
+				words[j] = word
+			}
+			if len(words) > 0 {
+				segment.Words = words
+			}
+			stitched.Segments = append(stitched.Segments, segment)
+			texts = append(texts, segment.Text)
+		}
+	}
+	stitched.RecognizedText = strings.TrimSpace(strings.Join(texts, ""))
+	return stitched
+}
+
+// joinOverlappingText appends next to text, dropping the longest run of words at the
+// start of next that repeats the end of text, ignoring case and punctuation.
+func joinOverlappingText(text string, next string) string {
+	next = strings.TrimSpace(next)
+	if text == "" || next == "" {
+		return strings.TrimSpace(text + " " + next)
+	}
+	tail := strings.Fields(text)
+	head := strings.Fields(next)
+	for n := min(len(tail), len(head), maxOverlapWords); n > 0; n-- {
+		if sameWords(tail[len(tail)-n:], head[:n]) {
+			head = head[n:]
+			break
+		}
+	}
+	if len(head) == 0 {
+		return text
+	}
+	return text + " " + strings.Join(head, " ")
+}
+
+func sameWords(a []string, b []string) bool {
+	for i := range a {
+		if normalizeWord(a[i]) != normalizeWord(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	}))
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// PaginateTranscripts filters transcripts with the query and returns the requested page,
+// newest first.
+func PaginateTranscripts(transcripts []Transcript, query TranscriptQuery) TranscriptPage {
+	matching := make([]Transcript, 0, len(transcripts))
+	for _, transcript := range transcripts {
+		if query.Matches(transcript) {
+			matching = append(matching, transcript)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].CreatedAt.After(matching[j].CreatedAt)
+	})
+
+	page := TranscriptPage{Items: []Transcript{}, Total: len(matching)}
+	if query.Offset >= len(matching) {
+		return page
+	}
+	end := len(matching)
+	if query.Limit > 0 && query.Offset+query.Limit < end {
+		end = query.Offset + query.Limit
+		page.NextOffset = &end
+	}
+	page.Items = matching[query.Offset:end]
+	return page
+}
+
+// NormalizedObjectKey returns the key of the normalized derivative stored next to the
+// audio object at objectKey.
+func NormalizedObjectKey(objectKey string) string {
+	return strings.TrimSuffix(objectKey, path.Ext(objectKey)) + ".normalized.wav"
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Whisper tasks.
+const (
+	TaskTranscribe = "transcribe"
+	TaskTranslate  = "translate"
+)
+
+// TranscriptionOptions are forwarded to Whisper. Zero values leave the choice to Whisper.
+type TranscriptionOptions struct {
+	// Language is the ISO 639-1 code of the spoken language, skipping detection.
+	Language string `json:"language,omitempty"`
+	// Task is TaskTranscribe or TaskTranslate, which translates to English.
+	Task          string   `json:"task,omitempty"`
+	InitialPrompt string   `json:"initial_prompt,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	// WordTimestamps asks Whisper for word-level timestamps.
+	WordTimestamps bool `json:"word_timestamps,omitempty"`
+}
+
+// IsZero reports whether no option is set.
+func (o TranscriptionOptions) IsZero() bool {
+	return o.Language == "" && o.Task == "" && o.InitialPrompt == "" && o.Temperature == nil && !o.WordTimestamps
+}
+
+// MaxInitialPromptLength bounds the initial prompt in characters; Whisper only uses the
+// last 224 tokens of it anyway.
+const MaxInitialPromptLength = 1000
+
+// supportedLanguages are the ISO 639-1 codes of the languages Whisper can transcribe.
+var supportedLanguages = map[string]bool{
+	"af": true, "am": true, "ar": true, "as": true, "az": true, "ba": true, "be": true, "bg": true,
+	"bn": true, "bo": true, "br": true, "bs": true, "ca": true, "cs": true, "cy": true, "da": true,
+	"de": true, "el": true, "en": true, "es": true, "et": true, "eu": true, "fa": true, "fi": true,
+	"fo": true, "fr": true, "gl": true, "gu": true, "ha": true, "he": true, "hi": true, "hr": true,
+	"ht": true, "hu": true, "hy": true, "id": true, "is": true, "it": true, "ja": true, "ka": true,
+	"kk": true, "km": true, "kn": true, "ko": true, "la": true, "lb": true, "ln": true, "lo": true,
+	"lt": true, "lv": true, "mg": true, "mi": true, "mk": true, "ml": true, "mn": true, "mr": true,
+	"ms": true, "mt": true, "my": true, "ne": true, "nl": true, "nn": true, "no": true, "oc": true,
+	"pa": true, "pl": true, "ps": true, "pt": true, "ro": true, "ru": true, "sa": true, "sd": true,
+	"si": true, "sk": true, "sl": true, "sn": true, "so": true, "sq": true, "sr": true, "su": true,
+	"sv": true, "sw": true, "ta": true, "te": true, "tg": true, "th": true, "tk": true, "tl": true,
+	"tr": true, "tt": true, "uk": true, "ur": true, "uz": true, "vi": true, "yi": true, "yo": true,
+	"zh": true,
+}
+
+// ParseTranscriptionOptions reads the language, task, initial_prompt, temperature and
+// word_timestamps fields through get, such as a form or query lookup, and validates them.
+func ParseTranscriptionOptions(get func(key string) string) (TranscriptionOptions, error) {
+	options := TranscriptionOptions{
+		Language:      get("language"),
+		Task:          get("task"),
+		InitialPrompt: get("initial_prompt"),
+	}
+	if raw := get("temperature"); raw != "" {
+		temperature, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return options, fmt.Errorf("invalid 'temperature', expected a number between 0 and 1")
+		}
+		options.Temperature = &temperature
+	}
+	if raw := get("word_timestamps"); raw != "" {
+		wordTimestamps, err := strconv.ParseBool(raw)
+		if err != nil {
+			return options, fmt.Errorf("invalid 'word_timestamps', expected a boolean")
+		}
+		options.WordTimestamps = wordTimestamps
+	}
+	return NormalizeTranscriptionOptions(options)
+}
+
+// NormalizeTranscriptionOptions lower-cases the language and task and checks every
+// option against the values Whisper accepts.
+func NormalizeTranscriptionOptions(options TranscriptionOptions) (TranscriptionOptions, error) {
+	options.Language = strings.ToLower(strings.TrimSpace(options.Language))
+	options.Task = strings.ToLower(strings.TrimSpace(options.Task))
+
+	if options.Language != "" && !supportedLanguages[options.Language] {
+		return options, fmt.Errorf("unsupported 'language' %q, expected an ISO 639-1 code", options.Language)
+	}
+	if options.Task != "" && options.Task != TaskTranscribe && options.Task != TaskTranslate {
+		return options, fmt.Errorf("invalid 'task', expected %q or %q", TaskTranscribe, TaskTranslate)
+	}
+	if len([]rune(options.InitialPrompt)) > MaxInitialPromptLength {
+		return options, fmt.Errorf("'initial_prompt' is longer than %d characters", MaxInitialPromptLength)
+	}
+	if options.Temperature != nil && (math.IsNaN(*options.Temperature) || *options.Temperature < 0 || *options.Temperature > 1) {
+		return options, fmt.Errorf("invalid 'temperature', expected a number between 0 and 1")
+	}
+	return options, nil
+}
+
+// TranscriptionOptionsKey returns a canonical form of the options for result cache keys.
+// The prompt is hashed to keep keys short.
+func TranscriptionOptionsKey(options TranscriptionOptions) string {
+	if options.IsZero() {
+		return ""
+	}
+	var key strings.Builder
+	fmt.Fprintf(&key, "lang=%s;task=%s;words=%t", options.Language, options.Task, options.WordTimestamps)
+	if options.Temperature != nil {
+		fmt.Fprintf(&key, ";temp=%s", strconv.FormatFloat(*options.Temperature, 'g', -1, 64))
+	}
+	if options.InitialPrompt != "" {
+		sum := sha256.Sum256([]byte(options.InitialPrompt))
+		fmt.Fprintf(&key, ";prompt=%s", hex.EncodeToString(sum[:8]))
+	}
+	return key.String()
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Translation is a transcript translated into a target language, segment by segment
+// when the transcript has segments.
+type Translation struct {
+	Language string `json:"language"`
+	Text     string `json:"text"`
+	// Segments carry the timings and speakers of the original segments.
+	Segments []TranslatedSegment `json:"segments,omitempty"`
+}
+
+// TranslatedSegment is the translation of the segment with the same ID.
+type TranslatedSegment struct {
+	ID      int     `json:"id"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+	Speaker string  `json:"speaker,omitempty"`
+}
+
+// targetLanguagePattern matches ISO 639 codes with an optional region or script, such as
+// "de", "pt-br" or "zh-hant".
+var targetLanguagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,4})?$`)
+
+// NormalizeTargetLanguage lower-cases a translation target language and validates it.
+func NormalizeTargetLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language != "" && !targetLanguagePattern.MatchString(language) {
+		return language, fmt.Errorf("invalid 'target_language' %q, expected an ISO 639-1 code", language)
+	}
+	return language, nil
+}
+
+// TranslationTexts returns the texts of a transcription to translate: the text of every
+// segment, so translations stay aligned with the timings, or the recognized text when
+// there are no segments.
+func TranslationTexts(result RecognitionSuccess) []string {
+	if len(result.Segments) == 0 {
+		return []string{strings.TrimSpace(result.RecognizedText)}
+	}
+	texts := make([]string, len(result.Segments))
+	for i, segment := range result.Segments {
+		texts[i] = strings.TrimSpace(segment.Text)
+	}
+	return texts
+}
+
+// ApplyTranslation attaches the translation into language of a transcription, where
+// texts are the translations of TranslationTexts(result) in order.
+func ApplyTranslation(result RecognitionSuccess, language string, texts []string) RecognitionSuccess {
+	translation := Translation{Language: language}
+	if len(result.Segments) == 0 {
+		translation.Text = strings.TrimSpace(strings.Join(texts, " "))
+		result.Translation = &translation
+		return result
+	}
+	parts := make([]string, 0, len(texts))
+	translation.Segments = make([]TranslatedSegment, len(result.Segments))
+	for i, segment := range result.Segments {
+		text := ""
+		if i < len(texts) {
+			text = strings.TrimSpace(texts[i])
+		}
+		translation.Segments[i] = TranslatedSegment{
+			ID:      segment.ID,
+			Start:   segment.Start,
+			End:     segment.End,
+			Text:    text,
+			Speaker: segment.Speaker,
+		}
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	translation.Text = strings.Join(parts, " ")
+	result.Translation = &translation
+	return result
+}
+
+// TranslatedSegments returns the segments of a translation for subtitle rendering.
+func TranslatedSegments(translation Translation) []Segment {
+	segments := make([]Segment, len(translation.Segments))
+	for i, segment := range translation.Segments {
+		segments[i] = Segment{
+			ID:      segment.ID,
+			Start:   segment.Start,
+			End:     segment.End,
+			Text:    segment.Text,
+			Speaker: segment.Speaker,
+		}
+	}
+	return segments
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// AudioTrack describes the audio track extracted from a video before transcription.
+type AudioTrack struct {
+	// ID is the track ID of MP4 files or the track number of Matroska files.
+	ID        int    `json:"id"`
+	Codec     string `json:"codec"`
+	Language  string `json:"language,omitempty"`
+	Container string `json:"container"`
+	// AudioTracks is how many audio tracks the video has.
+	AudioTracks int `json:"audio_tracks"`
+}
+
+var (
+	// ErrNoAudioTrack is returned for videos without an audio track.
+	ErrNoAudioTrack = errors.New("video has no audio track")
+	// ErrUnsupportedVideo is returned for video layouts the demuxers cannot handle.
+	ErrUnsupportedVideo = errors.New("unsupported video layout")
+)
+
+// ExtractedAudio is the audio track of a video in a standalone container, buffered on
+// disk. Closing it removes the file.
+type ExtractedAudio struct {
+	*TempFile
+	MIMEType  string
+	Extension string
+	Track     AudioTrack
+}
+
+// HasExtractableAudio reports whether ExtractAudioTrack handles the MIME type.
+func HasExtractableAudio(mimeType string) bool {
+	switch mimeType {
+	case "video/mp4", "video/quicktime", "video/x-m4v", "video/3gpp", "video/webm", "video/x-matroska":
+		return true
+	}
+	return false
+}
+
+// ExtractAudioTrack copies the audio track of an MP4, QuickTime, WebM or Matroska video
+// into a standalone M4A, WebM or Matroska file without re-encoding. The enabled or
+// default audio track is chosen, otherwise the first one. The reader position is
+// restored to the start of the file.
+func ExtractAudioTrack(file io.ReadSeeker, mimeType string) (*ExtractedAudio, error) {
+	readerAt, ok := file.(io.ReaderAt)
+	if !ok {
+		return nil, fmt.Errorf("%w: file does not support random access", ErrUnsupportedVideo)
+	}
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Seek(0, io.SeekStart)
+
+	tmp, err := os.CreateTemp("", "sr-api-audio-*")
+	if err != nil {
+		return nil, err
+	}
+	audio := &ExtractedAudio{TempFile: &TempFile{File: tmp}}
+	switch mimeType {
+	case "video/mp4", "video/quicktime", "video/x-m4v", "video/3gpp":
+		audio.MIMEType, audio.Extension = "audio/mp4", ".m4a"
+		audio.Track, err = extractMP4Audio(readerAt, size, tmp)
+	case "video/webm", "video/x-matroska":
+		audio.Track, err = extractMatroskaAudio(readerAt, size, tmp)
+		audio.MIMEType, audio.Extension = "audio/x-matroska", ".mka"
+		if audio.Track.Container == "webm" {
+			audio.MIMEType, audio.Extension = "audio/webm", ".webm"
+		}
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedVideo, mimeType)
+	}
+	if err == nil {
+		audio.Size, err = tmp.Seek(0, io.SeekCurrent)
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		audio.Close()
+		return nil, err
+	}
+	return audio, nil
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+type vocabularyEntry struct {
+	phrase      []string
+	replacement string
+}
+
+// ReplaceVocabulary replaces the phrases of the vocabulary, matched case-insensitively
+// on whole words and ignoring punctuation around them, by their replacement, such as
+// "kuber netes" by "Kubernetes". Longer phrases take precedence. The words of a phrase
+// become one word spanning their timings.
+func ReplaceVocabulary(result RecognitionSuccess, vocabulary map[string]string) RecognitionSuccess {
+	entries := make([]vocabularyEntry, 0, len(vocabulary))
+	for phrase, replacement := range vocabulary {
+		var words []string
+		for _, word := range strings.Fields(phrase) {
+			if word = normalizeWord(word); word != "" {
+				words = append(words, word)
+			}
+		}
+		if len(words) > 0 {
+			entries = append(entries, vocabularyEntry{phrase: words, replacement: replacement})
+		}
+	}
+	if len(entries) == 0 {
+		return result
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if len(entries[i].phrase) != len(entries[j].phrase) {
+			return len(entries[i].phrase) > len(entries[j].phrase)
+		}
+		return strings.Join(entries[i].phrase, " ") < strings.Join(entries[j].phrase, " ")
+	})
+
+	return mapWords(result, func(words []Word) ([]Word, bool) {
+		normalized := make([]string, len(words))
+		for i, word := range words {
+			normalized[i] = normalizeWord(word.Word)
+		}
+		replaced := make([]Word, 0, len(words))
+		changed := false
+		for i := 0; i < len(words); {
+			matched := false
+			for _, entry := range entries {
+				end := i + len(entry.phrase)
+				if end <= len(words) && sameWords(entry.phrase, normalized[i:end]) {
+					replaced = append(replaced, mergeWords(words, i, end, entry.replacement))
+					i, matched, changed = end, true, true
+					break
+				}
+			}
+			if !matched {
+				replaced = append(replaced, words[i])
+				i++
+			}
+		}
+		return replaced, changed
+	})
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"sr-api/internal/core/domain"
+)
+
+// AuditSink persists audit records. Implementations must never modify or remove
+// records once appended.
+type AuditSink interface {
+	Append(ctx context.Context, record domain.AuditRecord) error
+	// Query returns matching records newest first, at most query.Limit of them when set.
+	Query(ctx context.Context, query domain.AuditQuery) ([]domain.AuditRecord, error)
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"sr-api/internal/core/domain"
+)
+
+// Diarizer tells apart the speakers of a stored audio object.
+type Diarizer interface {
+	// Diarize returns the speaker turns of the object in time order. numSpeakers is the
+	// expected number of speakers, zero when unknown.
+	Diarize(ctx context.Context, objectKey string, numSpeakers int) ([]domain.SpeakerTurn, error)
+}
@@ -2,7 +2,6 @@ package ports
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -11,12 +10,25 @@ import (
 
 // RespondWithError sends an error response along with tracing the operation.
 func RespondWithError(c *gin.Context, span trace.Span, err error, code int, message string) {
-	spanID := telemetry.GetSpanId(span)
+	logger := telemetry.LoggerFromContext(trace.ContextWithSpan(c.Request.Context(), span))
 	span.SetAttributes(attribute.Int("http.status_code", code), attribute.String("error.message", message))
 	span.RecordError(err)
 
-	log.Error().Str("span_id", spanID).Int("http.status_code", code).Msg(message)
+	logger.Error().Int("http.status_code", code).Msg(message)
 	span.SetStatus(codes.Error, message)
 
 	c.JSON(code, gin.H{"error": "something went wrong, please try again later"})
 }
+
+// RespondWithClientError sends an error response whose message is safe to show to the
+// caller, such as a validation failure, along with tracing the operation.
+func RespondWithClientError(c *gin.Context, span trace.Span, err error, code int, message string) {
+	logger := telemetry.LoggerFromContext(trace.ContextWithSpan(c.Request.Context(), span))
+	span.SetAttributes(attribute.Int("http.status_code", code), attribute.String("error.message", message))
+	span.RecordError(err)
+
+	logger.Warn().Err(err).Int("http.status_code", code).Msg(message)
+	span.SetStatus(codes.Error, message)
+
+	c.JSON(code, gin.H{"error": message})
+}
@@ -1,33 +1,51 @@
 package ports
 
 import (
+	"context"
 	"fmt"
-	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"net/http"
 	"sr-api/internal/core/ports/telemetry"
+	"strconv"
 )
 
-func HttpClient(c *gin.Context, request *http.Request) (*http.Response, error) {
-	_, span := telemetry.StartSpanFromGinContext(c, "SendRequestToWhisperService")
-	spanID := telemetry.GetSpanId(span)
-	log.Debug().Str("span_id", spanID).Msg("Starting httpClient")
-	client := &http.Client{}
-	response, err := client.Do(request)
+// whisperClient injects the trace context into outgoing requests so that the Whisper
+// service can continue the trace.
+var whisperClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+// HttpClient sends a request to the Whisper service as a child span of the span in ctx.
+// Responses other than 200 OK are returned as errors; writing the HTTP response to the
+// caller is left to the handler.
+func HttpClient(ctx context.Context, request *http.Request) (*http.Response, error) {
+	ctx, span := telemetry.StartSpan(ctx, "SendRequestToWhisperService",
+		attribute.String("http.method", request.Method),
+		attribute.String("http.url", request.URL.String()),
+	)
+	defer span.End()
+
+	logger := telemetry.LoggerFromContext(ctx)
+	logger.Debug().Msg("Starting httpClient")
+	response, err := whisperClient.Do(request.WithContext(ctx))
 	if err != nil {
-		log.Error().Str("span_id", spanID).Err(err).Msg("Failed to send request to Whisper service")
+		logger.Error().Err(err).Msg("Failed to send request to Whisper service")
+		telemetry.MetricsFromContext(ctx).RecordWhisperError(ctx, "transport")
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Failed to send request to Whisper service")
-		RespondWithError(c, span, err, http.StatusInternalServerError, "Failed to send request to Whisper service")
 		return nil, err
 	}
-	log.Info().Str("span_id", spanID).Msg("Request sent to Whisper service")
+	logger.Info().Msg("Request sent to Whisper service")
+	span.SetAttributes(attribute.Int("http.status_code", response.StatusCode))
 	if response.StatusCode != http.StatusOK {
-		log.Error().Str("span_id", spanID).Int("status_code", response.StatusCode).Msg("Whisper service returned an error")
+		response.Body.Close()
+		err := fmt.Errorf("whisper service error: %d", response.StatusCode)
+		logger.Error().Int("status_code", response.StatusCode).Msg("Whisper service returned an error")
+		telemetry.MetricsFromContext(ctx).RecordWhisperError(ctx, strconv.Itoa(response.StatusCode))
+		span.RecordError(err)
 		span.SetStatus(codes.Error, fmt.Sprintf("Whisper service error: %d", response.StatusCode))
-		RespondWithError(c, span, err, response.StatusCode, fmt.Sprintf("Whisper service error: %d", response.StatusCode))
-		return nil, fmt.Errorf("whisper service error: %d", response.StatusCode)
+		return nil, err
 	}
+	span.SetStatus(codes.Ok, "Request sent to Whisper service")
 	return response, nil
 }
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"io"
+	"sr-api/internal/core/domain"
+)
+
+// Scanner inspects uploaded content for malware before it is stored.
+type Scanner interface {
+	// Scan reads content to the end. An error means the content could not be scanned,
+	// not that it is unsafe.
+	Scan(ctx context.Context, content io.Reader) (domain.ScanResult, error)
+}
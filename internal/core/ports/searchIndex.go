@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"sr-api/internal/core/domain"
+)
+
+// SearchIndex is a full-text index over stored transcripts.
+type SearchIndex interface {
+	// Index adds the transcript, replacing any previous version with the same ID.
+	Index(ctx context.Context, transcript domain.Transcript) error
+	Remove(ctx context.Context, id string) error
+	Search(ctx context.Context, query domain.SearchQuery) (domain.SearchResult, error)
+	// Rebuild replaces the whole index with the transcripts returned by list. Transcripts
+	// indexed or removed while list runs keep their latest state rather than the listed one.
+	Rebuild(ctx context.Context, list func(ctx context.Context) ([]domain.Transcript, error)) error
+}
@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// Summarizer writes summaries of transcripts, replacing the built-in extractive summary.
+type Summarizer interface {
+	// Summarize returns a summary of text of at most maxSentences sentences. language is
+	// the ISO 639-1 code of the text, empty when unknown.
+	Summarize(ctx context.Context, text string, language string, maxSentences int) (string, error)
+}
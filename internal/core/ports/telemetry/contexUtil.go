@@ -11,15 +11,31 @@ import (
 
 // StartSpanFromGinContext initializes a new tracing span for an HTTP request within a Gin context.
 func StartSpanFromGinContext(c *gin.Context, spanName string) (context.Context, trace.Span) {
-	log.Debug().Msgf("Starting span '%s' from Gin context", spanName)
+	LoggerFromContext(c.Request.Context()).Debug().Msgf("Starting span '%s' from Gin context", spanName)
 	tr := otel.Tracer("sr-api")
-	ctx, span := tr.Start(c.Request.Context(), spanName, trace.WithAttributes(
+	ctx, span := tr.Start(c.Request.Context(), spanName, trace.WithAttributes(tenantAttributes(c.Request.Context(),
 		attribute.String("http.method", c.Request.Method),
 		attribute.String("http.url", c.Request.URL.String()),
-	))
+	)...))
 	return ctx, span
 }
 
+// StartSpan starts a child span of the span carried by ctx. The returned context must be
+// passed on to nested operations so that their spans form a tree under this one.
+func StartSpan(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer("sr-api").Start(ctx, spanName, trace.WithAttributes(tenantAttributes(ctx, attrs...)...))
+	LoggerFromContext(ctx).Debug().Msgf("Started span '%s'", spanName)
+	return ctx, span
+}
+
+// tenantAttributes appends the tenant of ctx to span attributes when set.
+func tenantAttributes(ctx context.Context, attrs ...attribute.KeyValue) []attribute.KeyValue {
+	if tenantID := TenantFromContext(ctx); tenantID != "" {
+		attrs = append(attrs, attribute.String(TenantAttribute, tenantID))
+	}
+	return attrs
+}
+
 func GetSpanId(span trace.Span) string {
 	log.Debug().Msg("Getting span ID")
 	spanContext := span.SpanContext()
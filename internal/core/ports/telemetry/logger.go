@@ -0,0 +1,56 @@
+package telemetry
+
+import (
+	"context"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"os"
+	"strings"
+)
+
+// SetupLogger configures the global zerolog logger. Level is any zerolog level name
+// and format is either "json" or "console"; unknown values fall back to info and JSON.
+func SetupLogger(level string, format string) {
+	lvl, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil || level == "" {
+		lvl = zerolog.InfoLevel
+	}
+	zerolog.SetGlobalLevel(lvl)
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
+
+	var out io.Writer = os.Stderr
+	if strings.EqualFold(format, "console") {
+		out = zerolog.ConsoleWriter{Out: os.Stderr}
+	}
+	log.Logger = zerolog.New(out).With().Timestamp().Logger()
+
+	// Code running outside a request (tests, background jobs) falls back to the global logger.
+	zerolog.DefaultContextLogger = &log.Logger
+}
+
+// LoggerFromContext returns the request-scoped logger stored in ctx, annotated with
+// the trace and span IDs of the span currently active in ctx so that log lines can be
+// joined with traces.
+func LoggerFromContext(ctx context.Context) *zerolog.Logger {
+	logger := zerolog.Ctx(ctx)
+	if logger.GetLevel() == zerolog.Disabled && zerolog.DefaultContextLogger == nil {
+		logger = &log.Logger
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return logger
+	}
+	withSpan := logger.With().
+		Str("trace_id", spanContext.TraceID().String()).
+		Str("span_id", spanContext.SpanID().String()).
+		Logger()
+	return &withSpan
+}
+
+// ContextWithLogger stores logger in ctx so that LoggerFromContext can find it.
+func ContextWithLogger(ctx context.Context, logger zerolog.Logger) context.Context {
+	return logger.WithContext(ctx)
+}
@@ -0,0 +1,236 @@
+package telemetry
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"sync"
+	"time"
+)
+
+// Metrics holds the business instruments recorded by the upload and transcription pipeline.
+type Metrics struct {
+	uploadBytes         metric.Int64Histogram
+	uploads             metric.Int64Counter
+	signatureRejections metric.Int64Counter
+	minioPutDuration    metric.Float64Histogram
+	whisperDuration     metric.Float64Histogram
+	whisperErrors       metric.Int64Counter
+	detectedLanguages   metric.Int64Counter
+	audioSeconds        metric.Float64Counter
+	cacheLookups        metric.Int64Counter
+	purges              metric.Int64Counter
+	scans               metric.Int64Counter
+	redactions          metric.Int64Counter
+	quotaRejections     metric.Int64Counter
+}
+
+var (
+	appMetrics     *Metrics
+	appMetricsOnce sync.Once
+)
+
+type metricsContextKey struct{}
+
+// GetMetrics returns the process-wide instruments, creating them on first use
+// from the global MeterProvider.
+func GetMetrics() *Metrics {
+	appMetricsOnce.Do(func() {
+		appMetrics = NewMetrics(otel.Meter("sr-api"))
+	})
+	return appMetrics
+}
+
+// ContextWithMetrics stores instruments in ctx that replace the process-wide ones for
+// work derived from ctx, such as instruments of a meter read by a test.
+func ContextWithMetrics(ctx context.Context, m *Metrics) context.Context {
+	return context.WithValue(ctx, metricsContextKey{}, m)
+}
+
+// MetricsFromContext returns the instruments stored in ctx, or the process-wide ones.
+func MetricsFromContext(ctx context.Context) *Metrics {
+	if m, ok := ctx.Value(metricsContextKey{}).(*Metrics); ok {
+		return m
+	}
+	return GetMetrics()
+}
+
+// NewMetrics creates the instruments from meter.
+func NewMetrics(meter metric.Meter) *Metrics {
+	m := &Metrics{}
+	var err error
+
+	if m.uploadBytes, err = meter.Int64Histogram("sr_api.upload.size",
+		metric.WithDescription("Size of uploaded files"),
+		metric.WithUnit("By"),
+	); err != nil {
+		logInstrumentError("sr_api.upload.size", err)
+	}
+	if m.uploads, err = meter.Int64Counter("sr_api.uploads",
+		metric.WithDescription("Number of accepted uploads by MIME type"),
+	); err != nil {
+		logInstrumentError("sr_api.uploads", err)
+	}
+	if m.signatureRejections, err = meter.Int64Counter("sr_api.signature.rejections",
+		metric.WithDescription("Number of files rejected by the signature check or media policy by reason"),
+	); err != nil {
+		logInstrumentError("sr_api.signature.rejections", err)
+	}
+	if m.minioPutDuration, err = meter.Float64Histogram("sr_api.minio.put.duration",
+		metric.WithDescription("Latency of MinIO PutObject calls"),
+		metric.WithUnit("s"),
+	); err != nil {
+		logInstrumentError("sr_api.minio.put.duration", err)
+	}
+	if m.whisperDuration, err = meter.Float64Histogram("sr_api.whisper.duration",
+		metric.WithDescription("Latency of Whisper transcription requests"),
+		metric.WithUnit("s"),
+	); err != nil {
+		logInstrumentError("sr_api.whisper.duration", err)
+	}
+	if m.whisperErrors, err = meter.Int64Counter("sr_api.whisper.errors",
+		metric.WithDescription("Number of failed Whisper requests by status"),
+	); err != nil {
+		logInstrumentError("sr_api.whisper.errors", err)
+	}
+	if m.detectedLanguages, err = meter.Int64Counter("sr_api.detected_languages",
+		metric.WithDescription("Number of transcriptions by detected language"),
+	); err != nil {
+		logInstrumentError("sr_api.detected_languages", err)
+	}
+	if m.audioSeconds, err = meter.Float64Counter("sr_api.audio.processed",
+		metric.WithDescription("Duration of audio sent for transcription"),
+		metric.WithUnit("s"),
+	); err != nil {
+		logInstrumentError("sr_api.audio.processed", err)
+	}
+	if m.cacheLookups, err = meter.Int64Counter("sr_api.result_cache.lookups",
+		metric.WithDescription("Number of transcription result cache lookups by result"),
+	); err != nil {
+		logInstrumentError("sr_api.result_cache.lookups", err)
+	}
+	if m.purges, err = meter.Int64Counter("sr_api.retention.purges",
+		metric.WithDescription("Number of audio objects purged by reason"),
+	); err != nil {
+		logInstrumentError("sr_api.retention.purges", err)
+	}
+	if m.scans, err = meter.Int64Counter("sr_api.content_scans",
+		metric.WithDescription("Number of content scans by verdict"),
+	); err != nil {
+		logInstrumentError("sr_api.content_scans", err)
+	}
+	if m.redactions, err = meter.Int64Counter("sr_api.redactions",
+		metric.WithDescription("Number of personal data values redacted from transcripts by category"),
+	); err != nil {
+		logInstrumentError("sr_api.redactions", err)
+	}
+	if m.quotaRejections, err = meter.Int64Counter("sr_api.quota.rejections",
+		metric.WithDescription("Number of requests rejected by a tenant quota by limit"),
+	); err != nil {
+		logInstrumentError("sr_api.quota.rejections", err)
+	}
+
+	return m
+}
+
+func logInstrumentError(name string, err error) {
+	log.Error().Err(err).Str("instrument", name).Msg("Failed to create metric instrument")
+}
+
+// RecordUpload counts an accepted upload and its size.
+func (m *Metrics) RecordUpload(ctx context.Context, mimeType string, size int64) {
+	attrs := withTenant(ctx, attribute.String("mime_type", mimeType))
+	if m.uploads != nil {
+		m.uploads.Add(ctx, 1, attrs)
+	}
+	if m.uploadBytes != nil {
+		m.uploadBytes.Record(ctx, size, attrs)
+	}
+}
+
+// RecordSignatureRejection counts a file rejected by the signature check.
+func (m *Metrics) RecordSignatureRejection(ctx context.Context, reason string) {
+	if m.signatureRejections != nil {
+		m.signatureRejections.Add(ctx, 1, withTenant(ctx, attribute.String("reason", reason)))
+	}
+}
+
+// RecordMinioPut records the latency of a PutObject call.
+func (m *Metrics) RecordMinioPut(ctx context.Context, elapsed time.Duration, success bool) {
+	if m.minioPutDuration != nil {
+		m.minioPutDuration.Record(ctx, elapsed.Seconds(), withTenant(ctx, attribute.Bool("success", success)))
+	}
+}
+
+// RecordWhisper records the latency of a Whisper request.
+func (m *Metrics) RecordWhisper(ctx context.Context, elapsed time.Duration, success bool) {
+	if m.whisperDuration != nil {
+		m.whisperDuration.Record(ctx, elapsed.Seconds(), withTenant(ctx, attribute.Bool("success", success)))
+	}
+}
+
+// RecordWhisperError counts a failed Whisper request; status is the HTTP status code
+// or a short failure class when no response was received.
+func (m *Metrics) RecordWhisperError(ctx context.Context, status string) {
+	if m.whisperErrors != nil {
+		m.whisperErrors.Add(ctx, 1, withTenant(ctx, attribute.String("status", status)))
+	}
+}
+
+// RecordDetectedLanguage counts a completed transcription by its detected language.
+func (m *Metrics) RecordDetectedLanguage(ctx context.Context, language string) {
+	if language == "" {
+		language = "unknown"
+	}
+	if m.detectedLanguages != nil {
+		m.detectedLanguages.Add(ctx, 1, withTenant(ctx, attribute.String("language", language)))
+	}
+}
+
+// RecordAudioSeconds adds the duration of transcribed audio.
+func (m *Metrics) RecordAudioSeconds(ctx context.Context, seconds float64) {
+	if m.audioSeconds != nil && seconds > 0 {
+		m.audioSeconds.Add(ctx, seconds, withTenant(ctx))
+	}
+}
+
+// RecordCacheLookup counts a transcription result cache lookup.
+func (m *Metrics) RecordCacheLookup(ctx context.Context, hit bool) {
+	if m.cacheLookups != nil {
+		m.cacheLookups.Add(ctx, 1, withTenant(ctx, attribute.Bool("hit", hit)))
+	}
+}
+
+// RecordPurge counts an audio object removed by the retention policy.
+func (m *Metrics) RecordPurge(ctx context.Context, reason string, success bool) {
+	if m.purges != nil {
+		m.purges.Add(ctx, 1, withTenant(ctx, attribute.String("reason", reason), attribute.Bool("success", success)))
+	}
+}
+
+// RecordScan counts a content scan by verdict: clean, infected or error.
+func (m *Metrics) RecordScan(ctx context.Context, verdict string) {
+	if m.scans != nil {
+		m.scans.Add(ctx, 1, withTenant(ctx, attribute.String("verdict", verdict)))
+	}
+}
+
+// RecordRedactions counts the values redacted from a transcript by category.
+func (m *Metrics) RecordRedactions(ctx context.Context, categories map[string]int) {
+	if m.redactions == nil {
+		return
+	}
+	for category, count := range categories {
+		m.redactions.Add(ctx, int64(count), withTenant(ctx, attribute.String("category", category)))
+	}
+}
+
+// RecordQuotaRejection counts a request rejected by the daily uploads or bytes quota of
+// its tenant.
+func (m *Metrics) RecordQuotaRejection(ctx context.Context, limit string) {
+	if m.quotaRejections != nil {
+		m.quotaRejections.Add(ctx, 1, withTenant(ctx, attribute.String("limit", limit)))
+	}
+}
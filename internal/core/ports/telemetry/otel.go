@@ -7,6 +7,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/propagation"
 	sdkmeter "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -14,16 +15,16 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
-	"os"
+	"sr-api/internal/config"
 )
 
 // SetupOTelSDK bootstraps the OpenTelemetry pipeline.
 // If it does not return an error, make sure to call shutdown for proper cleanup.
-func SetupOTelSDK(ctx context.Context) (shutdown func(context.Context) error, err error) {
+func SetupOTelSDK(ctx context.Context, cfg *config.AppConfig) (shutdown func(context.Context) error, err error) {
 
 	var shutdownFuncs []func(context.Context) error
 	res, err := createResource(ctx)
-	target := os.Getenv("TELEMETRY_GRPC_TARGET")
+	target := cfg.TelemetryGrpcEndpoint
 	if target == "" {
 		log.Warn().Msg("TELEMETRY_GRPC_TARGET environment variable is not set")
 		log.Warn().Msg("Using default value: localhost:4317")
@@ -65,7 +66,7 @@ func SetupOTelSDK(ctx context.Context) (shutdown func(context.Context) error, er
 	otel.SetTextMapPropagator(propagation.TraceContext{})
 
 	// Set up meter provider.
-	meterProvider, err := initMetricsProvider(ctx, target, res)
+	meterProvider, err := initMetricsProvider(ctx, target, res, cfg.PrometheusEnabled)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to initialize meter provider")
 		handleErr(err)
@@ -84,7 +85,7 @@ func newPropagator() propagation.TextMapPropagator {
 	)
 }
 
-func initMetricsProvider(ctx context.Context, target string, res *resource.Resource) (*sdkmeter.MeterProvider, error) {
+func initMetricsProvider(ctx context.Context, target string, res *resource.Resource, withPrometheus bool) (*sdkmeter.MeterProvider, error) {
 	conn, err := grpc.DialContext(ctx, target,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
@@ -100,12 +101,23 @@ func initMetricsProvider(ctx context.Context, target string, res *resource.Resou
 		return nil, err
 	}
 
-	meterProvider := sdkmeter.NewMeterProvider(
+	opts := []sdkmeter.Option{
 		sdkmeter.WithReader(sdkmeter.NewPeriodicReader(metricExporter)),
 		sdkmeter.WithResource(res),
-	)
+	}
+
+	// The Prometheus reader registers itself with the default registry,
+	// which is served by promhttp.Handler on the /metrics route.
+	if withPrometheus {
+		promExporter, err := prometheus.New()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to create Prometheus exporter")
+			return nil, err
+		}
+		opts = append(opts, sdkmeter.WithReader(promExporter))
+	}
 
-	return meterProvider, nil
+	return sdkmeter.NewMeterProvider(opts...), nil
 }
 
 func initTracesProvider(ctx context.Context, target string, res *resource.Resource) (*sdktrace.TracerProvider, error) {
@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"context"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// TenantAttribute is the span attribute carrying the tenant ID; metrics use the
+// shorter "tenant" attribute.
+const TenantAttribute = "tenant.id"
+
+type tenantContextKey struct{}
+
+// ContextWithTenant stores the ID of the tenant work is done for in ctx and adds it to
+// the logger of ctx, so that spans, metrics and log lines derived from ctx are
+// attributed to the tenant. The default tenant has an empty ID and is not recorded.
+func ContextWithTenant(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	logger := zerolog.Ctx(ctx).With().Str("tenant_id", tenantID).Logger()
+	return context.WithValue(logger.WithContext(ctx), tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ID stored in ctx, empty for the default tenant.
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
+
+// withTenant returns the metric attributes with the tenant of ctx appended when set.
+func withTenant(ctx context.Context, attrs ...attribute.KeyValue) metric.MeasurementOption {
+	if tenantID := TenantFromContext(ctx); tenantID != "" {
+		attrs = append(attrs, attribute.String("tenant", tenantID))
+	}
+	return metric.WithAttributes(attrs...)
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"sr-api/internal/core/domain"
+)
+
+// ErrTranscriptNotFound is returned when no transcript exists for the requested ID.
+var ErrTranscriptNotFound = errors.New("transcript not found")
+
+// TranscriptStore persists transcripts keyed by their own transcript ID, several of
+// which may share one deduplicated audio object.
+type TranscriptStore interface {
+	Save(ctx context.Context, transcript domain.Transcript) error
+	Get(ctx context.Context, id string) (domain.Transcript, error)
+	List(ctx context.Context, query domain.TranscriptQuery) (domain.TranscriptPage, error)
+	// Delete removes the transcript, and its audio object unless another transcript
+	// still references it. The second return value reports whether the audio was removed.
+	Delete(ctx context.Context, id string) (domain.Transcript, bool, error)
+}
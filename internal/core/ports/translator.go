@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// Translator translates transcript text between languages.
+type Translator interface {
+	// Translate returns the translation of every text into target, in the same order.
+	// source is the ISO 639-1 code of the texts, or "auto" when unknown.
+	Translate(ctx context.Context, texts []string, source string, target string) ([]string, error)
+}
@@ -3,23 +3,27 @@ package main
 import (
 	"context"
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"os"
 	"sr-api/internal/adapters/handler"
+	"sr-api/internal/adapters/handler/middleware"
 	"sr-api/internal/config"
 	"sr-api/internal/core/ports/telemetry"
 )
 
 func main() {
 
-	// Set global log level to debug
-	zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
-	log.Logger = log.Output(os.Stderr)
+	log.Debug().Msg("Loading configuration...")
+	cfg := config.LoadConfig()
+	if cfg == nil {
+		log.Fatal().Msg("Configuration is nil after loading")
+	}
+	telemetry.SetupLogger(cfg.LogLevel, cfg.LogFormat)
+
 	// Initialize OpenTelemetry
-	shutdown, err := telemetry.SetupOTelSDK(context.Background())
+	shutdown, err := telemetry.SetupOTelSDK(context.Background(), cfg)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to set up OpenTelemetry")
 	}
@@ -28,25 +32,57 @@ func main() {
 			log.Fatal().Err(err).Msg("Failed to shut down OpenTelemetry properly")
 		}
 	}()
-	log.Debug().Msg("Loading configuration...")
-	cfg := config.LoadConfig()
-	if cfg == nil {
-		log.Fatal().Msg("Configuration is nil after loading")
-	}
-
 	dep, err := handler.NewUploadHandlerDependencies(cfg)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize dependencies")
 	}
 
+	// "sr-api reindex" rebuilds the persisted search index from the stored transcripts and exits.
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		if cfg.SearchIndexPath == "" {
+			log.Fatal().Msg("SEARCH_INDEX_PATH must be set to persist a rebuilt search index")
+		}
+		if err := dep.ReindexSearch(context.Background()); err != nil {
+			log.Fatal().Err(err).Msg("Failed to rebuild search index")
+		}
+		return
+	}
+
+	if err := dep.StartRetention(context.Background(), cfg); err != nil {
+		log.Fatal().Err(err).Msg("Failed to apply retention policy")
+	}
+	dep.StartSearchReindex(context.Background(), cfg)
+
 	log.Debug().Msg("Initializing server...")
 	r := gin.New()
 	log.Debug().Msg("Setting up OpenTelemetry middleware")
 	r.Use(otelgin.Middleware("sr-api"))
+	r.Use(middleware.RequestLogger(cfg))
+	r.Use(middleware.TenantResolver(dep.Tenants))
 
 	log.Debug().Msg("Setting up routes")
 	r.GET("/status", handler.StatusHandler)
 	r.POST("/upload", dep.UploadHandler)
+	r.POST("/upload/batch", dep.BatchUploadHandler)
+	r.POST("/uploads/presign", dep.PresignUploadHandler)
+	r.POST("/uploads/:id/complete", dep.CompleteUploadHandler)
+	r.POST("/transcribe", dep.TranscribeHandler)
+	r.GET("/requests/:id/progress", dep.ProgressHandler)
+	if dep.AuditSink != nil {
+		r.GET("/audit", dep.AuditHandler)
+	}
+	if dep.TranscriptStore != nil {
+		r.GET("/transcripts", dep.ListTranscriptsHandler)
+		r.GET("/transcripts/:id", dep.GetTranscriptHandler)
+		r.DELETE("/transcripts/:id", dep.DeleteTranscriptHandler)
+	}
+	if dep.SearchIndex != nil {
+		r.GET("/search", dep.SearchHandler)
+	}
+	if cfg.PrometheusEnabled {
+		log.Debug().Msg("Exposing Prometheus metrics endpoint")
+		r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
 
 	log.Debug().Msg("Starting server...")
 	if err := r.Run(":8080"); err != nil {
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"sr-api/internal/core/domain"
+	"strings"
+	"testing"
+)
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		io.WriteString(entry, content)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestZipEntries(t *testing.T) {
+	audio := "\xFF\xFB" + strings.Repeat("\x00", domain.SignatureLength)
+	data := buildZip(t, map[string]string{
+		"calls/":                 "",
+		"calls/a.mp3":            audio,
+		"calls/b.mp3":            audio,
+		"__MACOSX/calls/._a.mp3": "fork",
+	})
+	archive := bytes.NewReader(data)
+
+	if !domain.IsZipArchive(archive) {
+		t.Fatal("Expected archive to be detected")
+	}
+	if domain.IsZipArchive(strings.NewReader(audio)) {
+		t.Error("Expected audio not to be detected as an archive")
+	}
+
+	entries, err := domain.ZipEntries(archive, int64(len(data)), 10, 1<<20)
+	if err != nil {
+		t.Fatalf("Failed to list entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 audio entries, got: %d", len(entries))
+	}
+
+	file, err := domain.ExtractZipEntry(entries[0])
+	if err != nil {
+		t.Fatalf("Failed to extract entry: %v", err)
+	}
+	defer file.Close()
+	if _, err := domain.CheckFileSignatureWithContext(context.Background(), file); err != nil {
+		t.Errorf("Expected extracted entry to pass the signature check, got: %v", err)
+	}
+
+	if _, err := domain.ZipEntries(archive, int64(len(data)), 1, 1<<20); err == nil {
+		t.Error("Expected archive with too many files to be rejected")
+	}
+	if _, err := domain.ZipEntries(archive, int64(len(data)), 10, int64(len(audio))); err == nil {
+		t.Error("Expected archive expanding past the size limit to be rejected")
+	}
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sr-api/internal/core/domain"
+	"testing"
+)
+
+// buildGappedToneWav returns a 16 kHz mono tone of the given length in seconds with
+// half-second silent gaps starting at the given offsets.
+func buildGappedToneWav(seconds float64, gaps ...float64) []byte {
+	const rate = 16000
+	samples := int(seconds * rate)
+	wav := buildPCMWav(rate, 1, samples)
+	for i := 0; i < samples; i++ {
+		at := float64(i) / rate
+		silent := false
+		for _, gap := range gaps {
+			if at >= gap && at < gap+0.5 {
+				silent = true
+			}
+		}
+		if !silent {
+			value := int16(8000 * math.Sin(2*math.Pi*440*at))
+			binary.LittleEndian.PutUint16(wav[44+i*2:], uint16(value))
+		}
+	}
+	return wav
+}
+
+func TestPlanAudioChunks_CutsAtSilence(t *testing.T) {
+	file := &MockFile{content: string(buildGappedToneWav(25, 9, 18))}
+
+	chunks, err := domain.PlanAudioChunks(file, 10, 1)
+	if err != nil {
+		t.Fatalf("PlanAudioChunks failed: %v", err)
+	}
+	if file.offset != 0 {
+		t.Errorf("Expected reader to be rewound, offset: %d", file.offset)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	for i, gap := range []float64{9, 18} {
+		if cut := chunks[i].Cut; cut < gap || cut > gap+0.5 {
+			t.Errorf("Expected chunk %d to be cut in the silence at %.1fs, got %.3f", i, gap, cut)
+		}
+		if chunks[i].End <= chunks[i+1].Start {
+			t.Errorf("Expected chunks %d and %d to overlap: %+v", i, i+1, chunks)
+		}
+	}
+	if chunks[0].Start != 0 || chunks[2].End != 25 {
+		t.Errorf("Expected chunks to cover the recording: %+v", chunks)
+	}
+}
+
+func TestPlanAudioChunks_ShortRecording(t *testing.T) {
+	file := &MockFile{content: string(buildGappedToneWav(5))}
+
+	chunks, err := domain.PlanAudioChunks(file, 10, 1)
+	if err != nil {
+		t.Fatalf("PlanAudioChunks failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Start != 0 || chunks[0].End != 5 {
+		t.Errorf("Expected a single chunk, got: %+v", chunks)
+	}
+}
+
+func TestPlanAudioChunks_OverlapTooLarge(t *testing.T) {
+	file := &MockFile{content: string(buildGappedToneWav(30))}
+
+	if _, err := domain.PlanAudioChunks(file, 5, 10); !errors.Is(err, domain.ErrChunkOverlap) {
+		t.Errorf("Expected ErrChunkOverlap, got %v", err)
+	}
+}
+
+func TestExtractWAVChunk(t *testing.T) {
+	file := &MockFile{content: string(buildGappedToneWav(25))}
+
+	data, err := domain.ExtractWAVChunk(file, domain.AudioChunk{Start: 2, End: 4.5})
+	if err != nil {
+		t.Fatalf("ExtractWAVChunk failed: %v", err)
+	}
+	seconds, ok := domain.AudioDurationSeconds(&MockFile{content: string(data)})
+	if !ok || seconds != 2.5 {
+		t.Errorf("Expected a 2.5 second WAV chunk, got %f (ok=%t)", seconds, ok)
+	}
+	if string(data[44:48]) != file.content[44+2*16000*2:44+2*16000*2+4] {
+		t.Error("Expected chunk samples to start at the chunk offset")
+	}
+}
+
+func TestStitchTranscripts_Segments(t *testing.T) {
+	chunks := []domain.AudioChunk{
+		{Start: 0, End: 10.5, Cut: 10},
+		{Start: 9.5, End: 20, Cut: 20},
+	}
+	results := []domain.RecognitionSuccess{
+		{DetectedLang: "en", RecognizedText: "one two three", Segments: []domain.Segment{
+			{ID: 0, Start: 0, End: 5, Text: " one two"},
+			{ID: 1, Start: 9.8, End: 10.4, Text: " three", Words: []domain.Word{{Word: " three", Start: 9.8, End: 10.4}}},
+		}},
+		{DetectedLang: "en", RecognizedText: "three four", Segments: []domain.Segment{
+			{ID: 0, Start: 0.3, End: 0.9, Text: " three"},
+			{ID: 1, Start: 2, End: 4, Text: " four", Words: []domain.Word{{Word: " four", Start: 2, End: 4}}},
+		}},
+	}
+
+	stitched := domain.StitchTranscripts(results, chunks)
+
+	if stitched.RecognizedText != "one two three four" {
+		t.Errorf("Unexpected stitched text: %q", stitched.RecognizedText)
+	}
+	if len(stitched.Segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %d: %+v", len(stitched.Segments), stitched.Segments)
+	}
+	last := stitched.Segments[2]
+	if last.ID != 2 || last.Start != 11.5 || last.End != 13.5 || last.Words[0].Start != 11.5 {
+		t.Errorf("Expected the last segment to be renumbered and shifted, got: %+v", last)
+	}
+	if results[1].Segments[1].Start != 2 {
+		t.Error("Expected the chunk results to be left unchanged")
+	}
+}
+
+func TestStitchTranscripts_PlainText(t *testing.T) {
+	chunks := []domain.AudioChunk{{Start: 0, End: 10.5, Cut: 10}, {Start: 9.5, End: 20, Cut: 20}}
+	results := []domain.RecognitionSuccess{
+		{DetectedLang: "en", RecognizedText: "The quick brown fox jumps"},
+		{DetectedLang: "de", RecognizedText: "Fox jumps over the lazy dog."},
+		{DetectedLang: "en", RecognizedText: "Nothing in common."},
+	}
+
+	stitched := domain.StitchTranscripts(results, append(chunks, domain.AudioChunk{Start: 19.5, End: 25, Cut: 25}))
+
+	if stitched.RecognizedText != "The quick brown fox jumps over the lazy dog. Nothing in common." {
+		t.Errorf("Unexpected stitched text: %q", stitched.RecognizedText)
+	}
+	if stitched.DetectedLang != "en" {
+		t.Errorf("Expected the majority language, got: %s", stitched.DetectedLang)
+	}
+}
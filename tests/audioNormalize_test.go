@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sr-api/internal/core/domain"
+	"testing"
+)
+
+// buildToneWav returns a 16-bit PCM WAV file holding a quiet 440 Hz tone on every
+// channel, surrounded by the given number of silent samples.
+func buildToneWav(sampleRate, channels, silence, tone int) []byte {
+	wav := buildPCMWav(sampleRate, channels, silence*2+tone)
+	data := wav[44:]
+	for i := 0; i < tone; i++ {
+		value := int16(1000 * math.Sin(2*math.Pi*440*float64(i)/float64(sampleRate)))
+		for c := 0; c < channels; c++ {
+			binary.LittleEndian.PutUint16(data[((silence+i)*channels+c)*2:], uint16(value))
+		}
+	}
+	return wav
+}
+
+func TestNormalizeWAV_ResamplesDownmixesAndTrims(t *testing.T) {
+	file := &MockFile{content: string(buildToneWav(44100, 2, 44100, 44100))}
+
+	normalized, err := domain.NormalizeWAV(file)
+	if err != nil {
+		t.Fatalf("NormalizeWAV failed: %v", err)
+	}
+	if file.offset != 0 {
+		t.Errorf("Expected reader to be rewound, offset: %d", file.offset)
+	}
+
+	if channels := binary.LittleEndian.Uint16(normalized[22:24]); channels != 1 {
+		t.Errorf("Expected mono output, got %d channels", channels)
+	}
+	if rate := binary.LittleEndian.Uint32(normalized[24:28]); rate != domain.NormalizedSampleRate {
+		t.Errorf("Expected %d Hz output, got %d", domain.NormalizedSampleRate, rate)
+	}
+	seconds, ok := domain.AudioDurationSeconds(&MockFile{content: string(normalized)})
+	if !ok {
+		t.Fatal("Expected normalized output to be a WAV file")
+	}
+	// One second of tone with 100 ms of padding on either side.
+	if seconds < 1.1 || seconds > 1.3 {
+		t.Errorf("Expected silence to be trimmed to about 1.2 seconds, got: %f", seconds)
+	}
+
+	var peak, sum float64
+	samples := normalized[44:]
+	for i := 0; i+1 < len(samples); i += 2 {
+		value := float64(int16(binary.LittleEndian.Uint16(samples[i:]))) / 32768
+		peak = math.Max(peak, math.Abs(value))
+		sum += value * value
+	}
+	if peak > 0.9 {
+		t.Errorf("Expected peaks below -1 dBFS, got: %f", peak)
+	}
+	if rms := math.Sqrt(sum / float64(len(samples)/2)); rms < 0.05 {
+		t.Errorf("Expected quiet input to be amplified, RMS: %f", rms)
+	}
+}
+
+func TestNormalizeWAV_Silent(t *testing.T) {
+	file := &MockFile{content: string(buildPCMWav(16000, 1, 16000))}
+
+	if _, err := domain.NormalizeWAV(file); !errors.Is(err, domain.ErrSilentAudio) {
+		t.Errorf("Expected ErrSilentAudio, got: %v", err)
+	}
+}
+
+func TestNormalizeWAV_NotWav(t *testing.T) {
+	file := &MockFile{content: "\xFF\xFB" + string(make([]byte, 100))}
+
+	if _, err := domain.NormalizeWAV(file); !errors.Is(err, domain.ErrUnsupportedAudio) {
+		t.Errorf("Expected ErrUnsupportedAudio, got: %v", err)
+	}
+}
+
+func TestNormalizedObjectKey(t *testing.T) {
+	if key := domain.NormalizedObjectKey("tenant/2024/05/01/abc.mp3"); key != "tenant/2024/05/01/abc.normalized.wav" {
+		t.Errorf("Unexpected normalized key: %s", key)
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestAuditFileSinkAppendAndQuery(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sink, err := repository.NewAuditFileSink(path)
+	if err != nil {
+		t.Fatalf("Failed to create audit sink: %v", err)
+	}
+
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []domain.AuditRecord{
+		{ID: "1", Timestamp: base, Caller: "alice", Action: domain.AuditActionUpload, Outcome: domain.AuditOutcomeSuccess},
+		{ID: "2", Timestamp: base.Add(time.Hour), Caller: "bob", Action: domain.AuditActionUpload, Outcome: domain.AuditOutcomeRejected},
+		{ID: "3", Timestamp: base.Add(2 * time.Hour), Caller: "alice", Action: domain.AuditActionUpload, Outcome: domain.AuditOutcomeFailed},
+	}
+	for _, record := range records {
+		if err := sink.Append(ctx, record); err != nil {
+			t.Fatalf("Failed to append audit record: %v", err)
+		}
+	}
+
+	// A new sink on the same file must keep earlier records.
+	reopened, err := repository.NewAuditFileSink(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen audit sink: %v", err)
+	}
+
+	byCaller, err := reopened.Query(ctx, domain.AuditQuery{Caller: "alice"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(byCaller) != 2 || byCaller[0].ID != "3" || byCaller[1].ID != "1" {
+		t.Errorf("Expected records 3 and 1 for alice, got: %+v", byCaller)
+	}
+
+	byRange, err := reopened.Query(ctx, domain.AuditQuery{From: base.Add(30 * time.Minute), To: base.Add(90 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(byRange) != 1 || byRange[0].ID != "2" {
+		t.Errorf("Expected only record 2 in range, got: %+v", byRange)
+	}
+
+	limited, err := reopened.Query(ctx, domain.AuditQuery{Limit: 1})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(limited) != 1 || limited[0].ID != "3" {
+		t.Errorf("Expected the newest record only, got: %+v", limited)
+	}
+}
+
+func TestAuditMinioSinkQueriesNewestFirstWithinTheRange(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeObjectStore(t)
+	sink, err := repository.NewAuditMinioSink(newFakeMinioRepository(t, store).Client, "audio", "audit")
+	if err != nil {
+		t.Fatalf("Failed to create audit sink: %v", err)
+	}
+
+	now := time.Now().UTC()
+	records := []domain.AuditRecord{
+		{ID: "expired", Timestamp: now.Add(-repository.MaxAuditQueryRange - 24*time.Hour), Caller: "alice"},
+		{ID: "1", Timestamp: now.Add(-48 * time.Hour), Caller: "alice"},
+		{ID: "2", Timestamp: now.Add(-24 * time.Hour), Caller: "bob"},
+		{ID: "3", Timestamp: now.Add(-time.Hour), Caller: "alice"},
+		{ID: "4", Timestamp: now.Add(-time.Minute), Caller: "alice"},
+	}
+	for _, record := range records {
+		if err := sink.Append(ctx, record); err != nil {
+			t.Fatalf("Failed to append audit record: %v", err)
+		}
+	}
+
+	store.readKeys()
+	limited, err := sink.Query(ctx, domain.AuditQuery{Caller: "alice", Limit: 2})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(limited) != 2 || limited[0].ID != "4" || limited[1].ID != "3" {
+		t.Errorf("Expected the two newest records of alice, got: %+v", limited)
+	}
+	if reads := store.readKeys(); len(reads) != 2 {
+		t.Errorf("Expected only the returned records to be read, got %v", reads)
+	}
+
+	all, err := sink.Query(ctx, domain.AuditQuery{Caller: "alice"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(all) != 3 || all[2].ID != "1" {
+		t.Errorf("Expected the records of alice within the default range, got: %+v", all)
+	}
+
+	byRange, err := sink.Query(ctx, domain.AuditQuery{From: now.Add(-30 * time.Hour), To: now.Add(-30 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(byRange) != 2 || byRange[0].ID != "3" || byRange[1].ID != "2" {
+		t.Errorf("Expected records 3 and 2 in range, got: %+v", byRange)
+	}
+}
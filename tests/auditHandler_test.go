@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sr-api/internal/adapters/handler"
+	"sr-api/internal/adapters/handler/middleware"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func newAuditRouter(t *testing.T) *gin.Engine {
+	sink, err := repository.NewAuditFileSink(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to create audit sink: %v", err)
+	}
+	now := time.Now().UTC()
+	for i, caller := range []string{"alice", "bob", domain.AnonymousCaller} {
+		record := domain.AuditRecord{ID: caller, Timestamp: now.Add(time.Duration(i) * time.Second), Caller: caller, Action: domain.AuditActionUpload, Outcome: domain.AuditOutcomeSuccess}
+		if err := sink.Append(context.Background(), record); err != nil {
+			t.Fatalf("Failed to append audit record: %v", err)
+		}
+	}
+	registry, err := domain.NewTenantRegistry(domain.Tenant{Bucket: "audio"}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create tenant registry: %v", err)
+	}
+	dep := &handler.UploadHandlerDependencies{AuditSink: sink, AuditAdmins: []string{"admin"}, Tenants: registry}
+	cfg := &config.AppConfig{APIKeys: map[string]string{"alice-key": "alice", "admin-key": "admin"}}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestLogger(cfg))
+	r.Use(middleware.TenantResolver(dep.Tenants))
+	r.GET("/audit", dep.AuditHandler)
+	return r
+}
+
+func queryAudit(r *gin.Engine, apiKey string, url string) (int, []domain.AuditRecord) {
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if apiKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var body struct {
+		Records []domain.AuditRecord `json:"records"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body.Records
+}
+
+func TestAuditHandlerRestrictsCallersToTheirRecords(t *testing.T) {
+	r := newAuditRouter(t)
+
+	code, records := queryAudit(r, "alice-key", "/audit")
+	if code != http.StatusOK || len(records) != 1 || records[0].Caller != "alice" {
+		t.Errorf("Expected only alice's record, got %d %+v", code, records)
+	}
+	if code, _ := queryAudit(r, "alice-key", "/audit?caller=bob"); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for another caller's records, got %d", code)
+	}
+	if code, _ := queryAudit(r, "", "/audit"); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for anonymous callers, got %d", code)
+	}
+	// Unknown keys are identified by their fingerprint and see no other records.
+	if code, records := queryAudit(r, "unknown-key", "/audit?caller=admin"); code != http.StatusForbidden || len(records) != 0 {
+		t.Errorf("Expected 403 for an unknown key, got %d %+v", code, records)
+	}
+}
+
+func TestAuditHandlerAdminsReadEveryCaller(t *testing.T) {
+	r := newAuditRouter(t)
+
+	if code, records := queryAudit(r, "admin-key", "/audit"); code != http.StatusOK || len(records) != 3 {
+		t.Errorf("Expected every record for an admin, got %d %+v", code, records)
+	}
+	code, records := queryAudit(r, "admin-key", "/audit?caller=bob")
+	if code != http.StatusOK || len(records) != 1 || records[0].Caller != "bob" {
+		t.Errorf("Expected bob's record, got %d %+v", code, records)
+	}
+}
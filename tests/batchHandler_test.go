@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/core/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchUploadReportsEachFile(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, dep := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+	archive := buildZip(t, map[string]string{"inner.wav": string(testWAV(1600, 3))})
+
+	var response handlerStructure.BatchResponse
+	w := serve(t, r, multipartRequest(t, "/upload/batch", "alice-key", []multipartFile{
+		{"files", "a.wav", testWAV(1600, 1)},
+		{"files", "broken.wav", []byte("not audio at all")},
+		{"files", "calls.zip", archive},
+		{"file", "b.wav", testWAV(1600, 2)},
+	}, nil), &response)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if response.Succeeded != 3 || response.Failed != 1 || len(response.Results) != 4 {
+		t.Fatalf("Expected 3 succeeded and 1 failed file, got %+v", response)
+	}
+	expected := []struct {
+		filename string
+		archive  string
+		status   int
+	}{
+		{"a.wav", "", http.StatusOK},
+		{"broken.wav", "", http.StatusBadRequest},
+		{"inner.wav", "calls.zip", http.StatusOK},
+		{"b.wav", "", http.StatusOK},
+	}
+	for i, want := range expected {
+		result := response.Results[i]
+		if result.Filename != want.filename || result.Archive != want.archive || result.Status != want.status {
+			t.Errorf("Expected result %d for %s with status %d, got %+v", i, want.filename, want.status, result)
+		}
+		if (result.Result != nil) != (want.status == http.StatusOK) || (result.Error != "") == (want.status == http.StatusOK) {
+			t.Errorf("Expected result %d to carry either a transcription or an error, got %+v", i, result)
+		}
+	}
+
+	records, err := dep.AuditSink.Query(domain.ContextWithCaller(context.Background(), domain.Caller{ID: "alice"}), domain.AuditQuery{Caller: "alice"})
+	if err != nil {
+		t.Fatalf("Failed to query audit records: %v", err)
+	}
+	outcomes := make(map[string]string)
+	for _, record := range records {
+		if record.Action == domain.AuditActionUpload {
+			outcomes[record.OriginalFilename] = record.Outcome + "/" + record.Source
+		}
+	}
+	want := map[string]string{
+		"a.wav":      domain.AuditOutcomeSuccess + "/",
+		"broken.wav": domain.AuditOutcomeRejected + "/",
+		"inner.wav":  domain.AuditOutcomeSuccess + "/calls.zip",
+		"b.wav":      domain.AuditOutcomeSuccess + "/",
+	}
+	if len(outcomes) != len(want) {
+		t.Errorf("Expected one audit record per file, got %v", outcomes)
+	}
+	for filename, outcome := range want {
+		if outcomes[filename] != outcome {
+			t.Errorf("Expected audit outcome %q for %s, got %q", outcome, filename, outcomes[filename])
+		}
+	}
+}
+
+func TestBatchUploadBoundsConcurrency(t *testing.T) {
+	store := newFakeObjectStore(t)
+	var mu sync.Mutex
+	running, peak := 0, 0
+	whisper := newFakeWhisper(t, store, func([]byte) string {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return "hello"
+	})
+	cfg := newFakeServicesConfig(t, store, whisper)
+	r, _ := newTestRouter(t, cfg)
+
+	var files []multipartFile
+	for seed := byte(1); seed <= 6; seed++ {
+		files = append(files, multipartFile{"files", "a.wav", testWAV(1600, seed)})
+	}
+	var response handlerStructure.BatchResponse
+	if w := serve(t, r, multipartRequest(t, "/upload/batch", "alice-key", files, nil), &response); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if response.Succeeded != len(files) {
+		t.Fatalf("Expected every file to succeed, got %+v", response)
+	}
+	if peak < 1 || peak > cfg.BatchConcurrency {
+		t.Errorf("Expected at most %d concurrent transcriptions, got %d", cfg.BatchConcurrency, peak)
+	}
+}
+
+func TestBatchUploadRejectsTooManyFiles(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	cfg := newFakeServicesConfig(t, store, whisper)
+	cfg.BatchMaxFiles = 2
+	r, _ := newTestRouter(t, cfg)
+
+	w := serve(t, r, multipartRequest(t, "/upload/batch", "alice-key", []multipartFile{
+		{"files", "a.wav", testWAV(1600, 1)},
+		{"files", "calls.zip", buildZip(t, map[string]string{"b.wav": string(testWAV(1600, 2)), "c.wav": string(testWAV(1600, 3))})},
+	}, nil), nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(whisper.fileNames()) != 0 {
+		t.Errorf("Expected nothing to be transcribed, got %v", whisper.fileNames())
+	}
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sr-api/internal/adapters/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startFakeClamd serves the INSTREAM command on a local port, passing the streamed
+// content to reply and sending back its answer. A nil reply never answers.
+func startFakeClamd(t *testing.T, reply func(content []byte) string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				command := make([]byte, len("zINSTREAM\x00"))
+				if _, err := io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+					return
+				}
+				var content bytes.Buffer
+				size := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(conn, size); err != nil {
+						return
+					}
+					n := binary.BigEndian.Uint32(size)
+					if n == 0 {
+						break
+					}
+					if _, err := io.CopyN(&content, conn, int64(n)); err != nil {
+						return
+					}
+				}
+				if reply == nil {
+					io.Copy(io.Discard, conn)
+					return
+				}
+				conn.Write([]byte(reply(content.Bytes()) + "\x00"))
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func eicarReply(content []byte) string {
+	if bytes.Contains(content, []byte("EICAR")) {
+		return "stream: Eicar-Test-Signature FOUND"
+	}
+	return "stream: OK"
+}
+
+func TestClamAVScanner_Clean(t *testing.T) {
+	received := make(chan int, 1)
+	address := startFakeClamd(t, func(content []byte) string {
+		received <- len(content)
+		return eicarReply(content)
+	})
+	scanner := repository.NewClamAVScanner(address, 5*time.Second)
+
+	// Larger than one chunk, so that the stream is split.
+	content := bytes.Repeat([]byte("a"), 200<<10)
+	result, err := scanner.Scan(context.Background(), bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if result.Infected {
+		t.Error("Expected clean content")
+	}
+	if n := <-received; n != len(content) {
+		t.Errorf("Expected clamd to receive %d bytes, got %d", len(content), n)
+	}
+}
+
+func TestClamAVScanner_Infected(t *testing.T) {
+	scanner := repository.NewClamAVScanner(startFakeClamd(t, eicarReply), 5*time.Second)
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader("X5O!P%@AP EICAR test"))
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if !result.Infected || result.Signature != "Eicar-Test-Signature" {
+		t.Errorf("Expected the EICAR signature, got: %+v", result)
+	}
+}
+
+func TestClamAVScanner_ErrorReply(t *testing.T) {
+	scanner := repository.NewClamAVScanner(startFakeClamd(t, func([]byte) string {
+		return "INSTREAM size limit exceeded. ERROR"
+	}), 5*time.Second)
+
+	if _, err := scanner.Scan(context.Background(), strings.NewReader("content")); err == nil || !strings.Contains(err.Error(), "size limit") {
+		t.Errorf("Expected the clamd error, got: %v", err)
+	}
+}
+
+func TestClamAVScanner_Unavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	scanner := repository.NewClamAVScanner(address, 5*time.Second)
+	if _, err := scanner.Scan(context.Background(), strings.NewReader("content")); err == nil {
+		t.Error("Expected an error for an unreachable clamd")
+	}
+}
+
+func TestClamAVScanner_Timeout(t *testing.T) {
+	scanner := repository.NewClamAVScanner(startFakeClamd(t, nil), 200*time.Millisecond)
+
+	started := time.Now()
+	if _, err := scanner.Scan(context.Background(), strings.NewReader("content")); err == nil {
+		t.Error("Expected a timeout error")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("Expected the scan to give up after the timeout, took %s", elapsed)
+	}
+}
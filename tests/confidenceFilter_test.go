@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"encoding/json"
+	"sr-api/internal/core/domain"
+	"testing"
+)
+
+const verboseWhisperResponse = `{
+	"detected_language": "en",
+	"recognized_text": "Call me tomorrow. Thanks.",
+	"segments": [
+		{"id": 0, "start": 0.0, "end": 1.8, "text": " Call me tomorrow.", "no_speech_prob": 0.01, "words": [
+			{"word": " Call", "start": 0.0, "end": 0.4, "probability": 0.98},
+			{"word": " me", "start": 0.4, "end": 0.6, "probability": 0.95},
+			{"word": " tomorrow.", "start": 0.6, "end": 1.8, "probability": 0.31}
+		]},
+		{"id": 1, "start": 2.0, "end": 2.5, "text": " Thanks.", "no_speech_prob": 0.2, "words": [
+			{"word": " Thanks.", "start": 2.0, "end": 2.5, "probability": 0.9}
+		]}
+	]
+}`
+
+func TestApplyConfidenceFilter(t *testing.T) {
+	var result domain.RecognitionSuccess
+	if err := json.Unmarshal([]byte(verboseWhisperResponse), &result); err != nil {
+		t.Fatalf("Failed to decode verbose response: %v", err)
+	}
+	if len(result.Segments) != 2 || len(result.Segments[0].Words) != 3 || result.Segments[1].NoSpeechProb != 0.2 {
+		t.Fatalf("Unexpected segments: %+v", result.Segments)
+	}
+
+	marked := domain.ApplyConfidenceFilter(result, domain.ConfidenceFilter{MinConfidence: 0.5, Action: domain.ConfidenceActionMark})
+	if !marked.Segments[0].Words[2].LowConfidence || marked.Segments[0].Words[0].LowConfidence {
+		t.Errorf("Expected only 'tomorrow.' to be marked, got: %+v", marked.Segments[0].Words)
+	}
+	if marked.RecognizedText != result.RecognizedText {
+		t.Errorf("Expected marking to keep the text, got: '%s'", marked.RecognizedText)
+	}
+
+	redacted := domain.ApplyConfidenceFilter(result, domain.ConfidenceFilter{MinConfidence: 0.5, Action: domain.ConfidenceActionRedact})
+	if redacted.RecognizedText != "Call me [inaudible] Thanks." {
+		t.Errorf("Expected redacted text, got: '%s'", redacted.RecognizedText)
+	}
+	if redacted.Segments[0].Text != " Call me [inaudible]" {
+		t.Errorf("Expected redacted segment, got: '%s'", redacted.Segments[0].Text)
+	}
+
+	if result.Segments[0].Words[2].LowConfidence || result.Segments[0].Words[2].Word != " tomorrow." {
+		t.Error("Expected the original result, e.g. a cached one, to be left untouched")
+	}
+}
+
+func TestParseConfidenceFilter(t *testing.T) {
+	filter, err := domain.ParseConfidenceFilter(formLookup(map[string]string{"min_confidence": "0.4"}))
+	if err != nil || filter.MinConfidence != 0.4 || filter.Action != domain.ConfidenceActionMark {
+		t.Errorf("Expected marking at 0.4, got: %+v, %v", filter, err)
+	}
+	if _, err := domain.ParseConfidenceFilter(formLookup(map[string]string{"min_confidence": "2"})); err == nil {
+		t.Error("Expected out of range confidence to be rejected")
+	}
+	if _, err := domain.ParseConfidenceFilter(formLookup(map[string]string{"min_confidence": "NaN"})); err == nil {
+		t.Error("Expected NaN confidence to be rejected")
+	}
+	if _, err := domain.ParseConfidenceFilter(formLookup(map[string]string{"confidence_action": "drop"})); err == nil {
+		t.Error("Expected unknown action to be rejected")
+	}
+}
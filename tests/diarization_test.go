@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"testing"
+	"time"
+)
+
+// stubDiarizer returns fixed speaker turns whatever the object.
+type stubDiarizer struct {
+	turns []domain.SpeakerTurn
+}
+
+var _ ports.Diarizer = stubDiarizer{}
+
+func (stub stubDiarizer) Diarize(ctx context.Context, objectKey string, numSpeakers int) ([]domain.SpeakerTurn, error) {
+	return stub.turns, nil
+}
+
+var callTurns = stubDiarizer{turns: []domain.SpeakerTurn{
+	{Start: 0, End: 3.1, Speaker: "AGENT"},
+	{Start: 3.3, End: 6, Speaker: "CUSTOMER"},
+	{Start: 7, End: 9, Speaker: "AGENT"},
+}}
+
+func TestAssignSpeakers_SplitsSegmentsAtSpeakerChanges(t *testing.T) {
+	turns, _ := callTurns.Diarize(context.Background(), "call.wav", 2)
+	result := domain.RecognitionSuccess{
+		RecognizedText: "Hello, how can I help? My card is blocked.",
+		Segments: []domain.Segment{{
+			ID: 0, Start: 0, End: 5.5, Text: " Hello, how can I help? My card is blocked.",
+			Words: []domain.Word{
+				{Word: " Hello,", Start: 0.2, End: 0.8},
+				{Word: " how", Start: 1, End: 1.3},
+				{Word: " can", Start: 1.3, End: 1.6},
+				{Word: " I", Start: 1.6, End: 1.8},
+				{Word: " help?", Start: 1.8, End: 3.0},
+				{Word: " My", Start: 3.4, End: 3.6},
+				{Word: " card", Start: 3.6, End: 4},
+				{Word: " is", Start: 4, End: 4.2},
+				{Word: " blocked.", Start: 4.2, End: 5.2},
+			},
+		}},
+	}
+
+	labelled := domain.AssignSpeakers(result, turns)
+
+	if len(labelled.Segments) != 2 {
+		t.Fatalf("Expected the segment to be split in 2, got %+v", labelled.Segments)
+	}
+	agent, customer := labelled.Segments[0], labelled.Segments[1]
+	if agent.Speaker != "AGENT" || agent.Text != " Hello, how can I help?" || agent.Start != 0 || agent.End != 3.0 || agent.ID != 0 {
+		t.Errorf("Unexpected first segment: %+v", agent)
+	}
+	if customer.Speaker != "CUSTOMER" || customer.Text != " My card is blocked." || customer.Start != 3.4 || customer.End != 5.5 || customer.ID != 1 {
+		t.Errorf("Unexpected second segment: %+v", customer)
+	}
+	if labelled.RecognizedText != result.RecognizedText {
+		t.Errorf("Expected the recognized text to be unchanged, got %q", labelled.RecognizedText)
+	}
+	if len(result.Segments) != 1 || result.Segments[0].Speaker != "" {
+		t.Error("Expected the input result to be left untouched")
+	}
+}
+
+func TestAssignSpeakers_SegmentsWithoutWords(t *testing.T) {
+	result := domain.RecognitionSuccess{Segments: []domain.Segment{
+		{ID: 0, Start: 0, End: 3.5, Text: " Hello."},
+		{ID: 1, Start: 3, End: 6.5, Text: " Hi."},
+		{ID: 2, Start: 6.6, End: 6.9, Text: " Hm."},
+		{ID: 3, Start: 12, End: 13, Text: " Bye."},
+	}}
+
+	labelled := domain.AssignSpeakers(result, callTurns.turns)
+
+	for i, want := range []string{"AGENT", "CUSTOMER", "AGENT", "AGENT"} {
+		if got := labelled.Segments[i].Speaker; got != want {
+			t.Errorf("Expected segment %d to be attributed to %s, got %q", i, want, got)
+		}
+	}
+	if unlabelled := domain.AssignSpeakers(result, nil); unlabelled.Segments[0].Speaker != "" {
+		t.Error("Expected no speakers without turns")
+	}
+}
+
+func TestHTTPDiarizer_Diarize(t *testing.T) {
+	var request handlerStructure.DiarizeData
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"segments": [
+			{"start": 3.2, "end": 6.0, "speaker": "SPEAKER_01"},
+			{"start": 0.0, "end": 3.1, "speaker": "SPEAKER_00"},
+			{"start": 6.0, "end": 6.0, "speaker": "SPEAKER_00"}
+		]}`))
+	}))
+	defer server.Close()
+
+	diarizer, err := repository.NewHTTPDiarizer(server.URL+"/diarize", "audio", time.Second)
+	if err != nil {
+		t.Fatalf("NewHTTPDiarizer failed: %v", err)
+	}
+	turns, err := diarizer.Diarize(context.Background(), "call.wav", 2)
+	if err != nil {
+		t.Fatalf("Diarize failed: %v", err)
+	}
+	if request.BucketName != "audio" || request.FileName != "call.wav" || request.NumSpeakers != 2 {
+		t.Errorf("Unexpected request: %+v", request)
+	}
+	if len(turns) != 2 || turns[0].Speaker != "SPEAKER_00" || turns[1].Speaker != "SPEAKER_01" {
+		t.Errorf("Expected 2 turns in time order without the empty one, got %+v", turns)
+	}
+}
+
+func TestHTTPDiarizer_ServiceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	diarizer, err := repository.NewHTTPDiarizer(server.URL, "audio", time.Second)
+	if err != nil {
+		t.Fatalf("NewHTTPDiarizer failed: %v", err)
+	}
+	if _, err := diarizer.Diarize(context.Background(), "call.wav", 0); err == nil {
+		t.Error("Expected an error for a failing service")
+	}
+	if _, err := repository.NewHTTPDiarizer("", "audio", time.Second); err == nil {
+		t.Error("Expected an error for a missing endpoint")
+	}
+}
+
+func TestRenderSubtitles_PrefixSpeakers(t *testing.T) {
+	segments := []domain.Segment{
+		{Start: 0, End: 3, Text: " Hello, how can I help?", Speaker: "AGENT"},
+		{Start: 3.4, End: 3725.5, Text: " My card is blocked."},
+	}
+
+	srt := domain.RenderSRT(segments)
+	wantSRT := "1\n00:00:00,000 --> 00:00:03,000\nAGENT: Hello, how can I help?\n\n" +
+		"2\n00:00:03,400 --> 01:02:05,500\nMy card is blocked.\n\n"
+	if srt != wantSRT {
+		t.Errorf("Unexpected SRT:\n%s", srt)
+	}
+
+	vtt := domain.RenderVTT(segments)
+	wantVTT := "WEBVTT\n\n00:00:00.000 --> 00:00:03.000\n<v AGENT>Hello, how can I help?\n\n" +
+		"00:00:03.400 --> 01:02:05.500\nMy card is blocked.\n\n"
+	if vtt != wantVTT {
+		t.Errorf("Unexpected VTT:\n%s", vtt)
+	}
+}
@@ -0,0 +1,426 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeObject is an object held by fakeObjectStore.
+type fakeObject struct {
+	content      []byte
+	contentType  string
+	userMetadata map[string]string
+	tags         map[string]string
+	modified     time.Time
+}
+
+// fakeObjectStore is an in-memory S3 server covering the path-style requests the MinIO
+// client makes: object put, copy, get, stat, delete and tagging, bucket listing and
+// lifecycle configuration.
+type fakeObjectStore struct {
+	server *httptest.Server
+
+	mu         sync.Mutex
+	objects    map[string]*fakeObject
+	lifecycles map[string]string
+	puts       int
+	reads      []string
+}
+
+func newFakeObjectStore(t *testing.T) *fakeObjectStore {
+	store := &fakeObjectStore{objects: make(map[string]*fakeObject), lifecycles: make(map[string]string)}
+	store.server = httptest.NewServer(http.HandlerFunc(store.serve))
+	t.Cleanup(store.server.Close)
+	return store
+}
+
+// readKeys returns the keys of the objects downloaded since the last call.
+func (store *fakeObjectStore) readKeys() []string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	reads := store.reads
+	store.reads = nil
+	return reads
+}
+
+// endpoint returns the host:port the MinIO client connects to.
+func (store *fakeObjectStore) endpoint() string {
+	return strings.TrimPrefix(store.server.URL, "http://")
+}
+
+func (store *fakeObjectStore) put(bucket string, key string, object *fakeObject) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if object.modified.IsZero() {
+		object.modified = time.Now().UTC()
+	}
+	store.objects[bucket+"/"+key] = object
+}
+
+func (store *fakeObjectStore) get(bucket string, key string) (*fakeObject, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	object, ok := store.objects[bucket+"/"+key]
+	return object, ok
+}
+
+// keys returns the sorted keys of the bucket starting with prefix.
+func (store *fakeObjectStore) keys(bucket string, prefix string) []string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	var keys []string
+	for name := range store.objects {
+		if key, ok := strings.CutPrefix(name, bucket+"/"); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (store *fakeObjectStore) serve(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+	switch {
+	case key == "" && query.Has("location"):
+		writeXML(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"LocationConstraint"`
+			Region  string   `xml:",chardata"`
+		}{Region: "us-east-1"})
+	case key == "" && query.Has("lifecycle"):
+		store.serveLifecycle(w, r, bucket)
+	case key == "" && r.Method == http.MethodGet:
+		store.serveList(w, bucket, query)
+	case query.Has("tagging"):
+		store.serveTagging(w, r, bucket, key)
+	case r.Method == http.MethodPut:
+		store.servePut(w, r, bucket, key)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := store.get(bucket, key)
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		if r.Method == http.MethodGet {
+			store.mu.Lock()
+			store.reads = append(store.reads, key)
+			store.mu.Unlock()
+		}
+		for name, value := range object.userMetadata {
+			w.Header().Set("X-Amz-Meta-"+name, value)
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("ETag", etag(object.content))
+		http.ServeContent(w, r, key, object.modified, bytes.NewReader(object.content))
+	case r.Method == http.MethodDelete:
+		store.mu.Lock()
+		delete(store.objects, bucket+"/"+key)
+		store.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (store *fakeObjectStore) servePut(w http.ResponseWriter, r *http.Request, bucket string, key string) {
+	object := &fakeObject{contentType: r.Header.Get("Content-Type"), userMetadata: userMetadata(r.Header), tags: parseTags(r.Header.Get("X-Amz-Tagging"))}
+	if source := r.Header.Get("X-Amz-Copy-Source"); source != "" {
+		source, _ = url.PathUnescape(source)
+		sourceBucket, sourceKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+		original, ok := store.get(sourceBucket, sourceKey)
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		if match := r.Header.Get("X-Amz-Copy-Source-If-Match"); match != "" && strings.Trim(match, `"`) != strings.Trim(etag(original.content), `"`) {
+			writeS3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+		object.content = original.content
+		if r.Header.Get("X-Amz-Metadata-Directive") != "REPLACE" {
+			object.contentType, object.userMetadata = original.contentType, original.userMetadata
+		}
+		if r.Header.Get("X-Amz-Tagging-Directive") != "REPLACE" {
+			object.tags = original.tags
+		}
+		store.put(bucket, key, object)
+		writeXML(w, http.StatusOK, struct {
+			XMLName      xml.Name `xml:"CopyObjectResult"`
+			ETag         string
+			LastModified string
+		}{ETag: etag(object.content), LastModified: object.modified.Format(time.RFC3339)})
+		return
+	}
+
+	body, err := readS3Body(r)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+	object.content = body
+	store.put(bucket, key, object)
+	store.mu.Lock()
+	store.puts++
+	store.mu.Unlock()
+	w.Header().Set("ETag", etag(body))
+	w.WriteHeader(http.StatusOK)
+}
+
+// fakeMetadataItem is a user metadata entry of a listing, named after its header.
+type fakeMetadataItem struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type fakeListEntry struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int
+	UserMetadata *struct {
+		Items []fakeMetadataItem
+	} `xml:",omitempty"`
+}
+
+func (store *fakeObjectStore) serveList(w http.ResponseWriter, bucket string, query url.Values) {
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		MaxKeys     int
+		IsTruncated bool
+		Contents    []fakeListEntry
+	}{Name: bucket, Prefix: query.Get("prefix"), MaxKeys: 1000}
+	for _, key := range store.keys(bucket, query.Get("prefix")) {
+		object, _ := store.get(bucket, key)
+		entry := fakeListEntry{Key: key, LastModified: object.modified.Format(time.RFC3339Nano), ETag: etag(object.content), Size: len(object.content)}
+		if query.Get("metadata") == "true" {
+			entry.UserMetadata = &struct{ Items []fakeMetadataItem }{}
+			for name, value := range object.userMetadata {
+				entry.UserMetadata.Items = append(entry.UserMetadata.Items, fakeMetadataItem{XMLName: xml.Name{Local: "X-Amz-Meta-" + name}, Value: value})
+			}
+		}
+		result.Contents = append(result.Contents, entry)
+	}
+	result.KeyCount = len(result.Contents)
+	writeXML(w, http.StatusOK, result)
+}
+
+func (store *fakeObjectStore) serveTagging(w http.ResponseWriter, r *http.Request, bucket string, key string) {
+	object, ok := store.get(bucket, key)
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+	type tag struct{ Key, Value string }
+	if r.Method == http.MethodGet {
+		var tags []tag
+		for name, value := range object.tags {
+			tags = append(tags, tag{name, value})
+		}
+		writeXML(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"Tagging"`
+			TagSet  []tag    `xml:"TagSet>Tag"`
+		}{TagSet: tags})
+		return
+	}
+	var tagging struct {
+		TagSet []tag `xml:"TagSet>Tag"`
+	}
+	body, _ := readS3Body(r)
+	xml.Unmarshal(body, &tagging)
+	store.mu.Lock()
+	object.tags = make(map[string]string)
+	for _, tag := range tagging.TagSet {
+		object.tags[tag.Key] = tag.Value
+	}
+	store.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (store *fakeObjectStore) serveLifecycle(w http.ResponseWriter, r *http.Request, bucket string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		configuration, ok := store.lifecycles[bucket]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchLifecycleConfiguration")
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		io.WriteString(w, configuration)
+	case http.MethodPut:
+		body, _ := readS3Body(r)
+		store.lifecycles[bucket] = string(body)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(store.lifecycles, bucket)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// readS3Body returns the payload of a request, decoding the aws-chunked encoding the
+// MinIO client streams over plain HTTP.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	reader := bufio.NewReader(r.Body)
+	var body []byte
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return body, nil
+		}
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err
+		}
+		body = append(body, chunk[:size]...)
+	}
+}
+
+func userMetadata(header http.Header) map[string]string {
+	metadata := make(map[string]string)
+	for name := range header {
+		if key, ok := strings.CutPrefix(name, "X-Amz-Meta-"); ok {
+			metadata[key] = header.Get(name)
+		}
+	}
+	return metadata
+}
+
+func parseTags(raw string) map[string]string {
+	tags := make(map[string]string)
+	values, _ := url.ParseQuery(raw)
+	for key := range values {
+		tags[key] = values.Get(key)
+	}
+	return tags
+}
+
+func etag(content []byte) string {
+	sum := md5.Sum(content)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func writeXML(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(code)
+	xml.NewEncoder(w).Encode(value)
+}
+
+func writeS3Error(w http.ResponseWriter, code int, errorCode string) {
+	writeXML(w, code, struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: errorCode, Message: errorCode})
+}
+
+// fakeWhisper answers transcription requests with the text returned by transcribe for
+// the requested object, read from the object store.
+type fakeWhisper struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []fakeWhisperRequest
+}
+
+type fakeWhisperRequest struct {
+	Bucket   string `json:"bucket"`
+	FileName string `json:"file_name"`
+}
+
+func newFakeWhisper(t *testing.T, store *fakeObjectStore, transcribe func(content []byte) string) *fakeWhisper {
+	whisper := &fakeWhisper{}
+	whisper.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request fakeWhisperRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		whisper.mu.Lock()
+		whisper.requests = append(whisper.requests, request)
+		whisper.mu.Unlock()
+		object, ok := store.get(request.Bucket, request.FileName)
+		if !ok {
+			http.Error(w, "no such object", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(domain.RecognitionSuccess{DetectedLang: "en", RecognizedText: transcribe(object.content)})
+	}))
+	t.Cleanup(whisper.server.Close)
+	return whisper
+}
+
+func (whisper *fakeWhisper) fileNames() []string {
+	whisper.mu.Lock()
+	defer whisper.mu.Unlock()
+	var names []string
+	for _, request := range whisper.requests {
+		names = append(names, request.FileName)
+	}
+	return names
+}
+
+// newFakeServicesConfig returns a configuration using the fake object store and Whisper
+// service, with a file audit sink and without optional services.
+func newFakeServicesConfig(t *testing.T, store *fakeObjectStore, whisper *fakeWhisper) *config.AppConfig {
+	return &config.AppConfig{
+		MinioEndpoint:           store.endpoint(),
+		MinioAccessKey:          "access",
+		MinioSecretKey:          "secret",
+		MinioBucket:             "audio",
+		WhisperEndpoint:         whisper.server.URL,
+		WhisperTranscribe:       "/transcribe",
+		AuditSink:               "file",
+		AuditFilePath:           filepath.Join(t.TempDir(), "audit.jsonl"),
+		ResultCacheSize:         100,
+		ResultCacheTTL:          time.Hour,
+		TranscriptStore:         "minio",
+		SearchIndexEnabled:      true,
+		MediaCheckExtension:     true,
+		MediaCheckStructure:     true,
+		BatchMaxFiles:           10,
+		BatchConcurrency:        2,
+		BatchMaxArchiveBytes:    1 << 20,
+		PresignExpiry:           time.Minute,
+		TranscribeSourceBuckets: []string{"audio", "source"},
+		APIKeys:                 map[string]string{"alice-key": "alice", "bob-key": "bob"},
+	}
+}
+
+// testWAV returns a mono 16-bit PCM WAV file of the given number of samples at 16 kHz,
+// filled with a pattern derived from seed so that files of different seeds differ.
+func testWAV(samples int, seed byte) []byte {
+	wav := buildPCMWav(16000, 1, samples)
+	for i := range wav[44:] {
+		wav[44+i] = byte(i)*7 + seed
+	}
+	return wav
+}
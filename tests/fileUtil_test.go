@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"io"
 	"net/http"
@@ -56,7 +57,7 @@ func performFileSignatureCheckTest(t *testing.T, fileContent string, expectedSta
 	file := &MockFile{content: fileContent}
 
 	r.POST("/test-file-signature", func(c *gin.Context) {
-		err := domain.CheckFileSignatureWithGinContext(c, file)
+		_, err := domain.CheckFileSignatureWithContext(c.Request.Context(), file)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -91,3 +92,51 @@ func TestCheckFileSignature_NonAudioFile(t *testing.T) {
 	nonAudioFileContent := "Hello, World!" + strings.Repeat("\x00", domain.SignatureLength-12) // Simulate a non-audio file
 	performFileSignatureCheckTest(t, nonAudioFileContent, http.StatusBadRequest, "unknown file type")
 }
+
+func TestCheckFileSignature_ObjectHeader(t *testing.T) {
+	header := []byte("\xFF\xFB" + strings.Repeat("\x00", domain.SignatureLength-2))
+
+	mimeType, err := domain.CheckFileSignatureWithContext(context.Background(), domain.NewMemoryFile(header))
+	if err != nil {
+		t.Fatalf("Expected object header to pass the signature check, got: %v", err)
+	}
+	if mimeType != "audio/mpeg" {
+		t.Errorf("Expected 'audio/mpeg', got: '%s'", mimeType)
+	}
+
+	if _, err := domain.CheckFileSignatureWithContext(context.Background(), domain.NewMemoryFile(nil)); err == nil {
+		t.Error("Expected an empty object to be rejected")
+	}
+}
+
+// shortReadFile returns at most one byte per Read, like a reader streaming an object,
+// and may report a size larger than its content.
+type shortReadFile struct {
+	MockFile
+	size int64
+}
+
+func (m *shortReadFile) Read(p []byte) (n int, err error) {
+	return m.MockFile.Read(p[:min(len(p), 1)])
+}
+
+func (m *shortReadFile) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd && m.size > 0 {
+		return m.size + offset, nil
+	}
+	return m.MockFile.Seek(offset, whence)
+}
+
+func TestCheckFileSignature_ShortReads(t *testing.T) {
+	content := "\xFF\xFB" + strings.Repeat("\x00", domain.SignatureLength-2)
+
+	mimeType, err := domain.CheckFileSignatureWithContext(context.Background(), &shortReadFile{MockFile: MockFile{content: content}})
+	if err != nil || mimeType != "audio/mpeg" {
+		t.Errorf("Expected the signature to be read in several reads, got %q %v", mimeType, err)
+	}
+
+	truncated := &shortReadFile{MockFile: MockFile{content: content[:10]}, size: int64(len(content))}
+	if _, err := domain.CheckFileSignatureWithContext(context.Background(), truncated); err == nil || err.Error() != "file size is too small" {
+		t.Errorf("Expected a file shorter than its size to be too small, got %v", err)
+	}
+}
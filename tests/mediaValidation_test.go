@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sr-api/internal/core/domain"
+	"testing"
+)
+
+var strictMediaPolicy = domain.MediaPolicy{CheckExtension: true, CheckStructure: true}
+
+// isoBox returns an ISO base media box with the given type and zeroed payload.
+func isoBox(boxType string, payloadSize int) []byte {
+	box := make([]byte, 8+payloadSize)
+	binary.BigEndian.PutUint32(box[0:4], uint32(len(box)))
+	copy(box[4:8], boxType)
+	return box
+}
+
+func buildMP4(boxes ...[]byte) []byte {
+	ftyp := isoBox("ftyp", 8)
+	copy(ftyp[8:12], "isom")
+	data := ftyp
+	for _, box := range boxes {
+		data = append(data, box...)
+	}
+	return data
+}
+
+// buildOggPage returns an Ogg page with a single segment of payloadSize bytes and a
+// valid checksum.
+func buildOggPage(payloadSize int) []byte {
+	page := make([]byte, 27+1+payloadSize)
+	copy(page[0:4], "OggS")
+	page[26] = 1
+	page[27] = byte(payloadSize)
+	crc := uint32(0)
+	for _, b := range page {
+		crc ^= uint32(b) << 24
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	binary.LittleEndian.PutUint32(page[22:26], crc)
+	return page
+}
+
+func expectRejection(t *testing.T, err error, reason string) {
+	t.Helper()
+	var rejection *domain.MediaRejection
+	if !errors.As(err, &rejection) {
+		t.Fatalf("Expected a media rejection with reason %s, got: %v", reason, err)
+	}
+	if rejection.Reason != reason {
+		t.Errorf("Expected reason %s, got %s (%s)", reason, rejection.Reason, rejection.Message)
+	}
+}
+
+func TestValidateMedia_ValidWAV(t *testing.T) {
+	file := &MockFile{content: string(buildPCMWav(16000, 1, 1000))}
+
+	for _, name := range []string{"speech.wav", "SPEECH.WAV", "speech"} {
+		mimeType, err := domain.ValidateMediaWithContext(context.Background(), file, name, strictMediaPolicy)
+		if err != nil {
+			t.Errorf("Expected %s to be accepted, got: %v", name, err)
+		}
+		if mimeType != "audio/x-wav" {
+			t.Errorf("Unexpected MIME type: %s", mimeType)
+		}
+	}
+	if file.offset != 0 {
+		t.Errorf("Expected reader to be rewound, offset: %d", file.offset)
+	}
+}
+
+func TestValidateMedia_TruncatedWAV(t *testing.T) {
+	wav := buildPCMWav(16000, 1, 1000)
+	file := &MockFile{content: string(wav[:len(wav)-100])}
+
+	_, err := domain.ValidateMediaWithContext(context.Background(), file, "speech.wav", strictMediaPolicy)
+	expectRejection(t, err, domain.RejectReasonMalformedContainer)
+
+	if _, err := domain.ValidateMediaWithContext(context.Background(), file, "speech.wav", domain.MediaPolicy{}); err != nil {
+		t.Errorf("Expected structure checks to be optional, got: %v", err)
+	}
+}
+
+func TestValidateMedia_ExtensionMismatch(t *testing.T) {
+	file := &MockFile{content: string(buildPCMWav(16000, 1, 1000))}
+
+	_, err := domain.ValidateMediaWithContext(context.Background(), file, "speech.mp3", strictMediaPolicy)
+	expectRejection(t, err, domain.RejectReasonExtensionMismatch)
+}
+
+func TestValidateMedia_TypeNotAllowed(t *testing.T) {
+	file := &MockFile{content: string(buildPCMWav(16000, 1, 1000))}
+	policy := domain.MediaPolicy{AllowedTypes: []string{"audio/mpeg", "audio/ogg"}}
+
+	_, err := domain.ValidateMediaWithContext(context.Background(), file, "speech.wav", policy)
+	expectRejection(t, err, domain.RejectReasonTypeNotAllowed)
+}
+
+func TestValidateMedia_MP4(t *testing.T) {
+	valid := &MockFile{content: string(buildMP4(isoBox("moov", 300), isoBox("mdat", 100)))}
+	if _, err := domain.ValidateMediaWithContext(context.Background(), valid, "clip.mp4", strictMediaPolicy); err != nil {
+		t.Errorf("Expected valid MP4 to be accepted, got: %v", err)
+	}
+
+	noMoov := &MockFile{content: string(buildMP4(isoBox("mdat", 400)))}
+	_, err := domain.ValidateMediaWithContext(context.Background(), noMoov, "clip.mp4", strictMediaPolicy)
+	expectRejection(t, err, domain.RejectReasonMalformedContainer)
+
+	truncated := buildMP4(isoBox("moov", 300), isoBox("mdat", 100))
+	_, err = domain.ValidateMediaWithContext(context.Background(), &MockFile{content: string(truncated[:len(truncated)-10])}, "clip.mp4", strictMediaPolicy)
+	expectRejection(t, err, domain.RejectReasonMalformedContainer)
+}
+
+func TestValidateMedia_OggChecksum(t *testing.T) {
+	pages := append(buildOggPage(200), buildOggPage(100)...)
+	if _, err := domain.ValidateMediaWithContext(context.Background(), &MockFile{content: string(pages)}, "voice.ogg", strictMediaPolicy); err != nil {
+		t.Errorf("Expected valid Ogg pages to be accepted, got: %v", err)
+	}
+
+	pages[len(pages)-1] ^= 0xFF
+	_, err := domain.ValidateMediaWithContext(context.Background(), &MockFile{content: string(pages)}, "voice.ogg", strictMediaPolicy)
+	expectRejection(t, err, domain.RejectReasonMalformedContainer)
+}
+
+func TestValidateMedia_SignatureFailureIsNotARejection(t *testing.T) {
+	file := &MockFile{content: "not media at all, just some text that is long enough" + string(make([]byte, 300))}
+
+	_, err := domain.ValidateMediaWithContext(context.Background(), file, "notes.txt", strictMediaPolicy)
+	var rejection *domain.MediaRejection
+	if err == nil || errors.As(err, &rejection) {
+		t.Errorf("Expected a signature error, got: %v", err)
+	}
+}
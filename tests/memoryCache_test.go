@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"sr-api/internal/adapters/repository"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := repository.NewMemoryCache[string](2, time.Hour)
+
+	cache.Set("a", "1")
+	cache.Set("b", "2")
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Expected 'a' to be cached")
+	}
+	cache.Set("c", "3")
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected least recently used 'b' to be evicted")
+	}
+	if value, ok := cache.Get("a"); !ok || value != "1" {
+		t.Errorf("Expected 'a' to survive eviction, got: '%s', %v", value, ok)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 entries, got: %d", cache.Len())
+	}
+}
+
+func TestMemoryCacheExpiresEntries(t *testing.T) {
+	cache := repository.NewMemoryCache[string](10, 10*time.Millisecond)
+
+	cache.Set("a", "1")
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected entry to expire after the TTL")
+	}
+}
+
+func TestMemoryCacheDisabled(t *testing.T) {
+	cache := repository.NewMemoryCache[string](0, time.Hour)
+
+	cache.Set("a", "1")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Expected a disabled cache to never return values")
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmeter "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"net/http"
+	"net/http/httptest"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+	"testing"
+)
+
+// buildPCMWav returns a 16-bit PCM WAV file with the given number of silent samples per channel.
+func buildPCMWav(sampleRate, channels, samples int) []byte {
+	dataSize := samples * channels * 2
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, uint16(1))
+	binary.Write(buf, binary.LittleEndian, uint16(channels))
+	binary.Write(buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(buf, binary.LittleEndian, uint32(sampleRate*channels*2))
+	binary.Write(buf, binary.LittleEndian, uint16(channels*2))
+	binary.Write(buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(dataSize))
+	buf.Write(make([]byte, dataSize))
+	return buf.Bytes()
+}
+
+func TestSignatureRejectionsAreCountedByReason(t *testing.T) {
+	reader := sdkmeter.NewManualReader()
+	metrics := telemetry.NewMetrics(sdkmeter.NewMeterProvider(sdkmeter.WithReader(reader)).Meter("sr-api"))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	var files []*MockFile
+	r.POST("/check", func(c *gin.Context) {
+		file := files[0]
+		files = files[1:]
+		_, _ = domain.CheckFileSignatureWithContext(telemetry.ContextWithMetrics(c.Request.Context(), metrics), file)
+	})
+
+	files = []*MockFile{
+		{content: strings.Repeat("\x00", domain.SignatureLength-1)},
+		{content: "Hello, World!" + strings.Repeat("\x00", domain.SignatureLength)},
+		{content: "Hello, World!" + strings.Repeat("\x00", domain.SignatureLength)},
+	}
+	for range files {
+		req, _ := http.NewRequest(http.MethodPost, "/check", nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "sr_api.signature.rejections" {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				t.Fatalf("Unexpected data type %T", m.Data)
+			}
+			for _, dp := range sum.DataPoints {
+				reason, _ := dp.Attributes.Value(attribute.Key("reason"))
+				got[reason.AsString()] = dp.Value
+			}
+		}
+	}
+
+	if got["too_small"] != 1 {
+		t.Errorf("Expected 1 too_small rejection, got: %d", got["too_small"])
+	}
+	if got["unknown_type"] != 2 {
+		t.Errorf("Expected 2 unknown_type rejections, got: %d", got["unknown_type"])
+	}
+}
+
+func TestAudioDurationSeconds_Wav(t *testing.T) {
+	file := &MockFile{content: string(buildPCMWav(16000, 2, 24000))}
+
+	seconds, ok := domain.AudioDurationSeconds(file)
+	if !ok {
+		t.Fatal("Expected WAV duration to be detected")
+	}
+	if seconds != 1.5 {
+		t.Errorf("Expected 1.5 seconds, got: %f", seconds)
+	}
+	if file.offset != 0 {
+		t.Errorf("Expected reader to be rewound, offset: %d", file.offset)
+	}
+}
+
+func TestAudioDurationSeconds_NotWav(t *testing.T) {
+	file := &MockFile{content: "\xFF\xFB" + strings.Repeat("\x00", 100)}
+
+	if _, ok := domain.AudioDurationSeconds(file); ok {
+		t.Error("Expected non-WAV input to be rejected")
+	}
+}
+
+func TestAudioDurationSeconds_ForgedFormatChunkSize(t *testing.T) {
+	header := []byte("RIFF\x12\x00\x00\x00WAVEfmt \xF0\xFF\xFF\x7F")
+	file := &MockFile{content: string(append(header, make([]byte, 6)...))}
+
+	if _, ok := domain.AudioDurationSeconds(file); ok {
+		t.Error("Expected a fmt chunk larger than the file to be rejected")
+	}
+}
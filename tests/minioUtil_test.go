@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	minio2 "sr-api/internal/adapters/repository"
 	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
 	"time"
 
 	"github.com/minio/minio-go/v7"
@@ -140,7 +141,7 @@ func TestUploadToMinioWithTestContainer(t *testing.T) {
 	router.POST("/upload", func(c *gin.Context) {
 		// Simulate file upload as before, using the mockAudioFile struct
 		file := &mockAudioFile{content: "test content"}
-		if err := minioRepo.UploadToMinioWithContext(c, "testfile.mp3", file, int64(len(file.content))); err != nil {
+		if err := minioRepo.UploadToMinioWithContext(c.Request.Context(), "testfile.mp3", file, int64(len(file.content)), domain.ObjectMetadata{ContentType: "audio/mpeg"}); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file"})
 			return
 		}
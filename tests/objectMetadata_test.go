@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"sr-api/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestObjectMetadataRoundTrip(t *testing.T) {
+	metadata := domain.ObjectMetadata{
+		OriginalFilename: "звонок клиента.mp3",
+		Uploader:         "analytics",
+		SHA256:           "0b5c",
+		ContentType:      "audio/mpeg",
+		Size:             42,
+		DurationSeconds:  1.5,
+	}
+
+	userMetadata := domain.ObjectUserMetadata(metadata)
+	for key, value := range userMetadata {
+		for _, r := range value {
+			if r > 127 {
+				t.Fatalf("Expected ASCII header value for %s, got: %q", key, value)
+			}
+		}
+	}
+
+	// The object store returns keys in canonical header form.
+	returned := map[string]string{
+		"Original-Filename": userMetadata[domain.MetadataOriginalFilename],
+		"Uploader":          userMetadata[domain.MetadataUploader],
+		"Sha256":            userMetadata[domain.MetadataSHA256],
+		"Duration-Seconds":  userMetadata[domain.MetadataDurationSeconds],
+	}
+	if got := domain.ObjectMetadataFromUser("audio/mpeg", 42, returned); got != metadata {
+		t.Errorf("Expected %+v, got: %+v", metadata, got)
+	}
+}
+
+func TestObjectKeyPrefix(t *testing.T) {
+	now := time.Date(2024, 3, 1, 23, 0, 0, 0, time.FixedZone("UTC+3", 3*3600))
+
+	cases := []struct {
+		template string
+		caller   string
+		want     string
+	}{
+		{"", "analytics", ""},
+		{"{date}", "analytics", "2024/03/01/"},
+		{"{tenant}/{date}/", "analytics", "analytics/2024/03/01/"},
+		{"/uploads/{tenant}", "team a/b", "uploads/team_a_b/"},
+		{"{tenant}", "..", "__/"},
+	}
+	for _, tc := range cases {
+		if got := domain.ObjectKeyPrefix(tc.template, tc.caller, now); got != tc.want {
+			t.Errorf("ObjectKeyPrefix(%q, %q) = %q, want %q", tc.template, tc.caller, got, tc.want)
+		}
+	}
+}
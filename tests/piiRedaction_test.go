@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"sr-api/internal/core/domain"
+	"strings"
+	"testing"
+)
+
+func newTestRedactor(t *testing.T, custom map[string]string) *domain.Redactor {
+	t.Helper()
+	redactor, err := domain.NewRedactor(custom)
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+	return redactor
+}
+
+func TestRedactText_BuiltInDetectors(t *testing.T) {
+	redactor := newTestRedactor(t, nil)
+
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"email", "Write to john.doe+calls@example.com today.", "Write to [EMAIL] today."},
+		{"card", "My card is 4111 1111 1111 1111, expiring soon.", "My card is [CARD_NUMBER], expiring soon."},
+		{"card with dashes", "Card 5500-0000-0000-0004.", "Card [CARD_NUMBER]."},
+		{"invalid card checksum", "Reference 4111 1111 1111 1112 please.", "Reference 4111 1111 1111 1112 please."},
+		{"phone", "Call me at +7 (912) 345-67-89 tomorrow.", "Call me at [PHONE_NUMBER] tomorrow."},
+		{"national phone", "The number is (555) 123-4567.", "The number is [PHONE_NUMBER]."},
+		{"iban", "Pay to DE89 3704 0044 0532 0130 00 AND thanks.", "Pay to [IBAN] AND thanks."},
+		{"invalid iban", "Code DE00 3704 0044 0532 0130 00.", "Code DE00 3704 0044 0532 0130 00."},
+		{"long number", "Order 12345678901234567 shipped.", "Order 12345678901234567 shipped."},
+		{"short numbers", "We met in 2023 and 2024 with 15 people.", "We met in 2023 and 2024 with 15 people."},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, _ := redactor.RedactText(tc.text); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRedactText_CustomRules(t *testing.T) {
+	redactor := newTestRedactor(t, map[string]string{"ticket": `TCK-\d{6}`})
+
+	got, counts := redactor.RedactText("Ticket TCK-123456 for a@b.io and TCK-654321.")
+	if got != "Ticket [TICKET] for [EMAIL] and [TICKET]." {
+		t.Errorf("Unexpected redaction: %q", got)
+	}
+	if counts["ticket"] != 2 || counts[domain.RedactionCategoryEmail] != 1 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+
+	if _, err := domain.NewRedactor(map[string]string{"broken": `(`}); err == nil {
+		t.Error("Expected an invalid rule to be rejected")
+	}
+	if _, err := domain.NewRedactor(map[string]string{"empty": `a*`}); err == nil {
+		t.Error("Expected a rule matching empty text to be rejected")
+	}
+}
+
+func TestRedactText_SkipsEmptyMatches(t *testing.T) {
+	// The rule matches nothing in empty text, but empty text at every word boundary.
+	redactor := newTestRedactor(t, map[string]string{"code": `\bX?`})
+
+	got, counts := redactor.RedactText("Call X now")
+	if got != "Call [CODE] now" || counts["code"] != 1 {
+		t.Errorf("Expected only the non-empty match to be redacted, got %q %v", got, counts)
+	}
+}
+
+func TestRedactResult_SegmentsWordsAndReport(t *testing.T) {
+	redactor := newTestRedactor(t, nil)
+	result := domain.RecognitionSuccess{
+		RecognizedText: "My card is 4111 1111 1111 1111 and mail is a@b.io",
+		Segments: []domain.Segment{{
+			Text: " My card is 4111 1111 1111 1111 and mail is a@b.io",
+			Words: []domain.Word{
+				{Word: " My", Start: 0, End: 0.2, Probability: 0.9},
+				{Word: " card", Start: 0.2, End: 0.5, Probability: 0.9},
+				{Word: " is", Start: 0.5, End: 0.6, Probability: 0.9},
+				{Word: " 4111", Start: 0.6, End: 1, Probability: 0.8},
+				{Word: " 1111", Start: 1, End: 1.4, Probability: 0.7},
+				{Word: " 1111", Start: 1.4, End: 1.8, Probability: 0.9},
+				{Word: " 1111", Start: 1.8, End: 2.2, Probability: 0.9},
+				{Word: " and", Start: 2.2, End: 2.4, Probability: 0.9},
+				{Word: " mail", Start: 2.4, End: 2.6, Probability: 0.9},
+				{Word: " is", Start: 2.6, End: 2.7, Probability: 0.9},
+				{Word: " a@b.io", Start: 2.7, End: 3.5, Probability: 0.9},
+			},
+		}},
+	}
+
+	redacted := redactor.RedactResult(result)
+
+	if redacted.RecognizedText != "My card is [CARD_NUMBER] and mail is [EMAIL]" {
+		t.Errorf("Unexpected text: %q", redacted.RecognizedText)
+	}
+	segment := redacted.Segments[0]
+	if segment.Text != " My card is [CARD_NUMBER] and mail is [EMAIL]" {
+		t.Errorf("Unexpected segment text: %q", segment.Text)
+	}
+	if len(segment.Words) != 8 {
+		t.Fatalf("Expected the card number words to be merged, got %+v", segment.Words)
+	}
+	card := segment.Words[3]
+	if card.Word != " [CARD_NUMBER]" || card.Start != 0.6 || card.End != 2.2 || card.Probability != 0.7 {
+		t.Errorf("Unexpected card word: %+v", card)
+	}
+	if segment.Words[7].Word != " [EMAIL]" {
+		t.Errorf("Unexpected email word: %+v", segment.Words[7])
+	}
+
+	report := redacted.Redaction
+	if report == nil || report.Total != 2 || report.Categories[domain.RedactionCategoryCard] != 1 || report.Categories[domain.RedactionCategoryEmail] != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if strings.Contains(result.Segments[0].Words[3].Word, "[") {
+		t.Error("Expected the input result to be left untouched")
+	}
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"reflect"
+	"sr-api/internal/core/domain"
+	"testing"
+)
+
+func textResult(text string) domain.RecognitionSuccess {
+	return domain.RecognitionSuccess{RecognizedText: text}
+}
+
+func TestParsePostProcessSteps(t *testing.T) {
+	steps, err := domain.ParsePostProcessSteps([]string{"profanity", " Numbers", "dedupe", "numbers"})
+	if err != nil {
+		t.Fatalf("ParsePostProcessSteps failed: %v", err)
+	}
+	if want := []string{"dedupe", "numbers", "profanity"}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("Expected %v, got %v", want, steps)
+	}
+	if steps, err := domain.ParsePostProcessSteps([]string{"none"}); err != nil || steps == nil || len(steps) != 0 {
+		t.Errorf("Expected no steps for none, got %v, %v", steps, err)
+	}
+	for _, invalid := range [][]string{{"spellcheck"}, {"none", "dedupe"}} {
+		if _, err := domain.ParsePostProcessSteps(invalid); err == nil {
+			t.Errorf("Expected %v to be rejected", invalid)
+		}
+	}
+}
+
+func TestRemoveRepetitions_CollapsesLoops(t *testing.T) {
+	result := domain.RemoveRepetitions(textResult("Thank you. Thank you. Thank you. Thank you. Bye, see you."))
+	if result.RecognizedText != "Thank you. Bye, see you." {
+		t.Errorf("Unexpected text: %q", result.RecognizedText)
+	}
+
+	kept := domain.RemoveRepetitions(textResult("Very very good, no no."))
+	if kept.RecognizedText != "Very very good, no no." {
+		t.Errorf("Expected short repetitions to be kept, got %q", kept.RecognizedText)
+	}
+}
+
+func TestRemoveRepetitions_DropsHallucinatedSegments(t *testing.T) {
+	result := domain.RecognitionSuccess{
+		RecognizedText: "Let us start the meeting. Let us start the meeting. Yes. Yes. Субтитры сделал DimaTorzok Thanks for watching!",
+		Segments: []domain.Segment{
+			{ID: 0, Text: " Let us start the meeting."},
+			{ID: 1, Text: " Let us start the meeting."},
+			{ID: 2, Text: " Yes."},
+			{ID: 3, Text: " Yes."},
+			{ID: 4, Text: " Субтитры сделал DimaTorzok"},
+			{ID: 5, Text: " Thanks for watching!"},
+		},
+	}
+
+	cleaned := domain.RemoveRepetitions(result)
+
+	if len(cleaned.Segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %+v", cleaned.Segments)
+	}
+	if cleaned.RecognizedText != "Let us start the meeting. Yes. Yes." {
+		t.Errorf("Unexpected text: %q", cleaned.RecognizedText)
+	}
+	if cleaned.Segments[2].ID != 2 {
+		t.Errorf("Expected segments to be renumbered: %+v", cleaned.Segments)
+	}
+	if len(result.Segments) != 6 {
+		t.Error("Expected the input result to be left untouched")
+	}
+}
+
+func TestReplaceVocabulary(t *testing.T) {
+	result := domain.RecognitionSuccess{
+		RecognizedText: "We deploy on kuber netes, with SR API.",
+		Segments: []domain.Segment{{
+			Text: " We deploy on kuber netes, with SR API.",
+			Words: []domain.Word{
+				{Word: " We", Start: 0, End: 0.2}, {Word: " deploy", Start: 0.2, End: 0.6},
+				{Word: " on", Start: 0.6, End: 0.7}, {Word: " kuber", Start: 0.7, End: 1.0},
+				{Word: " netes,", Start: 1.0, End: 1.3}, {Word: " with", Start: 1.3, End: 1.5},
+				{Word: " SR", Start: 1.5, End: 1.7}, {Word: " API.", Start: 1.7, End: 2.0},
+			},
+		}},
+	}
+
+	replaced := domain.ReplaceVocabulary(result, map[string]string{"Kuber Netes": "Kubernetes", "sr api": "SR-API", "sr": "speech"})
+
+	if replaced.RecognizedText != "We deploy on Kubernetes, with SR-API." {
+		t.Errorf("Unexpected text: %q", replaced.RecognizedText)
+	}
+	words := replaced.Segments[0].Words
+	if len(words) != 6 || words[3].Word != " Kubernetes," || words[3].Start != 0.7 || words[3].End != 1.3 {
+		t.Errorf("Unexpected words: %+v", words)
+	}
+}
+
+func TestNormalizeNumbers_English(t *testing.T) {
+	cases := map[string]string{
+		"I paid two thousand and twenty four dollars.":   "I paid 2024 dollars.",
+		"Call one hundred twenty-three, then forty two.": "Call 123, then 42.",
+		"It was nineteen hundred and five.":              "It was 1905.",
+		"One of the three cats had twenty twenty vision": "One of the three cats had 20 20 vision",
+		"Thousands and a thousand people came.":          "Thousands and a thousand people came.",
+		"Seven million three hundred thousand.":          "7300000.",
+	}
+	for input, want := range cases {
+		if got := domain.NormalizeNumbers(textResult(input), "en").RecognizedText; got != want {
+			t.Errorf("For %q expected %q, got %q", input, want, got)
+		}
+	}
+}
+
+func TestNormalizeNumbers_Russian(t *testing.T) {
+	cases := map[string]string{
+		"Заказ на двести сорок пять рублей.":       "Заказ на 245 рублей.",
+		"Прошло две тысячи двадцать четыре года":   "Прошло 2024 года",
+		"Тысячи людей пришли":                      "Тысячи людей пришли",
+		"Одна тысяча девятьсот девяносто девять.":  "1999.",
+		"Три миллиона пятьсот тысяч семь человек.": "3500007 человек.",
+		"Пять минут.": "Пять минут.",
+	}
+	for input, want := range cases {
+		if got := domain.NormalizeNumbers(textResult(input), "ru").RecognizedText; got != want {
+			t.Errorf("For %q expected %q, got %q", input, want, got)
+		}
+	}
+	if got := domain.NormalizeNumbers(textResult("zwei hundert"), "de").RecognizedText; got != "zwei hundert" {
+		t.Errorf("Expected other languages to be unchanged, got %q", got)
+	}
+}
+
+func TestMaskProfanity(t *testing.T) {
+	english := domain.MaskProfanity(textResult("What the fuck, this is bullshit! Shell is fine."), "en", nil)
+	if english.RecognizedText != "What the f***, this is b*******! Shell is fine." {
+		t.Errorf("Unexpected English masking: %q", english.RecognizedText)
+	}
+
+	russian := domain.MaskProfanity(textResult("Хуже некуда, блядь, этот мудак опять. Учебник и сукно."), "ru", []string{"Darn"})
+	if russian.RecognizedText != "Хуже некуда, б****, этот м**** опять. Учебник и сукно." {
+		t.Errorf("Unexpected Russian masking: %q", russian.RecognizedText)
+	}
+
+	custom := domain.MaskProfanity(textResult("Darn it."), "fr", []string{"darn"})
+	if custom.RecognizedText != "D*** it." {
+		t.Errorf("Unexpected custom masking: %q", custom.RecognizedText)
+	}
+}
+
+func TestPostProcess_RunsStepsInOrder(t *testing.T) {
+	config := domain.PostProcessConfig{Vocabulary: map[string]string{"sir api": "SR-API"}}
+	steps, _ := domain.ParsePostProcessSteps([]string{"profanity", "numbers", "vocabulary", "dedupe"})
+
+	result := domain.PostProcess(textResult("Sir API handled twenty five shit files. Sir API handled twenty five shit files."), steps, "en", config)
+
+	if result.RecognizedText != "SR-API handled 25 s*** files. SR-API handled 25 s*** files." {
+		t.Errorf("Unexpected text: %q", result.RecognizedText)
+	}
+	if !reflect.DeepEqual(result.PostProcessing, steps) {
+		t.Errorf("Expected the steps to be recorded, got %v", result.PostProcessing)
+	}
+}
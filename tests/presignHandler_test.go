@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+	"sr-api/internal/adapters/handler"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/adapters/handler/middleware"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+// presignUpload presigns an upload of filename as the caller of apiKey.
+func presignUpload(t *testing.T, r *gin.Engine, apiKey string, filename string) handlerStructure.PresignResponse {
+	req, _ := http.NewRequest(http.MethodPost, "/uploads/presign", strings.NewReader(`{"filename":"`+filename+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.APIKeyHeader, apiKey)
+	var presigned handlerStructure.PresignResponse
+	if w := serve(t, r, req, &presigned); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	return presigned
+}
+
+// putPresigned uploads content to a presigned URL like a client would.
+func putPresigned(t *testing.T, presigned handlerStructure.PresignResponse, content []byte) {
+	req, _ := http.NewRequest(presigned.Method, presigned.UploadURL, bytes.NewReader(content))
+	for name, value := range presigned.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to upload to the presigned URL: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the presigned upload to succeed, got %d", resp.StatusCode)
+	}
+}
+
+func completeUpload(t *testing.T, r *gin.Engine, apiKey string, id string, out interface{}) int {
+	req, _ := http.NewRequest(http.MethodPost, "/uploads/"+id+"/complete", nil)
+	req.Header.Set(middleware.APIKeyHeader, apiKey)
+	return serve(t, r, req, out).Code
+}
+
+func TestCompletePresignedUploadStoresTheCheckedContent(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+	wav := testWAV(1600, 1)
+
+	presigned := presignUpload(t, r, "alice-key", "a.wav")
+	putPresigned(t, presigned, wav)
+	var result domain.RecognitionSuccess
+	if code := completeUpload(t, r, "alice-key", presigned.ID, &result); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+
+	code, transcript := getTranscript(t, r, "alice-key", result.TranscriptID)
+	if code != http.StatusOK || transcript.SHA256 == "" || transcript.ObjectKey == presigned.ObjectKey {
+		t.Fatalf("Expected a hashed transcript of a new object, got %d %+v", code, transcript)
+	}
+	if names := whisper.fileNames(); len(names) != 1 || names[0] != transcript.ObjectKey {
+		t.Errorf("Expected the new object to be transcribed, got %v", names)
+	}
+	if _, ok := store.get("audio", presigned.ObjectKey); ok {
+		t.Errorf("Expected the presigned object to be removed")
+	}
+
+	// The presigned URL is still valid, but nothing reads what is uploaded to it anymore.
+	putPresigned(t, presigned, []byte("replaced"))
+	stored, ok := store.get("audio", transcript.ObjectKey)
+	if !ok || !bytes.Equal(stored.content, wav) || stored.tags[repository.ObjectKindTag] != repository.ObjectKindAudio {
+		t.Errorf("Expected the checked content to be stored as audio")
+	}
+	if code := completeUpload(t, r, "alice-key", presigned.ID, nil); code != http.StatusNotFound {
+		t.Errorf("Expected a completed upload to be unknown, got %d", code)
+	}
+}
+
+func TestCompletePresignedUploadIsDeduplicated(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+	wav := testWAV(1600, 1)
+	if w := serve(t, r, multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "a.wav", wav}}, nil), nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	presigned := presignUpload(t, r, "alice-key", "b.wav")
+	putPresigned(t, presigned, wav)
+	req, _ := http.NewRequest(http.MethodPost, "/uploads/"+presigned.ID+"/complete", nil)
+	req.Header.Set(middleware.APIKeyHeader, "alice-key")
+	w := serve(t, r, req, nil)
+	if w.Code != http.StatusOK || w.Header().Get(handler.CacheHeader) != "HIT" {
+		t.Fatalf("Expected a cached result, got %d %q: %s", w.Code, w.Header().Get(handler.CacheHeader), w.Body.String())
+	}
+	if len(whisper.fileNames()) != 1 {
+		t.Errorf("Expected a single transcription, got %v", whisper.fileNames())
+	}
+	if _, ok := store.get("audio", presigned.ObjectKey); ok {
+		t.Errorf("Expected the presigned object to be removed")
+	}
+}
+
+func TestCompletePresignedUploadRejectsInvalidMedia(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+
+	if code := completeUpload(t, r, "alice-key", "unknown", nil); code != http.StatusNotFound {
+		t.Errorf("Expected an unknown upload to be rejected with 404, got %d", code)
+	}
+	presigned := presignUpload(t, r, "alice-key", "a.wav")
+	if code := completeUpload(t, r, "alice-key", presigned.ID, nil); code != http.StatusConflict {
+		t.Errorf("Expected a missing upload to be rejected with 409, got %d", code)
+	}
+	putPresigned(t, presigned, []byte("not audio at all"))
+	if code := completeUpload(t, r, "bob-key", presigned.ID, nil); code != http.StatusNotFound {
+		t.Errorf("Expected another caller's upload to be rejected with 404, got %d", code)
+	}
+	if code := completeUpload(t, r, "alice-key", presigned.ID, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected invalid media to be rejected with 400, got %d", code)
+	}
+	if _, ok := store.get("audio", presigned.ObjectKey); ok {
+		t.Errorf("Expected the rejected upload to be removed")
+	}
+	if len(whisper.fileNames()) != 0 {
+		t.Errorf("Expected nothing to be transcribed, got %v", whisper.fileNames())
+	}
+}
+
+func TestUncompletedPresignedUploadIsPurgedAsAudio(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	cfg := newFakeServicesConfig(t, store, whisper)
+	r, _ := newTestRouter(t, cfg)
+
+	presigned := presignUpload(t, r, "alice-key", "a.wav")
+	uploadURL, err := url.Parse(presigned.UploadURL)
+	if err != nil {
+		t.Fatalf("Failed to parse the presigned URL: %v", err)
+	}
+	if signed := uploadURL.Query().Get("X-Amz-SignedHeaders"); !strings.Contains(signed, "x-amz-tagging") {
+		t.Fatalf("Expected the tagging header to be signed, got %q", signed)
+	}
+	putPresigned(t, presigned, testWAV(1600, 1))
+	stored, ok := store.get("audio", presigned.ObjectKey)
+	if !ok || stored.tags[repository.ObjectKindTag] != repository.ObjectKindAudio {
+		t.Fatalf("Expected the presigned upload to be tagged as audio")
+	}
+
+	// The upload is never completed.
+	stored.modified = time.Now().Add(-2 * time.Hour)
+	sweeper, err := repository.NewRetentionSweeper(newFakeMinioRepository(t, store), nil, &recordingAuditSink{}, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create sweeper: %v", err)
+	}
+	if _, err := sweeper.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if _, ok := store.get("audio", presigned.ObjectKey); ok {
+		t.Errorf("Expected the expired presigned upload to be purged")
+	}
+}
+
+func TestCopyObjectFromRejectsAChangedSource(t *testing.T) {
+	store := newFakeObjectStore(t)
+	store.put("source", "a.wav", &fakeObject{content: []byte("original")})
+	repo := newFakeMinioRepository(t, store)
+
+	err := repo.CopyObjectFrom(context.Background(), "source", "a.wav", etag([]byte("scanned")), "copy.wav", domain.ObjectMetadata{})
+	if !errors.Is(err, repository.ErrObjectChanged) {
+		t.Errorf("Expected ErrObjectChanged, got %v", err)
+	}
+	if _, ok := store.get("audio", "copy.wav"); ok {
+		t.Errorf("Expected nothing to be copied")
+	}
+	if err := repo.CopyObjectFrom(context.Background(), "source", "a.wav", etag([]byte("original")), "copy.wav", domain.ObjectMetadata{}); err != nil {
+		t.Errorf("Expected the unchanged source to be copied, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/adapters/handler/middleware"
+	"sync"
+	"testing"
+	"time"
+)
+
+func getProgress(t *testing.T, r *gin.Engine, apiKey string, requestID string) (int, handlerStructure.RequestProgress) {
+	req, _ := http.NewRequest(http.MethodGet, "/requests/"+requestID+"/progress", nil)
+	req.Header.Set(middleware.APIKeyHeader, apiKey)
+	var progress handlerStructure.RequestProgress
+	w := serve(t, r, req, &progress)
+	return w.Code, progress
+}
+
+func TestChunkedTranscriptionProgress(t *testing.T) {
+	store := newFakeObjectStore(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	whisper := newFakeWhisper(t, store, func([]byte) string {
+		once.Do(func() {
+			close(started)
+			<-release
+		})
+		return "hello"
+	})
+	cfg := newFakeServicesConfig(t, store, whisper)
+	cfg.TranscribeChunkDuration = time.Second
+	cfg.TranscribeChunkOverlap = 100 * time.Millisecond
+	cfg.TranscribeChunkConcurrency = 1
+	r, _ := newTestRouter(t, cfg)
+
+	// The client picks the request ID to poll the progress of its upload.
+	req := multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "long.wav", testWAV(3*16000, 1)}}, nil)
+	req.Header.Set(middleware.RequestIDHeader, "long-upload")
+	done := make(chan int)
+	go func() {
+		done <- serve(t, r, req, nil).Code
+	}()
+
+	<-started
+	code, progress := getProgress(t, r, "alice-key", "long-upload")
+	if code != http.StatusOK || len(progress.Files) != 1 {
+		t.Fatalf("Expected the progress of one file, got %d %+v", code, progress)
+	}
+	running := progress.Files[0]
+	if running.State != handlerStructure.ProgressRunning || running.ChunksTotal < 2 || running.ChunksCompleted != 0 {
+		t.Errorf("Expected a running transcription of several chunks, got %+v", running)
+	}
+	if code, _ := getProgress(t, r, "bob-key", "long-upload"); code != http.StatusNotFound {
+		t.Errorf("Expected the progress to be hidden from other callers, got %d", code)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Fatalf("Expected the upload to succeed, got %d", code)
+	}
+	_, progress = getProgress(t, r, "alice-key", "long-upload")
+	if finished := progress.Files[0]; finished.State != handlerStructure.ProgressCompleted || finished.ChunksCompleted != running.ChunksTotal {
+		t.Errorf("Expected a completed transcription of %d chunks, got %+v", running.ChunksTotal, finished)
+	}
+	if code, _ := getProgress(t, r, "alice-key", "unknown"); code != http.StatusNotFound {
+		t.Errorf("Expected an unknown request to be reported as not found, got %d", code)
+	}
+}
+
+func TestChunkedTranscriptionsOfOneObjectUseTheirOwnChunks(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	cfg := newFakeServicesConfig(t, store, whisper)
+	cfg.TranscribeChunkDuration = time.Second
+	cfg.TranscribeChunkOverlap = 100 * time.Millisecond
+	r, _ := newTestRouter(t, cfg)
+	wav := testWAV(3*16000, 1)
+
+	for i := 0; i < 2; i++ {
+		w := serve(t, r, multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "long.wav", wav}}, map[string]string{"no_cache": "true"}), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	names := whisper.fileNames()
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			t.Errorf("Expected each transcription to use chunks of its own, got %v", names)
+			break
+		}
+		seen[name] = true
+	}
+	if len(names) < 4 {
+		t.Errorf("Expected both uploads to be transcribed in chunks, got %v", names)
+	}
+}
+
+func TestChunkedTranscriptionProgressIsKeptPerCaller(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	cfg := newFakeServicesConfig(t, store, whisper)
+	cfg.TranscribeChunkDuration = time.Second
+	cfg.TranscribeChunkOverlap = 100 * time.Millisecond
+	r, _ := newTestRouter(t, cfg)
+
+	// Both callers happen to pick the same request ID.
+	for i, apiKey := range []string{"alice-key", "bob-key"} {
+		req := multipartRequest(t, "/upload", apiKey, []multipartFile{{"file", "long.wav", testWAV(3*16000, byte(i+1))}}, nil)
+		req.Header.Set(middleware.RequestIDHeader, "shared-id")
+		if w := serve(t, r, req, nil); w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	_, alice := getProgress(t, r, "alice-key", "shared-id")
+	_, bob := getProgress(t, r, "bob-key", "shared-id")
+	if len(alice.Files) != 1 || len(bob.Files) != 1 || alice.Files[0].ObjectKey == bob.Files[0].ObjectKey {
+		t.Errorf("Expected each caller to see only the progress of its own upload, got %+v and %+v", alice, bob)
+	}
+}
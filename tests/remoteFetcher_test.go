@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHostAllowed(t *testing.T) {
+	allowlist := []string{"media.internal", ".files.example.com"}
+
+	cases := map[string]bool{
+		"media.internal":          true,
+		"MEDIA.internal.":         true,
+		"a.files.example.com":     true,
+		"files.example.com":       false,
+		"evilfiles.example.com":   false,
+		"media.internal.evil.com": false,
+		"169.254.169.254":         false,
+		"":                        false,
+	}
+	for host, want := range cases {
+		if got := domain.HostAllowed(host, allowlist); got != want {
+			t.Errorf("HostAllowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestRemoteFetcher(t *testing.T) {
+	body := "\xFF\xFB" + strings.Repeat("\x00", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/call.mp3":
+			io.WriteString(w, body)
+		case "/redirect":
+			http.Redirect(w, r, "http://localhost.invalid/call.mp3", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher, err := repository.NewRemoteFetcher([]string{"127.0.0.1"}, 1024, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create fetcher: %v", err)
+	}
+	ctx := context.Background()
+
+	file, err := fetcher.Fetch(ctx, server.URL+"/call.mp3")
+	if err != nil {
+		t.Fatalf("Failed to fetch allowed URL: %v", err)
+	}
+	content, _ := io.ReadAll(file)
+	file.Close()
+	if string(content) != body || file.Size != int64(len(body)) || file.Filename != "call.mp3" {
+		t.Errorf("Unexpected download: filename %q, size %d", file.Filename, file.Size)
+	}
+
+	if _, err := fetcher.Fetch(ctx, server.URL+"/redirect"); !errors.Is(err, repository.ErrSourceNotAllowed) {
+		t.Errorf("Expected redirect to a host outside the allowlist to be refused, got: %v", err)
+	}
+	if _, err := fetcher.Fetch(ctx, "file:///etc/passwd"); !errors.Is(err, repository.ErrSourceNotAllowed) {
+		t.Errorf("Expected non-HTTP scheme to be refused, got: %v", err)
+	}
+
+	small, _ := repository.NewRemoteFetcher([]string{"127.0.0.1"}, 10, 5*time.Second)
+	if _, err := small.Fetch(ctx, server.URL+"/call.mp3"); !errors.Is(err, repository.ErrSourceTooLarge) {
+		t.Errorf("Expected size limit to be enforced, got: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"net/http"
+	"net/http/httptest"
+	"sr-api/internal/adapters/handler/middleware"
+	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+	"testing"
+)
+
+func TestRequestLoggerAddsRequestFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	previous := log.Logger
+	log.Logger = zerolog.New(buf)
+	defer func() { log.Logger = previous }()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	cfg := &config.AppConfig{APIKeys: map[string]string{"secret-key": "analytics"}}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestLogger(cfg))
+	var caller domain.Caller
+	r.GET("/log", func(c *gin.Context) {
+		ctx, span := telemetry.StartSpanFromGinContext(c, "TestHandler")
+		defer span.End()
+		caller = domain.CallerFromContext(ctx)
+		telemetry.LoggerFromContext(ctx).Info().Msg("handler line")
+		c.Status(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/log", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-123")
+	req.Header.Set(middleware.APIKeyHeader, "secret-key")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Header().Get(middleware.RequestIDHeader) != "req-123" {
+		t.Errorf("Expected request ID to be echoed, got: '%s'", w.Header().Get(middleware.RequestIDHeader))
+	}
+	if caller.ID != "analytics" || !caller.Known {
+		t.Errorf("Expected known caller 'analytics', got: %+v", caller)
+	}
+
+	var handlerLine map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to decode log line '%s': %v", line, err)
+		}
+		if entry["message"] == "handler line" {
+			handlerLine = entry
+		}
+	}
+	if handlerLine == nil {
+		t.Fatalf("Handler log line not found in: %s", buf.String())
+	}
+	for _, field := range []string{"request_id", "client_ip", "api_key_id", "trace_id", "span_id"} {
+		if _, ok := handlerLine[field]; !ok {
+			t.Errorf("Expected field '%s' in log line: %v", field, handlerLine)
+		}
+	}
+	if handlerLine["api_key_id"] != "analytics" {
+		t.Errorf("Expected api_key_id 'analytics', got: %v", handlerLine["api_key_id"])
+	}
+}
+
+func TestResolveCallerFingerprintsUnknownKeys(t *testing.T) {
+	caller := domain.ResolveCaller("unknown-secret", map[string]string{"secret-key": "analytics"})
+	if caller.Known {
+		t.Error("Expected unknown key to be reported as unknown")
+	}
+	if !strings.HasPrefix(caller.ID, "key-") || strings.Contains(caller.ID, "unknown-secret") {
+		t.Errorf("Expected a fingerprint identity, got: '%s'", caller.ID)
+	}
+	if domain.ResolveCaller("", nil).ID != domain.AnonymousCaller {
+		t.Error("Expected empty key to resolve to the anonymous caller")
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingAuditSink keeps appended audit records in memory.
+type recordingAuditSink struct {
+	mu      sync.Mutex
+	records []domain.AuditRecord
+}
+
+func (sink *recordingAuditSink) Append(_ context.Context, record domain.AuditRecord) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.records = append(sink.records, record)
+	return nil
+}
+
+func (sink *recordingAuditSink) Query(_ context.Context, query domain.AuditQuery) ([]domain.AuditRecord, error) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	var records []domain.AuditRecord
+	for _, record := range sink.records {
+		if query.Matches(record) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func newFakeMinioRepository(t *testing.T, store *fakeObjectStore) *repository.MinioRepository {
+	repo, err := repository.NewMinioRepository(newFakeServicesConfig(t, store, newFakeWhisper(t, store, nil)))
+	if err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	return repo
+}
+
+func TestRetentionSweeperPurgesOnlyExpiredAudio(t *testing.T) {
+	store := newFakeObjectStore(t)
+	expired := time.Now().Add(-2 * time.Hour)
+	audio := map[string]string{repository.ObjectKindTag: repository.ObjectKindAudio}
+	store.put("audio", "old.wav", &fakeObject{content: []byte("old"), tags: audio, modified: expired})
+	store.put("audio", "recent.wav", &fakeObject{content: []byte("recent"), tags: audio})
+	store.put("audio", "transcripts/old.transcript.json", &fakeObject{content: []byte("{}"), modified: expired})
+	store.put("audio", "quarantine/old.wav", &fakeObject{content: []byte("flagged"), modified: expired,
+		tags: map[string]string{repository.ObjectKindTag: repository.ObjectKindQuarantine}})
+	store.put("audio", "audit/old.jsonl", &fakeObject{content: []byte("{}"), modified: expired})
+
+	audit := &recordingAuditSink{}
+	sweeper, err := repository.NewRetentionSweeper(newFakeMinioRepository(t, store), nil, audit, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create sweeper: %v", err)
+	}
+	purged, err := sweeper.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+
+	if purged != 1 {
+		t.Errorf("Expected 1 purged object, got %d", purged)
+	}
+	if _, ok := store.get("audio", "old.wav"); ok {
+		t.Errorf("Expected the expired audio to be purged")
+	}
+	for _, key := range []string{"recent.wav", "transcripts/old.transcript.json", "quarantine/old.wav", "audit/old.jsonl"} {
+		if _, ok := store.get("audio", key); !ok {
+			t.Errorf("Expected %s to be kept", key)
+		}
+	}
+	if len(audit.records) != 1 || audit.records[0].ObjectKey != "old.wav" || audit.records[0].Reason != repository.PurgeReasonExpired || audit.records[0].Caller != domain.SystemCaller {
+		t.Errorf("Expected one purge audit record for old.wav, got %+v", audit.records)
+	}
+}
+
+func TestPurgeAudioRecordsTheOutcome(t *testing.T) {
+	store := newFakeObjectStore(t)
+	store.put("audio", "a.wav", &fakeObject{content: []byte("audio")})
+	repo := newFakeMinioRepository(t, store)
+	audit := &recordingAuditSink{}
+
+	if err := repository.PurgeAudio(context.Background(), repo, audit, "a.wav", repository.PurgeReasonAfterTranscription); err != nil {
+		t.Fatalf("PurgeAudio failed: %v", err)
+	}
+	if _, ok := store.get("audio", "a.wav"); ok {
+		t.Errorf("Expected the object to be removed")
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := repository.PurgeAudio(cancelled, repo, audit, "b.wav", repository.PurgeReasonAfterTranscription); err == nil {
+		t.Errorf("Expected an error when the removal fails")
+	}
+
+	if len(audit.records) != 2 {
+		t.Fatalf("Expected 2 audit records, got %+v", audit.records)
+	}
+	for i, outcome := range []string{domain.AuditOutcomeSuccess, domain.AuditOutcomeFailed} {
+		record := audit.records[i]
+		if record.Action != domain.AuditActionPurge || record.Reason != repository.PurgeReasonAfterTranscription || record.Outcome != outcome {
+			t.Errorf("Expected a %s purge record, got %+v", outcome, record)
+		}
+	}
+}
+
+func TestApplyLifecycleRetentionKeepsExistingRules(t *testing.T) {
+	store := newFakeObjectStore(t)
+	store.lifecycles["audio"] = `<LifecycleConfiguration><Rule><ID>logs</ID><Status>Enabled</Status>` +
+		`<Filter><Prefix>logs/</Prefix></Filter><Expiration><Days>30</Days></Expiration></Rule></LifecycleConfiguration>`
+	repo := newFakeMinioRepository(t, store)
+
+	for _, period := range []time.Duration{24 * time.Hour, 72 * time.Hour} {
+		if err := repository.ApplyLifecycleRetention(context.Background(), repo, []string{"audio"}, period); err != nil {
+			t.Fatalf("ApplyLifecycleRetention failed: %v", err)
+		}
+	}
+
+	config, err := repo.Client.GetBucketLifecycle(context.Background(), "audio")
+	if err != nil {
+		t.Fatalf("Failed to get lifecycle: %v", err)
+	}
+	days := make(map[string]lifecycle.ExpirationDays)
+	for _, rule := range config.Rules {
+		days[rule.ID] = rule.Expiration.Days
+	}
+	if len(config.Rules) != 2 || days["logs"] != 30 || days["sr-api-audio-retention"] != 3 {
+		t.Errorf("Expected the existing rule and one updated retention rule, got %+v", config.Rules)
+	}
+}
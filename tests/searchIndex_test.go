@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func indexedTranscript(id string, lang string, text string) domain.Transcript {
+	return domain.Transcript{
+		ID:        id,
+		CreatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		RecognitionSuccess: domain.RecognitionSuccess{
+			DetectedLang:   lang,
+			RecognizedText: text,
+		},
+	}
+}
+
+func TestSearchIndexRanksAndHighlights(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index.json")
+	index, err := repository.NewMemorySearchIndex(path)
+	if err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+
+	transcripts := []domain.Transcript{
+		indexedTranscript("call-1", "en", "The customer asked about the SuperPhone battery and the SuperPhone case."),
+		indexedTranscript("call-2", "en", "We discussed the weather and a new SuperPhone."),
+		indexedTranscript("call-3", "ru", "Клиент спросил про SuperPhone и доставку."),
+		indexedTranscript("call-4", "en", "Nothing about products here <script>."),
+	}
+	for _, transcript := range transcripts {
+		if err := index.Index(ctx, transcript); err != nil {
+			t.Fatalf("Failed to index transcript: %v", err)
+		}
+	}
+
+	result, err := index.Search(ctx, domain.SearchQuery{Text: "superphone", Language: "en"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if result.Total != 2 || result.Hits[0].ID != "call-1" {
+		t.Fatalf("Expected call-1 to rank first of 2 English hits, got: %+v", result.Hits)
+	}
+	if len(result.Hits[0].Highlights) == 0 || !strings.Contains(result.Hits[0].Highlights[0], "<mark>SuperPhone</mark>") {
+		t.Errorf("Expected highlighted match, got: %v", result.Hits[0].Highlights)
+	}
+
+	both, _ := index.Search(ctx, domain.SearchQuery{Text: "SuperPhone battery"})
+	if both.Total != 1 || both.Hits[0].ID != "call-1" {
+		t.Errorf("Expected only call-1 to contain both terms, got: %+v", both.Hits)
+	}
+
+	russian, _ := index.Search(ctx, domain.SearchQuery{Text: "доставку"})
+	if russian.Total != 1 || russian.Hits[0].ID != "call-3" {
+		t.Errorf("Expected call-3 for Russian query, got: %+v", russian.Hits)
+	}
+
+	escaped, _ := index.Search(ctx, domain.SearchQuery{Text: "products"})
+	if escaped.Total != 1 || strings.Contains(escaped.Hits[0].Highlights[0], "<script>") {
+		t.Errorf("Expected HTML in excerpts to be escaped, got: %+v", escaped.Hits)
+	}
+
+	paged, _ := index.Search(ctx, domain.SearchQuery{Text: "superphone", Limit: 1})
+	if len(paged.Hits) != 1 || paged.Total != 3 || paged.NextOffset == nil {
+		t.Errorf("Expected first page of 3 hits, got: %+v", paged)
+	}
+
+	if err := index.Remove(ctx, "call-1"); err != nil {
+		t.Fatalf("Failed to remove transcript: %v", err)
+	}
+	reloaded, err := repository.NewMemorySearchIndex(path)
+	if err != nil {
+		t.Fatalf("Failed to reload index: %v", err)
+	}
+	if reloaded.Len() != 3 {
+		t.Errorf("Expected 3 documents after reload, got: %d", reloaded.Len())
+	}
+	afterRemove, _ := reloaded.Search(ctx, domain.SearchQuery{Text: "battery"})
+	if afterRemove.Total != 0 {
+		t.Errorf("Expected removed transcript to be gone, got: %+v", afterRemove.Hits)
+	}
+}
+
+func TestSearchIndexRebuildKeepsConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	index, err := repository.NewMemorySearchIndex("")
+	if err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	stale := indexedTranscript("call-1", "en", "An outdated budget review.")
+	if err := index.Index(ctx, stale); err != nil {
+		t.Fatalf("Failed to index transcript: %v", err)
+	}
+
+	err = index.Rebuild(ctx, func(ctx context.Context) ([]domain.Transcript, error) {
+		// A request stores a transcript and deletes another while the store is listed.
+		if err := index.Index(ctx, indexedTranscript("call-3", "en", "A new budget review.")); err != nil {
+			return nil, err
+		}
+		if err := index.Remove(ctx, "call-2"); err != nil {
+			return nil, err
+		}
+		return []domain.Transcript{
+			indexedTranscript("call-2", "en", "A deleted budget review."),
+			indexedTranscript("call-4", "en", "A stored budget review."),
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	result, err := index.Search(ctx, domain.SearchQuery{Text: "budget", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	found := make(map[string]bool)
+	for _, hit := range result.Hits {
+		found[hit.ID] = true
+	}
+	if len(found) != 2 || !found["call-3"] || !found["call-4"] {
+		t.Errorf("Expected the listed and the newly indexed transcript only, got: %+v", result.Hits)
+	}
+}
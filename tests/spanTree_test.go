@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
+	"strings"
+	"testing"
+)
+
+func TestUploadPipelineSpansFormTree(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	whisper := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_ = json.NewEncoder(w).Encode(domain.RecognitionSuccess{DetectedLang: "en", RecognizedText: "hello"})
+	}))
+	defer whisper.Close()
+
+	whisperRepo := repository.NewWhisperRepository(&config.AppConfig{
+		WhisperEndpoint:   whisper.URL,
+		WhisperTranscribe: "/transcribe",
+		MinioBucket:       "test-bucket",
+	})
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "UploadHandler")
+	file := &MockFile{content: "\xFF\xFB" + strings.Repeat("\x00", domain.SignatureLength)}
+	if _, err := domain.CheckFileSignatureWithContext(ctx, file); err != nil {
+		t.Fatalf("Signature check failed: %v", err)
+	}
+	if _, err := domain.GenerateUIDWithContext(ctx); err != nil {
+		t.Fatalf("UUID generation failed: %v", err)
+	}
+	if _, err := whisperRepo.SendToWhisper(ctx, "file.mp3", domain.TranscriptionOptions{}); err != nil {
+		t.Fatalf("SendToWhisper failed: %v", err)
+	}
+	root.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	parentOf := map[string]string{
+		"CheckFileSignature":          "UploadHandler",
+		"GenerateUID":                 "UploadHandler",
+		"SendToWhisper":               "UploadHandler",
+		"SendRequestToWhisperService": "SendToWhisper",
+	}
+	for child, parent := range parentOf {
+		childSpan, ok := spans[child]
+		if !ok {
+			t.Errorf("Span '%s' was not ended", child)
+			continue
+		}
+		if childSpan.Parent().SpanID() != spans[parent].SpanContext().SpanID() {
+			t.Errorf("Expected '%s' to be a child of '%s'", child, parent)
+		}
+		if childSpan.SpanContext().TraceID() != root.SpanContext().TraceID() {
+			t.Errorf("Expected '%s' to belong to the root trace", child)
+		}
+	}
+
+	if !strings.Contains(traceparent, root.SpanContext().TraceID().String()) {
+		t.Errorf("Expected traceparent header to carry trace %s, got: '%s'", root.SpanContext().TraceID(), traceparent)
+	}
+}
@@ -0,0 +1,314 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/adapters/handler/middleware"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports/telemetry"
+	"strings"
+	"testing"
+)
+
+func newTestTenantRegistry(t *testing.T, apiKeyCallers []string) *domain.TenantRegistry {
+	t.Helper()
+	registry, err := domain.NewTenantRegistry(domain.Tenant{Bucket: "audio", SourceBuckets: []string{"audio"}}, map[string]domain.Tenant{
+		"acme": {Callers: []string{"analytics"}, Quota: domain.TenantQuota{UploadsPerDay: 10}},
+		"beta": {Bucket: "beta-audio", Callers: []string{"beta-app"}, SourceBuckets: []string{"beta-audio", "shared"}, WhisperEndpoint: "http://whisper-beta"},
+	}, apiKeyCallers)
+	if err != nil {
+		t.Fatalf("Failed to create tenant registry: %v", err)
+	}
+	return registry
+}
+
+func TestTenantRegistryResolvesCallers(t *testing.T) {
+	registry := newTestTenantRegistry(t, nil)
+
+	acme := registry.Resolve(domain.Caller{ID: "analytics", Known: true})
+	if acme.ID != "acme" || acme.Bucket != "audio" || acme.Prefix != "tenants/acme/" || acme.Quota.UploadsPerDay != 10 {
+		t.Errorf("Expected tenant acme under tenants/acme/ in the default bucket, got: %+v", acme)
+	}
+	beta := registry.Resolve(domain.Caller{ID: "beta-app", Known: true})
+	if beta.ID != "beta" || beta.Bucket != "beta-audio" || beta.Prefix != "" || beta.WhisperEndpoint != "http://whisper-beta" {
+		t.Errorf("Expected tenant beta in its own bucket, got: %+v", beta)
+	}
+	for _, caller := range []domain.Caller{
+		{ID: "analytics"},
+		{ID: domain.AnonymousCaller},
+		{ID: "reporting", Known: true},
+	} {
+		if tenant := registry.Resolve(caller); tenant.ID != "" || tenant.Bucket != "audio" || tenant.Name() != domain.DefaultTenantName {
+			t.Errorf("Expected caller %+v in the default tenant, got: %+v", caller, tenant)
+		}
+	}
+}
+
+func TestTenantRegistryDerivesTenantsFromAPIKeys(t *testing.T) {
+	registry := newTestTenantRegistry(t, []string{"analytics", "reporting", "beta"})
+
+	if tenant := registry.Resolve(domain.Caller{ID: "analytics", Known: true}); tenant.ID != "acme" {
+		t.Errorf("Expected a configured tenant to keep its callers, got: %+v", tenant)
+	}
+	if tenant := registry.Resolve(domain.Caller{ID: "reporting", Known: true}); tenant.ID != "reporting" || tenant.Prefix != "tenants/reporting/" {
+		t.Errorf("Expected a tenant derived from the API key name, got: %+v", tenant)
+	}
+	if tenant := registry.Resolve(domain.Caller{ID: "beta", Known: true}); tenant.ID != "beta" || tenant.Bucket != "beta-audio" {
+		t.Errorf("Expected an API key named after a configured tenant to join it, got: %+v", tenant)
+	}
+	if tenant := registry.Resolve(domain.Caller{ID: "key-0a1b2c3d"}); tenant.ID != "" {
+		t.Errorf("Expected an unknown API key in the default tenant, got: %+v", tenant)
+	}
+
+	var ids []string
+	for _, tenant := range registry.All() {
+		ids = append(ids, tenant.Name())
+	}
+	if !slices.Equal(ids, []string{"default", "acme", "beta", "reporting"}) {
+		t.Errorf("Unexpected tenants: %v", ids)
+	}
+	if buckets := registry.Buckets(); !slices.Equal(buckets, []string{"audio", "beta-audio"}) {
+		t.Errorf("Unexpected buckets: %v", buckets)
+	}
+}
+
+func TestTenantRegistryRejectsInvalidTenants(t *testing.T) {
+	defaultTenant := domain.Tenant{Bucket: "audio"}
+	cases := map[string]struct {
+		tenants       map[string]domain.Tenant
+		apiKeyCallers []string
+	}{
+		"reserved ID":     {tenants: map[string]domain.Tenant{"default": {}}},
+		"invalid ID":      {tenants: map[string]domain.Tenant{"acme/../beta": {}}},
+		"shared caller":   {tenants: map[string]domain.Tenant{"acme": {Callers: []string{"analytics"}}, "beta": {Callers: []string{"analytics"}}}},
+		"shared bucket":   {tenants: map[string]domain.Tenant{"acme": {Bucket: "shared"}, "beta": {Bucket: "shared", Prefix: "beta"}}},
+		"nested prefix":   {tenants: map[string]domain.Tenant{"acme": {Prefix: "customers/"}, "beta": {Prefix: "customers/beta"}}},
+		"invalid API key": {apiKeyCallers: []string{"data team"}},
+	}
+	for name, c := range cases {
+		if _, err := domain.NewTenantRegistry(defaultTenant, c.tenants, c.apiKeyCallers); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestTenantObjectOwnership(t *testing.T) {
+	registry := newTestTenantRegistry(t, nil)
+
+	cases := []struct {
+		bucket, key string
+		owner       string
+		owned       bool
+	}{
+		{"audio", "tenants/acme/0b5c.wav", "acme", true},
+		{"audio", "analytics/0b5c.wav", "", true},
+		{"beta-audio", "0b5c.wav", "beta", true},
+		{"shared", "0b5c.wav", "", false},
+	}
+	for _, c := range cases {
+		owner, owned := registry.TenantOfObject(c.bucket, c.key)
+		if owned != c.owned || owned && owner.ID != c.owner {
+			t.Errorf("%s/%s: expected owner %q (%v), got %q (%v)", c.bucket, c.key, c.owner, c.owned, owner.ID, owned)
+		}
+	}
+
+	if registry.MayTranscribeFrom("", "audio", "tenants/acme/0b5c.wav") {
+		t.Error("Expected the default tenant not to read objects of another tenant")
+	}
+	if !registry.MayTranscribeFrom("", "audio", "uploads/0b5c.wav") {
+		t.Error("Expected the default tenant to read its source bucket")
+	}
+	if !registry.MayTranscribeFrom("beta", "shared", "0b5c.wav") || !registry.MayTranscribeFrom("beta", "beta-audio", "0b5c.wav") {
+		t.Error("Expected tenant beta to read its source buckets")
+	}
+	if registry.MayTranscribeFrom("acme", "audio", "tenants/acme/0b5c.wav") {
+		t.Error("Expected a tenant without source buckets to read none")
+	}
+	if registry.MayTranscribeFrom("unknown", "audio", "0b5c.wav") {
+		t.Error("Expected an unknown tenant to read nothing")
+	}
+}
+
+func TestTenantContentKeysAreScoped(t *testing.T) {
+	if key := domain.TenantContentKey("", "abc"); key != "abc" {
+		t.Errorf("Expected the default tenant to keep the digest, got: %s", key)
+	}
+	if key := domain.TenantContentKey("acme", "abc"); key != "acme/abc" {
+		t.Errorf("Expected a tenant-scoped key, got: %s", key)
+	}
+}
+
+func TestQuotaTrackerEnforcesDailyQuota(t *testing.T) {
+	ctx := context.Background()
+	tracker := repository.NewQuotaTracker()
+	acme := domain.Tenant{ID: "acme", Quota: domain.TenantQuota{UploadsPerDay: 2, BytesPerDay: 100}}
+
+	if err := tracker.Reserve(ctx, acme, 40); err != nil {
+		t.Fatalf("Expected the first upload to fit, got: %v", err)
+	}
+	if err := tracker.Reserve(ctx, acme, 70); !errors.Is(err, repository.ErrQuotaExceeded) {
+		t.Errorf("Expected the bytes quota to be exceeded, got: %v", err)
+	}
+	if err := tracker.Reserve(ctx, acme, 60); err != nil {
+		t.Errorf("Expected a rejected upload not to count, got: %v", err)
+	}
+	if err := tracker.Reserve(ctx, acme, 0); !errors.Is(err, repository.ErrQuotaExceeded) {
+		t.Errorf("Expected the uploads quota to be exceeded, got: %v", err)
+	}
+
+	beta := domain.Tenant{ID: "beta", Quota: domain.TenantQuota{UploadsPerDay: 1}}
+	if err := tracker.Reserve(ctx, beta, 1<<30); err != nil {
+		t.Errorf("Expected quotas to be tracked per tenant, got: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := tracker.Reserve(ctx, domain.Tenant{}, 1<<30); err != nil {
+			t.Errorf("Expected no quota without limits, got: %v", err)
+		}
+	}
+	var disabled *repository.QuotaTracker
+	if err := disabled.Reserve(ctx, acme, 1<<30); err != nil {
+		t.Errorf("Expected a nil tracker to enforce no quota, got: %v", err)
+	}
+}
+
+func TestSearchIsScopedToTenant(t *testing.T) {
+	ctx := context.Background()
+	index, err := repository.NewMemorySearchIndex("")
+	if err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	own := indexedTranscript("call-1", "en", "The SuperPhone battery is great.")
+	other := indexedTranscript("call-2", "en", "The SuperPhone case is great.")
+	other.Tenant = "acme"
+	for _, transcript := range []domain.Transcript{own, other} {
+		if err := index.Index(ctx, transcript); err != nil {
+			t.Fatalf("Failed to index transcript: %v", err)
+		}
+	}
+
+	for tenant, want := range map[string]string{"": "call-1", "acme": "call-2"} {
+		result, err := index.Search(ctx, domain.SearchQuery{Text: "superphone", Tenant: tenant})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if result.Total != 1 || result.Hits[0].ID != want {
+			t.Errorf("Expected tenant %q to find only %s, got: %+v", tenant, want, result.Hits)
+		}
+	}
+	if result, _ := index.Search(ctx, domain.SearchQuery{Text: "superphone", Tenant: "beta"}); result.Total != 0 {
+		t.Errorf("Expected no hits for a tenant without transcripts, got: %+v", result.Hits)
+	}
+}
+
+func TestAuditQueryMatchesTenantStrictly(t *testing.T) {
+	record := domain.AuditRecord{Caller: "analytics", Tenant: "acme"}
+	if (domain.AuditQuery{}).Matches(record) {
+		t.Error("Expected the default tenant not to see records of another tenant")
+	}
+	if !(domain.AuditQuery{Tenant: "acme"}).Matches(record) {
+		t.Error("Expected the tenant to see its own records")
+	}
+	if !(domain.AuditQuery{}).Matches(domain.AuditRecord{Caller: "analytics"}) {
+		t.Error("Expected the default tenant to see its own records")
+	}
+}
+
+func TestTenantResolverAttributesRequests(t *testing.T) {
+	buf := &bytes.Buffer{}
+	previous := log.Logger
+	log.Logger = zerolog.New(buf)
+	defer func() { log.Logger = previous }()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	cfg := &config.AppConfig{APIKeys: map[string]string{"secret-key": "analytics"}}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestLogger(cfg))
+	r.Use(middleware.TenantResolver(newTestTenantRegistry(t, nil)))
+	var tenant domain.Tenant
+	r.GET("/log", func(c *gin.Context) {
+		ctx, span := telemetry.StartSpanFromGinContext(c, "TestHandler")
+		defer span.End()
+		tenant = domain.TenantFromContext(ctx)
+		telemetry.LoggerFromContext(ctx).Info().Msg("handler line")
+		c.Status(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/log", nil)
+	req.Header.Set(middleware.APIKeyHeader, "secret-key")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if tenant.ID != "acme" {
+		t.Errorf("Expected tenant acme in the request context, got: %+v", tenant)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to decode log line '%s': %v", line, err)
+		}
+		if entry["tenant_id"] != "acme" {
+			t.Errorf("Expected tenant_id 'acme' in log line: %v", entry)
+		}
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got: %d", len(spans))
+	}
+	if !hasAttribute(spans[0], telemetry.TenantAttribute, "acme") {
+		t.Errorf("Expected span attribute %s=acme, got: %v", telemetry.TenantAttribute, spans[0].Attributes())
+	}
+}
+
+func TestSendToWhisperUsesTenantEndpointAndBucket(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	var sent handlerStructure.SendData
+	tenantWhisper := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		_ = json.NewEncoder(w).Encode(domain.RecognitionSuccess{DetectedLang: "en", RecognizedText: "hello"})
+	}))
+	defer tenantWhisper.Close()
+
+	whisperRepo := repository.NewWhisperRepository(&config.AppConfig{
+		WhisperEndpoint:   "http://127.0.0.1:1",
+		WhisperTranscribe: "/transcribe",
+		MinioBucket:       "audio",
+	})
+	ctx := domain.ContextWithTenant(context.Background(), domain.Tenant{ID: "beta", Bucket: "beta-audio", WhisperEndpoint: tenantWhisper.URL})
+	if _, err := whisperRepo.SendToWhisper(ctx, "0b5c.wav", domain.TranscriptionOptions{}); err != nil {
+		t.Fatalf("SendToWhisper failed: %v", err)
+	}
+	if sent.BucketName != "beta-audio" || sent.FileName != "0b5c.wav" {
+		t.Errorf("Expected the object in the tenant bucket, got: %+v", sent)
+	}
+	for _, span := range recorder.Ended() {
+		if span.Name() == "SendToWhisper" && !hasAttribute(span, telemetry.TenantAttribute, "beta") {
+			t.Errorf("Expected span attribute %s=beta, got: %v", telemetry.TenantAttribute, span.Attributes())
+		}
+	}
+}
+
+func hasAttribute(span sdktrace.ReadOnlySpan, key string, value string) bool {
+	for _, attr := range span.Attributes() {
+		if string(attr.Key) == key && attr.Value.AsString() == value {
+			return true
+		}
+	}
+	return false
+}
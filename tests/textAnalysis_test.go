@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+const releaseMeeting = "Good morning everyone. Today we review the release plan for the mobile app. " +
+	"The release plan moves the mobile app launch to March. Marketing needs the release notes by Friday. " +
+	"Um, okay, thanks. The mobile app still has a crash on login. Anna will fix the login crash before the release. " +
+	"Any questions? No. Great, see you next week."
+
+func keywordTexts(keywords []domain.Keyword) []string {
+	texts := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		texts[i] = keyword.Text
+	}
+	return texts
+}
+
+func TestAnalyze_English(t *testing.T) {
+	analysis := domain.Analyze(domain.RecognitionSuccess{RecognizedText: releaseMeeting}, "en", domain.AnalysisConfig{SummarySentences: 3, Keywords: 5})
+
+	want := "Today we review the release plan for the mobile app. The release plan moves the mobile app launch to March. The mobile app still has a crash on login."
+	if analysis.Summary != want || analysis.SummaryMethod != domain.SummaryMethodExtractive {
+		t.Errorf("Unexpected summary: %q (%s)", analysis.Summary, analysis.SummaryMethod)
+	}
+	if keywords := keywordTexts(analysis.Keywords); !reflect.DeepEqual(keywords, []string{"release", "app", "mobile", "crash", "login"}) {
+		t.Errorf("Unexpected keywords: %v", keywords)
+	}
+	if analysis.Keywords[0].Score != 1 || analysis.Keywords[4].Score >= analysis.Keywords[1].Score {
+		t.Errorf("Expected scores relative to the best keyword: %+v", analysis.Keywords)
+	}
+	if len(analysis.Keyphrases) != 5 || analysis.Keyphrases[0].Text != "mobile app" || analysis.Keyphrases[1].Text != "release plan" {
+		t.Errorf("Unexpected keyphrases: %v", keywordTexts(analysis.Keyphrases))
+	}
+}
+
+func TestAnalyze_RussianStopwords(t *testing.T) {
+	text := "Всем привет. Сегодня обсуждаем бюджет проекта на следующий квартал. Бюджет проекта вырос на двадцать процентов. " +
+		"Ну, в общем, понятно. Отдел продаж просит увеличить бюджет рекламы. Решение по бюджету рекламы примем в пятницу. Спасибо всем."
+
+	analysis := domain.Analyze(domain.RecognitionSuccess{RecognizedText: text}, "ru", domain.AnalysisConfig{SummarySentences: 5, Keywords: 3})
+
+	if sentences := domain.SplitSentences(analysis.Summary); len(sentences) != 3 {
+		t.Errorf("Expected a third of the 7 sentences, got %q", analysis.Summary)
+	}
+	if keywords := keywordTexts(analysis.Keywords); !reflect.DeepEqual(keywords, []string{"бюджет", "проекта", "рекламы"}) {
+		t.Errorf("Unexpected keywords: %v", keywords)
+	}
+	for _, keyphrase := range analysis.Keyphrases {
+		if strings.Contains(keyphrase.Text, "всем") || strings.Contains(keyphrase.Text, " на ") {
+			t.Errorf("Expected keyphrases without stopwords, got %q", keyphrase.Text)
+		}
+	}
+}
+
+func TestAnalyze_SegmentsWithoutPunctuation(t *testing.T) {
+	result := domain.RecognitionSuccess{
+		RecognizedText: "deploy the gateway service then test the gateway service and update the docs",
+		Segments: []domain.Segment{
+			{Text: " deploy the gateway service"},
+			{Text: " then test the gateway service"},
+			{Text: " and update the docs"},
+		},
+	}
+
+	analysis := domain.Analyze(result, "en", domain.AnalysisConfig{SummarySentences: 1, Keywords: 2})
+
+	if analysis.Summary != "deploy the gateway service" {
+		t.Errorf("Expected a segment as summary, got %q", analysis.Summary)
+	}
+	if len(analysis.Keyphrases) == 0 || analysis.Keyphrases[0].Text != "gateway service" {
+		t.Errorf("Unexpected keyphrases: %v", keywordTexts(analysis.Keyphrases))
+	}
+
+	empty := domain.Analyze(domain.RecognitionSuccess{}, "en", domain.AnalysisConfig{SummarySentences: 3, Keywords: 5})
+	if empty.Summary != "" || empty.Keywords == nil || len(empty.Keywords) != 0 {
+		t.Errorf("Unexpected analysis of an empty transcript: %+v", empty)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	got := domain.SplitSentences("Version 1.5 is out! Is it stable? Yes… mostly. 会议开始。请发言")
+	want := []string{"Version 1.5 is out!", "Is it stable?", "Yes…", "mostly.", "会议开始。", "请发言"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	for sentences, want := range map[int]int{0: 0, 1: 1, 4: 2, 9: 3, 30: 5} {
+		if got := domain.SummaryLength(sentences, 5); got != want {
+			t.Errorf("Expected %d summary sentences for %d, got %d", want, sentences, got)
+		}
+	}
+}
+
+func TestRedactResult_RedactsAnalysis(t *testing.T) {
+	redactor, err := domain.NewRedactor(map[string]string{"project": `Project Falcon`})
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+	result := domain.RecognitionSuccess{
+		RecognizedText: "Mail anna@example.com about Project Falcon.",
+		Analysis: &domain.Analysis{
+			Summary:    "Mail anna@example.com about Project Falcon.",
+			Keyphrases: []domain.Keyword{{Text: "project falcon", Score: 1}},
+		},
+	}
+
+	redacted := redactor.RedactResult(result)
+
+	if redacted.Analysis.Summary != "Mail [EMAIL] about [PROJECT]." {
+		t.Errorf("Unexpected summary: %q", redacted.Analysis.Summary)
+	}
+	if result.Analysis.Summary != "Mail anna@example.com about Project Falcon." {
+		t.Error("Expected the input analysis to be left untouched")
+	}
+}
+
+func TestLLMSummarizer_Summarize(t *testing.T) {
+	var request handlerStructure.ChatCompletionData
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": " The launch moves to March. "}}]}`))
+	}))
+	defer server.Close()
+
+	summarizer, err := repository.NewLLMSummarizer(server.URL+"/v1/chat/completions", "secret", "summary-model", time.Second)
+	if err != nil {
+		t.Fatalf("NewLLMSummarizer failed: %v", err)
+	}
+	summary, err := summarizer.Summarize(context.Background(), releaseMeeting, "en", 3)
+	if err != nil {
+		t.Fatalf("Summarize failed: %v", err)
+	}
+	if summary != "The launch moves to March." {
+		t.Errorf("Unexpected summary: %q", summary)
+	}
+	if authorization != "Bearer secret" || request.Model != "summary-model" || len(request.Messages) != 2 {
+		t.Fatalf("Unexpected request: %q, %+v", authorization, request)
+	}
+	if !strings.Contains(request.Messages[0].Content, "at most 3 sentences") || request.Messages[1].Content != releaseMeeting {
+		t.Errorf("Unexpected messages: %+v", request.Messages)
+	}
+}
+
+func TestLLMSummarizer_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/empty" {
+			w.Write([]byte(`{"choices": []}`))
+			return
+		}
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/limited", "/empty"} {
+		summarizer, err := repository.NewLLMSummarizer(server.URL+path, "", "summary-model", time.Second)
+		if err != nil {
+			t.Fatalf("NewLLMSummarizer failed: %v", err)
+		}
+		if _, err := summarizer.Summarize(context.Background(), "text", "", 3); err == nil {
+			t.Errorf("Expected an error from %s", path)
+		}
+	}
+	if _, err := repository.NewLLMSummarizer(server.URL, "", "", time.Second); err == nil {
+		t.Error("Expected an error for a missing model")
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func transcriptAt(id string, lang string, createdAt time.Time) domain.Transcript {
+	return domain.Transcript{
+		ID:                 id,
+		CreatedAt:          createdAt,
+		RecognitionSuccess: domain.RecognitionSuccess{DetectedLang: lang},
+	}
+}
+
+func TestPaginateTranscripts(t *testing.T) {
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	transcripts := []domain.Transcript{
+		transcriptAt("a", "en", base),
+		transcriptAt("b", "ru", base.Add(time.Hour)),
+		transcriptAt("c", "en", base.Add(2*time.Hour)),
+		transcriptAt("d", "en", base.Add(3*time.Hour)),
+	}
+
+	page := domain.PaginateTranscripts(transcripts, domain.TranscriptQuery{Language: "en", Limit: 2})
+	if page.Total != 3 {
+		t.Errorf("Expected 3 matching transcripts, got: %d", page.Total)
+	}
+	if len(page.Items) != 2 || page.Items[0].ID != "d" || page.Items[1].ID != "c" {
+		t.Errorf("Expected newest English transcripts d and c, got: %+v", page.Items)
+	}
+	if page.NextOffset == nil || *page.NextOffset != 2 {
+		t.Fatalf("Expected next offset 2, got: %v", page.NextOffset)
+	}
+
+	last := domain.PaginateTranscripts(transcripts, domain.TranscriptQuery{Language: "en", Offset: *page.NextOffset, Limit: 2})
+	if len(last.Items) != 1 || last.Items[0].ID != "a" || last.NextOffset != nil {
+		t.Errorf("Expected last page with transcript a only, got: %+v", last)
+	}
+
+	ranged := domain.PaginateTranscripts(transcripts, domain.TranscriptQuery{From: base.Add(30 * time.Minute), To: base.Add(150 * time.Minute)})
+	if ranged.Total != 2 || ranged.Items[0].ID != "c" || ranged.Items[1].ID != "b" {
+		t.Errorf("Expected transcripts c and b in range, got: %+v", ranged.Items)
+	}
+}
+
+func TestTranscriptKeys(t *testing.T) {
+	if key := repository.TranscriptKey("0b5c"); key != "transcripts/0b5c.transcript.json" {
+		t.Errorf("Expected sidecar key 'transcripts/0b5c.transcript.json', got: '%s'", key)
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
+	"strings"
+	"testing"
+)
+
+func formLookup(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestParseTranscriptionOptions(t *testing.T) {
+	options, err := domain.ParseTranscriptionOptions(formLookup(map[string]string{
+		"language":        "RU",
+		"task":            "Translate",
+		"initial_prompt":  "SuperPhone",
+		"temperature":     "0.2",
+		"word_timestamps": "true",
+	}))
+	if err != nil {
+		t.Fatalf("Expected valid options, got: %v", err)
+	}
+	if options.Language != "ru" || options.Task != domain.TaskTranslate || *options.Temperature != 0.2 || !options.WordTimestamps {
+		t.Errorf("Unexpected options: %+v", options)
+	}
+
+	invalid := []map[string]string{
+		{"language": "russian"},
+		{"language": "xx"},
+		{"task": "summarize"},
+		{"temperature": "1.5"},
+		{"temperature": "warm"},
+		{"temperature": "NaN"},
+		{"temperature": "-Inf"},
+		{"word_timestamps": "maybe"},
+		{"initial_prompt": strings.Repeat("a", domain.MaxInitialPromptLength+1)},
+	}
+	for _, values := range invalid {
+		if _, err := domain.ParseTranscriptionOptions(formLookup(values)); err == nil {
+			t.Errorf("Expected %v to be rejected", values)
+		}
+	}
+}
+
+func TestTranscriptionOptionsKey(t *testing.T) {
+	if key := domain.TranscriptionOptionsKey(domain.TranscriptionOptions{}); key != "" {
+		t.Errorf("Expected empty key for default options, got: '%s'", key)
+	}
+	english := domain.TranscriptionOptionsKey(domain.TranscriptionOptions{Language: "en"})
+	translated := domain.TranscriptionOptionsKey(domain.TranscriptionOptions{Language: "en", Task: domain.TaskTranslate})
+	if english == "" || english == translated {
+		t.Errorf("Expected distinct keys, got: '%s' and '%s'", english, translated)
+	}
+}
+
+func TestSendToWhisperForwardsOptions(t *testing.T) {
+	var sent map[string]any
+	whisper := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		_ = json.NewEncoder(w).Encode(domain.RecognitionSuccess{DetectedLang: "ru", RecognizedText: "hello"})
+	}))
+	defer whisper.Close()
+
+	whisperRepo := repository.NewWhisperRepository(&config.AppConfig{
+		WhisperEndpoint:   whisper.URL,
+		WhisperTranscribe: "/transcribe",
+		MinioBucket:       "test-bucket",
+	})
+	options := domain.TranscriptionOptions{Language: "ru", Task: domain.TaskTranslate}
+	result, err := whisperRepo.SendToWhisper(context.Background(), "file.mp3", options)
+	if err != nil {
+		t.Fatalf("SendToWhisper failed: %v", err)
+	}
+
+	if sent["language"] != "ru" || sent["task"] != "translate" || sent["file_name"] != "file.mp3" {
+		t.Errorf("Expected options in the Whisper request, got: %v", sent)
+	}
+	if _, ok := sent["temperature"]; ok {
+		t.Errorf("Expected unset temperature to be omitted, got: %v", sent)
+	}
+	if result.Options == nil || *result.Options != options {
+		t.Errorf("Expected options to be echoed, got: %+v", result.Options)
+	}
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sr-api/internal/adapters/handler/handlerStructure"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/core/domain"
+	"sr-api/internal/core/ports"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTranslator "translates" by upper-casing and tagging every text with the target.
+type fakeTranslator struct{}
+
+var _ ports.Translator = fakeTranslator{}
+
+func (fakeTranslator) Translate(ctx context.Context, texts []string, source string, target string) ([]string, error) {
+	translated := make([]string, len(texts))
+	for i, text := range texts {
+		translated[i] = target + ":" + strings.ToUpper(text)
+	}
+	return translated, nil
+}
+
+func translate(t *testing.T, result domain.RecognitionSuccess, target string) domain.RecognitionSuccess {
+	t.Helper()
+	texts, err := fakeTranslator{}.Translate(context.Background(), domain.TranslationTexts(result), result.DetectedLang, target)
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	return domain.ApplyTranslation(result, target, texts)
+}
+
+func TestApplyTranslation_AlignsSegments(t *testing.T) {
+	result := domain.RecognitionSuccess{
+		DetectedLang:   "ru",
+		RecognizedText: "Привет. Как дела?",
+		Segments: []domain.Segment{
+			{ID: 0, Start: 0, End: 1.5, Text: " Привет.", Speaker: "SPEAKER_00"},
+			{ID: 1, Start: 1.5, End: 3, Text: " Как дела?", Speaker: "SPEAKER_01"},
+		},
+	}
+
+	translated := translate(t, result, "en")
+
+	if translated.RecognizedText != result.RecognizedText {
+		t.Errorf("Expected the original text to be kept, got %q", translated.RecognizedText)
+	}
+	translation := translated.Translation
+	if translation == nil || translation.Language != "en" || translation.Text != "en:ПРИВЕТ. en:КАК ДЕЛА?" {
+		t.Fatalf("Unexpected translation: %+v", translation)
+	}
+	second := translation.Segments[1]
+	if len(translation.Segments) != 2 || second.ID != 1 || second.Start != 1.5 || second.End != 3 || second.Speaker != "SPEAKER_01" || second.Text != "en:КАК ДЕЛА?" {
+		t.Errorf("Expected segments aligned with the original, got %+v", translation.Segments)
+	}
+
+	srt := domain.RenderSRT(domain.TranslatedSegments(*translation))
+	if !strings.Contains(srt, "00:00:01,500 --> 00:00:03,000\nSPEAKER_01: en:КАК ДЕЛА?") {
+		t.Errorf("Unexpected translated subtitles:\n%s", srt)
+	}
+}
+
+func TestApplyTranslation_WithoutSegments(t *testing.T) {
+	translated := translate(t, domain.RecognitionSuccess{RecognizedText: " hello there "}, "de")
+
+	if translated.Translation.Text != "de:HELLO THERE" || translated.Translation.Segments != nil {
+		t.Errorf("Unexpected translation: %+v", translated.Translation)
+	}
+}
+
+func TestRedactResult_RedactsTranslation(t *testing.T) {
+	redactor, err := domain.NewRedactor(nil)
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+	result := domain.ApplyTranslation(
+		domain.RecognitionSuccess{RecognizedText: "Пишите на ivan@example.com"},
+		"en", []string{"Write to ivan@example.com"},
+	)
+
+	redacted := redactor.RedactResult(result)
+
+	if redacted.Translation.Text != "Write to [EMAIL]" || redacted.Redaction.Total != 1 {
+		t.Errorf("Unexpected redaction: %+v, %+v", redacted.Translation, redacted.Redaction)
+	}
+	if result.Translation.Text != "Write to ivan@example.com" {
+		t.Error("Expected the input translation to be left untouched")
+	}
+}
+
+func TestNormalizeTargetLanguage(t *testing.T) {
+	for input, want := range map[string]string{"": "", " DE ": "de", "pt-BR": "pt-br", "zh-Hant": "zh-hant"} {
+		if got, err := domain.NormalizeTargetLanguage(input); err != nil || got != want {
+			t.Errorf("For %q expected %q, got %q, %v", input, want, got, err)
+		}
+	}
+	for _, invalid := range []string{"german", "e", "de_DE", "../en"} {
+		if _, err := domain.NormalizeTargetLanguage(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestLibreTranslator_Translate(t *testing.T) {
+	var requests []handlerStructure.TranslateData
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request handlerStructure.TranslateData
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, request)
+		translated := make([]string, len(request.Q))
+		for i, text := range request.Q {
+			translated[i] = strings.ToUpper(text)
+		}
+		json.NewEncoder(w).Encode(handlerStructure.TranslateResult{TranslatedText: translated})
+	}))
+	defer server.Close()
+
+	translator, err := repository.NewLibreTranslator(server.URL+"/translate", "secret", time.Second)
+	if err != nil {
+		t.Fatalf("NewLibreTranslator failed: %v", err)
+	}
+	texts := make([]string, 150)
+	for i := range texts {
+		texts[i] = "text"
+	}
+	texts[149] = "last"
+
+	translated, err := translator.Translate(context.Background(), texts, "en", "de")
+	if err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if len(translated) != 150 || translated[0] != "TEXT" || translated[149] != "LAST" {
+		t.Errorf("Unexpected translations: %d texts, last %q", len(translated), translated[len(translated)-1])
+	}
+	if len(requests) != 2 || len(requests[0].Q) != 100 || len(requests[1].Q) != 50 {
+		t.Fatalf("Expected the texts in two batches, got %d requests", len(requests))
+	}
+	if request := requests[0]; request.Source != "en" || request.Target != "de" || request.Format != "text" || request.APIKey != "secret" {
+		t.Errorf("Unexpected request: %+v", request)
+	}
+}
+
+func TestLibreTranslator_ServiceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "xx is not supported"}`))
+	}))
+	defer server.Close()
+
+	translator, err := repository.NewLibreTranslator(server.URL, "", time.Second)
+	if err != nil {
+		t.Fatalf("NewLibreTranslator failed: %v", err)
+	}
+	_, err = translator.Translate(context.Background(), []string{"hello"}, "en", "xx")
+	if err == nil || !strings.Contains(err.Error(), "xx is not supported") {
+		t.Errorf("Expected the service error, got %v", err)
+	}
+	if _, err := repository.NewLibreTranslator("", "", time.Second); err == nil {
+		t.Error("Expected an error for a missing endpoint")
+	}
+}
@@ -0,0 +1,336 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sr-api/internal/adapters/handler"
+	"sr-api/internal/adapters/handler/middleware"
+	"sr-api/internal/adapters/repository"
+	"sr-api/internal/config"
+	"sr-api/internal/core/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRouter creates the dependencies for cfg and registers the routes of the
+// service like main does.
+func newTestRouter(t *testing.T, cfg *config.AppConfig) (*gin.Engine, *handler.UploadHandlerDependencies) {
+	dep, err := handler.NewUploadHandlerDependencies(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create dependencies: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestLogger(cfg))
+	r.Use(middleware.TenantResolver(dep.Tenants))
+	r.POST("/upload", dep.UploadHandler)
+	r.POST("/upload/batch", dep.BatchUploadHandler)
+	r.POST("/uploads/presign", dep.PresignUploadHandler)
+	r.POST("/uploads/:id/complete", dep.CompleteUploadHandler)
+	r.POST("/transcribe", dep.TranscribeHandler)
+	r.GET("/requests/:id/progress", dep.ProgressHandler)
+	r.GET("/transcripts", dep.ListTranscriptsHandler)
+	r.GET("/transcripts/:id", dep.GetTranscriptHandler)
+	r.DELETE("/transcripts/:id", dep.DeleteTranscriptHandler)
+	return r, dep
+}
+
+type multipartFile struct {
+	field    string
+	filename string
+	content  []byte
+}
+
+// multipartRequest builds a multipart POST request with the files and form fields.
+func multipartRequest(t *testing.T, url string, apiKey string, files []multipartFile, fields map[string]string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.field, file.filename)
+		if err != nil {
+			t.Fatalf("Failed to create form file: %v", err)
+		}
+		part.Write(file.content)
+	}
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+	writer.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, url, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if apiKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, apiKey)
+	}
+	return req
+}
+
+// serve sends the request to the router and decodes a JSON response into out, if given.
+func serve(t *testing.T, r *gin.Engine, req *http.Request, out interface{}) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if out != nil && w.Code < http.StatusMultipleChoices {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("Failed to decode response %q: %v", w.Body.String(), err)
+		}
+	}
+	return w
+}
+
+func getTranscript(t *testing.T, r *gin.Engine, apiKey string, id string) (int, domain.Transcript) {
+	req, _ := http.NewRequest(http.MethodGet, "/transcripts/"+id, nil)
+	req.Header.Set(middleware.APIKeyHeader, apiKey)
+	var transcript domain.Transcript
+	w := serve(t, r, req, &transcript)
+	return w.Code, transcript
+}
+
+func TestUploadDeduplicatedContentKeepsEachTranscript(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "write to jane@example.com today" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+	wav := testWAV(1600, 1)
+
+	var redacted domain.RecognitionSuccess
+	w := serve(t, r, multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "a.wav", wav}}, map[string]string{"redact": "true"}), &redacted)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var full domain.RecognitionSuccess
+	w = serve(t, r, multipartRequest(t, "/upload", "bob-key", []multipartFile{{"file", "b.wav", wav}}, map[string]string{"no_cache": "true"}), &full)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if redacted.TranscriptID == "" || redacted.TranscriptID == full.TranscriptID {
+		t.Fatalf("Expected a transcript ID per transcription, got %q and %q", redacted.TranscriptID, full.TranscriptID)
+	}
+	if names := whisper.fileNames(); len(names) != 2 || names[0] != names[1] {
+		t.Errorf("Expected both transcriptions to share the stored audio, got %v", names)
+	}
+	code, first := getTranscript(t, r, "alice-key", redacted.TranscriptID)
+	if code != http.StatusOK || first.Caller != "alice" || strings.Contains(first.RecognizedText, "jane@example.com") {
+		t.Errorf("Expected alice's redacted transcript to be kept, got %d %+v", code, first)
+	}
+	code, second := getTranscript(t, r, "bob-key", full.TranscriptID)
+	if code != http.StatusOK || second.Caller != "bob" || !strings.Contains(second.RecognizedText, "jane@example.com") {
+		t.Errorf("Expected bob's transcript, got %d %+v", code, second)
+	}
+}
+
+func TestDeleteTranscriptKeepsAudioSharedByAnotherTranscript(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+	wav := testWAV(1600, 1)
+	upload := func() domain.RecognitionSuccess {
+		var result domain.RecognitionSuccess
+		w := serve(t, r, multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "a.wav", wav}}, map[string]string{"no_cache": "true"}), &result)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		return result
+	}
+	deleteTranscript := func(id string) {
+		req, _ := http.NewRequest(http.MethodDelete, "/transcripts/"+id, nil)
+		req.Header.Set(middleware.APIKeyHeader, "alice-key")
+		if w := serve(t, r, req, nil); w.Code != http.StatusNoContent {
+			t.Fatalf("Expected 204, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	first, second := upload(), upload()
+	_, kept := getTranscript(t, r, "alice-key", second.TranscriptID)
+	deleteTranscript(first.TranscriptID)
+
+	if code, _ := getTranscript(t, r, "alice-key", first.TranscriptID); code != http.StatusNotFound {
+		t.Errorf("Expected the deleted transcript to be gone, got %d", code)
+	}
+	if code, transcript := getTranscript(t, r, "alice-key", second.TranscriptID); code != http.StatusOK || transcript.ObjectKey != kept.ObjectKey {
+		t.Fatalf("Expected the other transcript to be kept, got %d %+v", code, transcript)
+	}
+	if _, ok := store.get("audio", kept.ObjectKey); !ok {
+		t.Fatalf("Expected the shared audio to be kept")
+	}
+	third := upload()
+	if names := whisper.fileNames(); len(names) != 3 || names[2] != kept.ObjectKey {
+		t.Errorf("Expected a later upload to reuse the shared audio, got %v", names)
+	}
+
+	deleteTranscript(second.TranscriptID)
+	deleteTranscript(third.TranscriptID)
+	if _, ok := store.get("audio", kept.ObjectKey); ok {
+		t.Errorf("Expected the audio to be removed with its last transcript")
+	}
+}
+
+func TestUploadReportsInvalidOptions(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+
+	var response map[string]string
+	w := serve(t, r, multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "a.wav", testWAV(1600, 1)}}, map[string]string{"temperature": "NaN"}), nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &response); w.Code != http.StatusBadRequest || err != nil {
+		t.Fatalf("Expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if response["error"] != "invalid 'temperature', expected a number between 0 and 1" {
+		t.Errorf("Expected the invalid option to be reported, got %q", response["error"])
+	}
+	if len(whisper.fileNames()) != 0 {
+		t.Errorf("Expected nothing to be transcribed, got %v", whisper.fileNames())
+	}
+}
+
+func TestUploadReportsTheMediaRejection(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+
+	var response map[string]string
+	w := serve(t, r, multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "a.mp3", testWAV(1600, 1)}}, nil), nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &response); w.Code != http.StatusBadRequest || err != nil {
+		t.Fatalf("Expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if response["error"] != "File extension .mp3 does not match the detected type audio/x-wav" {
+		t.Errorf("Expected the rejection reason to be reported, got %q", response["error"])
+	}
+	if len(store.keys("audio", "")) != 0 {
+		t.Errorf("Expected nothing to be stored, got %v", store.keys("audio", ""))
+	}
+}
+
+func TestUploadReportsAVideoWithoutAudioTrack(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+
+	var response map[string]string
+	w := serve(t, r, multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "clip.mp4", buildVideoMP4(false)}}, nil), nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &response); w.Code != http.StatusUnprocessableEntity || err != nil {
+		t.Fatalf("Expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if response["error"] != "Video has no audio track" {
+		t.Errorf("Expected the missing audio track to be reported, got %q", response["error"])
+	}
+	if len(whisper.fileNames()) != 0 {
+		t.Errorf("Expected nothing to be transcribed, got %v", whisper.fileNames())
+	}
+}
+
+func TestListTranscriptsReadsOnlyTheRequestedPage(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+	var ids []string
+	for seed := byte(1); seed <= 3; seed++ {
+		var result domain.RecognitionSuccess
+		w := serve(t, r, multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "a.wav", testWAV(1600, seed)}}, nil), &result)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		ids = append(ids, result.TranscriptID)
+	}
+	store.readKeys()
+
+	req, _ := http.NewRequest(http.MethodGet, "/transcripts?limit=1&offset=1", nil)
+	req.Header.Set(middleware.APIKeyHeader, "alice-key")
+	var page domain.TranscriptPage
+	if w := serve(t, r, req, &page); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if page.Total != 3 || len(page.Items) != 1 || page.Items[0].ID != ids[1] || page.Items[0].RecognizedText != "hello" {
+		t.Fatalf("Expected the second newest transcript of 3, got %+v", page)
+	}
+	if reads := store.readKeys(); len(reads) != 1 || reads[0] != repository.TranscriptKey(ids[1]) {
+		t.Errorf("Expected only the listed transcript to be read, got %v", reads)
+	}
+}
+
+func TestSearchIndexIsRebuiltInTheBackground(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "quarterly budget review" })
+	cfg := newFakeServicesConfig(t, store, whisper)
+	r, _ := newTestRouter(t, cfg)
+	var result domain.RecognitionSuccess
+	if w := serve(t, r, multipartRequest(t, "/upload", "alice-key", []multipartFile{{"file", "a.wav", testWAV(1600, 1)}}, nil), &result); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A restarted service finds the stored transcript once the index is rebuilt.
+	_, dep := newTestRouter(t, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dep.StartSearchReindex(ctx, cfg)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		found, err := dep.SearchIndex.Search(context.Background(), domain.SearchQuery{Text: "budget", Limit: 10})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if found.Total == 1 && found.Hits[0].ID == result.TranscriptID {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the stored transcript to be indexed, got %+v", found)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServiceStartsWhileMinioIsUnreachable(t *testing.T) {
+	store := newFakeObjectStore(t)
+	cfg := newFakeServicesConfig(t, store, newFakeWhisper(t, store, nil))
+	store.server.Close()
+
+	_, dep := newTestRouter(t, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dep.StartSearchReindex(ctx, cfg)
+	if found, err := dep.SearchIndex.Search(context.Background(), domain.SearchQuery{Text: "budget", Limit: 10}); err != nil || found.Total != 0 {
+		t.Errorf("Expected an empty index to be searchable, got %+v %v", found, err)
+	}
+}
+
+func TestTranscribeBucketObjectLikeAnUpload(t *testing.T) {
+	store := newFakeObjectStore(t)
+	whisper := newFakeWhisper(t, store, func([]byte) string { return "hello" })
+	r, _ := newTestRouter(t, newFakeServicesConfig(t, store, whisper))
+	wav := testWAV(1600, 1)
+	store.put("source", "calls/a.wav", &fakeObject{content: wav, contentType: "audio/wav"})
+	transcribe := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/transcribe", strings.NewReader(`{"bucket":"source","object":"calls/a.wav","normalize":true}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.APIKeyHeader, "alice-key")
+		return serve(t, r, req, nil)
+	}
+
+	w := transcribe()
+	var result domain.RecognitionSuccess
+	if err := json.Unmarshal(w.Body.Bytes(), &result); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	code, transcript := getTranscript(t, r, "alice-key", result.TranscriptID)
+	if code != http.StatusOK || transcript.SHA256 == "" || transcript.NormalizedObjectKey == "" {
+		t.Fatalf("Expected a hashed and normalized transcript, got %d %+v", code, transcript)
+	}
+	if names := whisper.fileNames(); len(names) != 1 || names[0] != transcript.NormalizedObjectKey {
+		t.Errorf("Expected the normalized audio to be transcribed, got %v", names)
+	}
+	if copied, ok := store.get("audio", transcript.ObjectKey); !ok || !bytes.Equal(copied.content, wav) {
+		t.Errorf("Expected the object to be copied into the upload bucket")
+	}
+	if _, ok := store.get("source", "calls/a.wav"); !ok {
+		t.Errorf("Expected the source object to be kept")
+	}
+
+	if w := transcribe(); w.Code != http.StatusOK || w.Header().Get(handler.CacheHeader) != "HIT" {
+		t.Errorf("Expected the same object to be served from the cache, got %d %q", w.Code, w.Header().Get(handler.CacheHeader))
+	}
+}
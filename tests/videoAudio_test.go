@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sr-api/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func mp4Box(boxType string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(box, boxType...), body...)
+}
+
+func isoUint32s(values ...uint32) []byte {
+	var out []byte
+	for _, value := range values {
+		out = binary.BigEndian.AppendUint32(out, value)
+	}
+	return out
+}
+
+// mp4Trak returns a track whose single chunk holds two samples at chunkOffset.
+func mp4Trak(id uint32, handler string, codec string, chunkOffset uint32, sampleSize uint32) []byte {
+	tkhd := append(isoUint32s(1, 0, 0, id), make([]byte, 68)...)
+	mdhd := append(isoUint32s(0, 0, 0, 1000, 0), 0x15, 0xC7, 0, 0) // "eng"
+	stbl := mp4Box("stbl",
+		mp4Box("stsd", isoUint32s(0, 1, 16), []byte(codec), make([]byte, 4)),
+		mp4Box("stsc", isoUint32s(0, 1, 1, 2, 1)),
+		mp4Box("stsz", isoUint32s(0, sampleSize, 2)),
+		mp4Box("stco", isoUint32s(0, 1, chunkOffset)),
+	)
+	return mp4Box("trak",
+		mp4Box("tkhd", tkhd),
+		mp4Box("mdia",
+			mp4Box("mdhd", mdhd),
+			mp4Box("hdlr", isoUint32s(0, 0), []byte(handler), make([]byte, 13)),
+			mp4Box("minf", stbl),
+		),
+	)
+}
+
+// buildVideoMP4 returns an MP4 whose media data holds 8 bytes of video followed by 6
+// bytes of audio, with an audio track unless withAudio is false.
+func buildVideoMP4(withAudio bool) []byte {
+	ftyp := mp4Box("ftyp", []byte("isom"), isoUint32s(0x200), []byte("isommp41"))
+	mdat := mp4Box("mdat", []byte("VVVVVVVVaudio!"))
+	videoOffset := uint32(len(ftyp) + 8)
+	traks := [][]byte{mp4Box("mvhd", make([]byte, 100)), mp4Trak(1, "vide", "avc1", videoOffset, 4)}
+	if withAudio {
+		traks = append(traks, mp4Trak(2, "soun", "mp4a", videoOffset+8, 3))
+	}
+	return bytes.Join([][]byte{ftyp, mdat, mp4Box("moov", traks...)}, nil)
+}
+
+func TestExtractAudioTrack_MP4(t *testing.T) {
+	file := &MockFile{content: string(buildVideoMP4(true))}
+
+	audio, err := domain.ExtractAudioTrack(file, "video/mp4")
+	if err != nil {
+		t.Fatalf("ExtractAudioTrack failed: %v", err)
+	}
+	defer audio.Close()
+	if file.offset != 0 {
+		t.Errorf("Expected reader to be rewound, offset: %d", file.offset)
+	}
+	if audio.MIMEType != "audio/mp4" || audio.Extension != ".m4a" {
+		t.Errorf("Expected an M4A file, got %s %s", audio.MIMEType, audio.Extension)
+	}
+	track := audio.Track
+	if track.ID != 2 || track.Codec != "mp4a" || track.Language != "eng" || track.AudioTracks != 1 {
+		t.Errorf("Unexpected track: %+v", track)
+	}
+
+	content, err := io.ReadAll(audio)
+	if err != nil {
+		t.Fatalf("Failed to read extracted audio: %v", err)
+	}
+	if int64(len(content)) != audio.Size {
+		t.Errorf("Expected size %d, got %d", len(content), audio.Size)
+	}
+	if bytes.Contains(content, []byte("vide")) || bytes.Contains(content, []byte("VVVV")) {
+		t.Error("Expected the video track to be dropped")
+	}
+	if !bytes.HasSuffix(content, []byte("audio!")) {
+		t.Fatal("Expected the audio samples at the end of the file")
+	}
+	stco := bytes.Index(content, []byte("stco"))
+	if stco < 0 {
+		t.Fatal("Expected a chunk offset table")
+	}
+	chunkOffset := binary.BigEndian.Uint32(content[stco+12:])
+	if got := string(content[chunkOffset : chunkOffset+6]); got != "audio!" {
+		t.Errorf("Expected the chunk offset to point at the audio samples, got %q", got)
+	}
+
+	// The extracted file is a valid ISO container of its own.
+	if _, err := domain.ValidateMediaWithContext(context.Background(), &MockFile{content: string(content)}, "a.m4a", domain.MediaPolicy{CheckStructure: true}); err != nil {
+		t.Errorf("Expected the extracted file to validate: %v", err)
+	}
+}
+
+func TestExtractAudioTrack_MP4WithoutAudio(t *testing.T) {
+	file := &MockFile{content: string(buildVideoMP4(false))}
+
+	if _, err := domain.ExtractAudioTrack(file, "video/mp4"); !errors.Is(err, domain.ErrNoAudioTrack) {
+		t.Errorf("Expected ErrNoAudioTrack, got %v", err)
+	}
+}
+
+func TestExtractAudioTrack_MP4ForgedSampleCount(t *testing.T) {
+	ftyp := mp4Box("ftyp", []byte("isom"), isoUint32s(0x200), []byte("isommp41"))
+	trak := mp4Box("trak",
+		mp4Box("tkhd", append(isoUint32s(1, 0, 0, 1), make([]byte, 68)...)),
+		mp4Box("mdia",
+			mp4Box("hdlr", isoUint32s(0, 0), []byte("soun"), make([]byte, 13)),
+			mp4Box("minf", mp4Box("stbl",
+				mp4Box("stsc", isoUint32s(0, 1, 1, 0xFFFFFFFF, 1)),
+				mp4Box("stsz", isoUint32s(0, 1, 0xFFFFFFFF)),
+				mp4Box("stco", isoUint32s(0, 1, 0)),
+			)),
+		),
+	)
+	file := &MockFile{content: string(bytes.Join([][]byte{ftyp, mp4Box("moov", mp4Box("mvhd", make([]byte, 100)), trak)}, nil))}
+
+	start := time.Now()
+	if _, err := domain.ExtractAudioTrack(file, "video/mp4"); !errors.Is(err, domain.ErrUnsupportedVideo) {
+		t.Errorf("Expected ErrUnsupportedVideo, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected forged sample tables to be rejected quickly, took %s", elapsed)
+	}
+}
+
+func ebml(id uint32, payload ...[]byte) []byte {
+	var out []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(out) > 0 {
+			out = append(out, b)
+		}
+	}
+	body := bytes.Join(payload, nil)
+	out = append(out, 0x40|byte(len(body)>>8), byte(len(body)))
+	return append(out, body...)
+}
+
+// buildWebM returns a WebM file with a VP9 track 1 and an Opus track 2, and a cluster
+// with a block of each.
+func buildWebM(withAudio bool) []byte {
+	tracks := [][]byte{ebml(0xAE, ebml(0xD7, []byte{1}), ebml(0x83, []byte{1}), ebml(0x86, []byte("V_VP9")))}
+	if withAudio {
+		tracks = append(tracks, ebml(0xAE, ebml(0xD7, []byte{2}), ebml(0x83, []byte{2}), ebml(0x86, []byte("A_OPUS")), ebml(0x22B59C, []byte("deu"))))
+	}
+	return bytes.Join([][]byte{
+		ebml(0x1A45DFA3, ebml(0x4282, []byte("webm"))),
+		ebml(0x18538067,
+			ebml(0x114D9B74, make([]byte, 4)),
+			ebml(0x1549A966, ebml(0x2AD7B1, []byte{0x0F, 0x42, 0x40})),
+			ebml(0x1654AE6B, tracks...),
+			ebml(0x1F43B675,
+				ebml(0xE7, []byte{0}),
+				ebml(0xA3, []byte{0x81, 0, 0, 0x80}, []byte("VIDEOFRAME")),
+				ebml(0xA3, []byte{0x82, 0, 0, 0x80}, []byte("opus-packet"), make([]byte, 512)),
+			),
+			ebml(0x1C53BB6B, make([]byte, 4)),
+		),
+	}, nil)
+}
+
+func TestExtractAudioTrack_WebM(t *testing.T) {
+	file := &MockFile{content: string(buildWebM(true))}
+
+	audio, err := domain.ExtractAudioTrack(file, "video/webm")
+	if err != nil {
+		t.Fatalf("ExtractAudioTrack failed: %v", err)
+	}
+	defer audio.Close()
+	if audio.MIMEType != "audio/webm" || audio.Extension != ".webm" {
+		t.Errorf("Expected a WebM file, got %s %s", audio.MIMEType, audio.Extension)
+	}
+	track := audio.Track
+	if track.ID != 2 || track.Codec != "A_OPUS" || track.Language != "deu" || track.Container != "webm" || track.AudioTracks != 1 {
+		t.Errorf("Unexpected track: %+v", track)
+	}
+
+	content, err := io.ReadAll(audio)
+	if err != nil {
+		t.Fatalf("Failed to read extracted audio: %v", err)
+	}
+	if bytes.Contains(content, []byte("VIDEOFRAME")) || bytes.Contains(content, []byte("V_VP9")) {
+		t.Error("Expected the video track and its blocks to be dropped")
+	}
+	if !bytes.Contains(content, []byte("opus-packet")) {
+		t.Error("Expected the audio block to be kept")
+	}
+
+	// The segment size is patched to the end of the file.
+	segment := bytes.Index(content, []byte{0x18, 0x53, 0x80, 0x67})
+	if segment < 0 {
+		t.Fatal("Expected a segment")
+	}
+	size := binary.BigEndian.Uint64(content[segment+4:]) &^ (1 << 56)
+	if want := uint64(len(content) - segment - 12); size != want {
+		t.Errorf("Expected segment size %d, got %d", want, size)
+	}
+	if _, err := domain.ValidateMediaWithContext(context.Background(), &MockFile{content: string(content)}, "a.webm", domain.MediaPolicy{CheckStructure: true}); err != nil {
+		t.Errorf("Expected the extracted file to validate: %v", err)
+	}
+}
+
+func TestExtractAudioTrack_WebMWithoutAudio(t *testing.T) {
+	file := &MockFile{content: string(buildWebM(false))}
+
+	if _, err := domain.ExtractAudioTrack(file, "video/webm"); !errors.Is(err, domain.ErrNoAudioTrack) {
+		t.Errorf("Expected ErrNoAudioTrack, got %v", err)
+	}
+}